@@ -23,6 +23,7 @@ import (
 	"github.com/sdyn/backend/internal/repository"
 	"github.com/sdyn/backend/internal/services"
 	"github.com/sdyn/backend/pkg/database"
+	"github.com/sdyn/backend/pkg/storage"
 )
 
 func main() {
@@ -52,19 +53,58 @@ func main() {
 	}
 	defer rdb.Close()
 
+	// Connect to MinIO
+	store, err := storage.NewMinio(storage.MinioConfig{
+		Endpoint:  cfg.MinioEndpoint,
+		AccessKey: cfg.MinioAccessKey,
+		SecretKey: cfg.MinioSecretKey,
+		Bucket:    cfg.MinioBucket,
+		UseSSL:    cfg.MinioUseSSL,
+		PublicURL: cfg.MinioPublicURL,
+		// Avatars and event covers are linked directly from the site
+		PublicPrefix: services.PublicImagePrefix,
+	})
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to connect to MinIO")
+	}
+
 	// Initialize repositories
 	memberRepo := repository.NewMemberRepository(db)
 	orgRepo := repository.NewOrganizationRepository(db)
 	eventRepo := repository.NewEventRepository(db)
 	feeRepo := repository.NewFeeRepository(db)
+	exportJobRepo := repository.NewExportJobRepository(db)
+	approvalRepo := repository.NewApprovalRepository(db)
+	transferRepo := repository.NewTransferRepository(db)
+	documentRepo := repository.NewDocumentRepository(db)
+	imageRepo := repository.NewImageRepository(db)
+	eventSeriesRepo := repository.NewEventSeriesRepository(db)
+	calendarTokenRepo := repository.NewCalendarTokenRepository(db)
+	notificationRepo := repository.NewNotificationRepository(db)
 
 	// Initialize services
 	memberService := services.NewMemberService(memberRepo, rdb)
 	orgService := services.NewOrganizationService(orgRepo)
 	eventService := services.NewEventService(eventRepo)
-	feeService := services.NewFeeService(feeRepo)
+	feeService := services.NewFeeService(feeRepo, services.MembershipPolicy{
+		AnnualTermMonths:  cfg.MembershipAnnualTermMonths,
+		MonthlyTermMonths: cfg.MembershipMonthlyTermMonths,
+	})
 	authService := services.NewAuthService(cfg, db, rdb)
 	authzService := services.NewAuthorizationService(db, rdb)
+	exportService := services.NewExportService(memberRepo, orgRepo, eventRepo, feeRepo)
+	exportJobService := services.NewExportJobService(exportJobRepo, exportService, store)
+	approvalService := services.NewApprovalService(approvalRepo)
+	transferService := services.NewTransferService(transferRepo, memberRepo, orgRepo)
+	documentService := services.NewDocumentService(documentRepo, memberRepo, store, int64(cfg.DocumentMaxSizeMB)<<20)
+	imageService := services.NewImageService(imageRepo, memberRepo, eventRepo, store)
+	cardService := services.NewMemberCardService(memberRepo, store, cfg)
+	checkinService := services.NewEventCheckinService(eventRepo, memberRepo, cfg)
+	eventSeriesService := services.NewEventSeriesService(eventSeriesRepo, eventService, cfg)
+	calendarService := services.NewCalendarService(calendarTokenRepo, eventRepo, orgRepo)
+	notificationService := services.NewNotificationService(notificationRepo)
+	eventSurveyService := services.NewEventSurveyService(eventRepo)
+	participationService := services.NewParticipationService(eventRepo, memberRepo, cfg)
 
 	// Initialize Keycloak validator
 	if err := middleware.InitKeycloakValidator(cfg); err != nil {
@@ -79,9 +119,31 @@ func main() {
 	// Initialize handlers
 	memberHandler := handlers.NewMemberHandler(memberService)
 	orgHandler := handlers.NewOrganizationHandler(orgService)
-	eventHandler := handlers.NewEventHandler(eventService)
+	eventHandler := handlers.NewEventHandler(eventService, eventSeriesService)
 	feeHandler := handlers.NewFeeHandler(feeService)
 	authHandler := handlers.NewAuthHandler(authService)
+	exportHandler := handlers.NewExportHandler(exportService, exportJobService, authzService)
+	reportHandler := handlers.NewReportHandler(db, rdb)
+	approvalHandler := handlers.NewApprovalHandler(approvalService)
+	transferHandler := handlers.NewTransferHandler(transferService)
+	documentHandler := handlers.NewDocumentHandler(documentService)
+	imageHandler := handlers.NewImageHandler(imageService)
+	cardHandler := handlers.NewCardHandler(cardService)
+	checkinHandler := handlers.NewCheckinHandler(checkinService)
+	eventSeriesHandler := handlers.NewEventSeriesHandler(eventSeriesService)
+	calendarHandler := handlers.NewCalendarHandler(calendarService)
+	notificationHandler := handlers.NewNotificationHandler(notificationService)
+	eventSurveyHandler := handlers.NewEventSurveyHandler(eventSurveyService)
+	participationHandler := handlers.NewParticipationHandler(participationService)
+
+	// Start background workers
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+	go exportJobService.RunWorker(workerCtx)
+	go memberService.RunScheduler(workerCtx)
+	go imageService.RunCollector(workerCtx)
+	go eventSeriesService.RunGenerator(workerCtx)
+	go eventService.RunScheduler(workerCtx)
 
 	// Create Fiber app
 	app := fiber.New(fiber.Config{
@@ -90,6 +152,8 @@ func main() {
 		DisableStartupMessage: cfg.Env == "production",
 		ReadTimeout:           10 * time.Second,
 		WriteTimeout:          10 * time.Second,
+		// Leave room for the multipart overhead of a maximum size document
+		BodyLimit: (cfg.DocumentMaxSizeMB + 1) << 20,
 	})
 
 	// Global middleware
@@ -133,6 +197,15 @@ func main() {
 	auth.Post("/refresh", authHandler.RefreshToken)
 	auth.Post("/logout", authHandler.Logout)
 
+	// Membership card check (public) - the QR code on a printed card
+	api.Get("/cards/verify", cardHandler.Verify)
+
+	// Calendar feeds (public) - private feeds take a calendar token, as
+	// calendar apps cannot send a JWT
+	api.Get("/calendar/public.ics", calendarHandler.PublicFeed)
+	api.Get("/calendar/me.ics", calendarHandler.MemberFeed)
+	api.Get("/calendar/organizations/:id.ics", calendarHandler.OrganizationFeed)
+
 	// Protected routes - use Keycloak JWT validation with token blacklist check and audit logging
 	protected := api.Group("",
 		middleware.KeycloakJWTAuth(),
@@ -144,12 +217,44 @@ func main() {
 	// Members - with RBAC permission checking
 	members := protected.Group("/members")
 	members.Get("/", middleware.RequirePermission(models.ResourceMember, models.ActionList), memberHandler.List)
+	members.Get("/duplicates", middleware.RequirePermission(models.ResourceMember, models.ActionUpdate), memberHandler.ListDuplicates)
+	members.Get("/expiring", middleware.RequirePermission(models.ResourceMember, models.ActionList), memberHandler.ListExpiring)
 	members.Get("/:id", middleware.RBACWithResourceCheck(models.ResourceMember, models.ActionRead), memberHandler.Get)
 	members.Post("/", middleware.RequirePermission(models.ResourceMember, models.ActionCreate), memberHandler.Create)
+	members.Post("/import", middleware.RequirePermission(models.ResourceMember, models.ActionImport), memberHandler.Import)
 	members.Put("/:id", middleware.RBACWithResourceCheck(models.ResourceMember, models.ActionUpdate), memberHandler.Update)
 	members.Delete("/:id", middleware.RBACWithResourceCheck(models.ResourceMember, models.ActionDelete), memberHandler.Delete)
 	members.Get("/:id/history", middleware.RBACWithResourceCheck(models.ResourceMember, models.ActionRead), memberHandler.GetHistory)
+	members.Get("/:id/as-of", middleware.RBACWithResourceCheck(models.ResourceMember, models.ActionRead), memberHandler.GetAsOf)
+	members.Get("/:id/referrals", middleware.RBACWithResourceCheck(models.ResourceMember, models.ActionRead), memberHandler.GetReferrals)
+	members.Get("/:id/completeness", middleware.RBACWithResourceCheck(models.ResourceMember, models.ActionRead), memberHandler.GetCompleteness)
+	members.Get("/:id/participation", middleware.RBACWithResourceCheck(models.ResourceMember, models.ActionRead), participationHandler.GetMember)
+	members.Post("/:id/verifications", middleware.RBACWithResourceCheck(models.ResourceMember, models.ActionApprove), memberHandler.VerifyField)
+	members.Delete("/:id/verifications/:field", middleware.RBACWithResourceCheck(models.ResourceMember, models.ActionApprove), memberHandler.UnverifyField)
 	members.Post("/:id/status", middleware.RBACWithResourceCheck(models.ResourceMember, models.ActionApprove), memberHandler.UpdateStatus)
+	members.Post("/:id/merge", middleware.RBACWithResourceCheck(models.ResourceMember, models.ActionDelete), memberHandler.Merge)
+	members.Post("/:id/transfers", middleware.RBACWithResourceCheck(models.ResourceMember, models.ActionUpdate), transferHandler.Create)
+	members.Get("/:id/documents", middleware.RBACWithResourceCheck(models.ResourceMember, models.ActionRead), documentHandler.List)
+	members.Post("/:id/documents", middleware.RBACWithResourceCheck(models.ResourceMember, models.ActionUpdate), documentHandler.Upload)
+	members.Get("/:id/documents/:documentId", middleware.RBACWithResourceCheck(models.ResourceMember, models.ActionRead), documentHandler.Get)
+	members.Delete("/:id/documents/:documentId", middleware.RBACWithResourceCheck(models.ResourceMember, models.ActionApprove), documentHandler.Delete)
+	members.Get("/:id/card", middleware.RBACWithResourceCheck(models.ResourceMember, models.ActionRead), cardHandler.GetCard)
+	members.Post("/:id/avatar", middleware.RBACWithResourceCheck(models.ResourceMember, models.ActionUpdate), imageHandler.UploadAvatar)
+	members.Delete("/:id/avatar", middleware.RBACWithResourceCheck(models.ResourceMember, models.ActionUpdate), imageHandler.DeleteAvatar)
+
+	// Membership approvals - scope is checked per application against the review level
+	approvals := protected.Group("/approvals")
+	approvals.Get("/", middleware.RequirePermission(models.ResourceMember, models.ActionApprove), approvalHandler.List)
+	approvals.Post("/:id/approve", middleware.RequirePermission(models.ResourceMember, models.ActionApprove), approvalHandler.Approve)
+	approvals.Post("/:id/reject", middleware.RequirePermission(models.ResourceMember, models.ActionReject), approvalHandler.Reject)
+	approvals.Post("/:id/escalate", middleware.RequirePermission(models.ResourceMember, models.ActionApprove), approvalHandler.Escalate)
+
+	// Organization transfers - who may decide is checked per transfer against
+	// the receiving organization
+	transfers := protected.Group("/transfers")
+	transfers.Post("/:id/accept", middleware.RequirePermission(models.ResourceMember, models.ActionApprove), transferHandler.Accept)
+	transfers.Post("/:id/decline", middleware.RequirePermission(models.ResourceMember, models.ActionApprove), transferHandler.Decline)
+	transfers.Post("/:id/cancel", middleware.RequirePermission(models.ResourceMember, models.ActionUpdate), transferHandler.Cancel)
 
 	// Organizations - with RBAC permission checking
 	orgs := protected.Group("/organizations")
@@ -160,6 +265,7 @@ func main() {
 	orgs.Delete("/:id", middleware.RBACWithResourceCheck(models.ResourceOrganization, models.ActionDelete), orgHandler.Delete)
 	orgs.Get("/:id/members", middleware.RBACWithResourceCheck(models.ResourceOrganization, models.ActionRead), orgHandler.GetMembers)
 	orgs.Get("/:id/stats", middleware.RBACWithResourceCheck(models.ResourceOrganization, models.ActionRead), orgHandler.GetStats)
+	orgs.Get("/:id/transfers", middleware.RBACWithResourceCheck(models.ResourceOrganization, models.ActionRead), transferHandler.List)
 
 	// Events - with RBAC permission checking
 	events := protected.Group("/events")
@@ -171,6 +277,26 @@ func main() {
 	events.Post("/:id/register", eventHandler.Register) // All members can register
 	events.Post("/:id/attendance", middleware.RBACWithResourceCheck(models.ResourceEvent, models.ActionUpdate), eventHandler.MarkAttendance)
 	events.Get("/:id/participants", middleware.RBACWithResourceCheck(models.ResourceEvent, models.ActionRead), eventHandler.GetParticipants)
+	events.Get("/:id/registration", eventHandler.GetMyRegistration) // Own registration only
+	events.Delete("/:id/register", eventHandler.CancelRegistration) // Own registration only
+	events.Post("/:id/participants/:memberId/remove", middleware.RBACWithResourceCheck(models.ResourceEvent, models.ActionUpdate), eventHandler.RemoveParticipant)
+	events.Get("/:id/settings", middleware.RBACWithResourceCheck(models.ResourceEvent, models.ActionRead), checkinHandler.GetSettings)
+	events.Put("/:id/settings", middleware.RBACWithResourceCheck(models.ResourceEvent, models.ActionUpdate), checkinHandler.UpdateSettings)
+	events.Get("/:id/ticket", checkinHandler.GetMyTicket) // Own registration only
+	events.Post("/:id/checkin", middleware.RBACWithResourceCheck(models.ResourceEvent, models.ActionUpdate), checkinHandler.CheckIn)
+	events.Post("/:id/checkout", middleware.RBACWithResourceCheck(models.ResourceEvent, models.ActionUpdate), checkinHandler.CheckOut)
+	events.Put("/:id/participants/:memberId/hours", middleware.RBACWithResourceCheck(models.ResourceEvent, models.ActionUpdate), participationHandler.SetHours)
+	events.Post("/:id/cover", middleware.RBACWithResourceCheck(models.ResourceEvent, models.ActionUpdate), imageHandler.UploadEventCover)
+	events.Delete("/:id/cover", middleware.RBACWithResourceCheck(models.ResourceEvent, models.ActionUpdate), imageHandler.DeleteEventCover)
+	events.Get("/:id/survey", middleware.RBACWithResourceCheck(models.ResourceEvent, models.ActionRead), eventSurveyHandler.Get)
+	events.Put("/:id/survey", middleware.RBACWithResourceCheck(models.ResourceEvent, models.ActionUpdate), eventSurveyHandler.Update)
+	events.Post("/:id/survey/responses", eventSurveyHandler.Submit) // Attendees only
+	events.Get("/:id/survey/results", middleware.RequirePermission(models.ResourceReport, models.ActionRead), middleware.RBACWithResourceCheck(models.ResourceEvent, models.ActionRead), eventSurveyHandler.Results)
+
+	// Recurring events - occurrences are regular events
+	eventSeries := protected.Group("/event-series")
+	eventSeries.Post("/", middleware.RequirePermission(models.ResourceEvent, models.ActionCreate), eventSeriesHandler.Create)
+	eventSeries.Get("/:id", middleware.RequirePermission(models.ResourceEvent, models.ActionRead), eventSeriesHandler.Get)
 
 	// Membership Fees - with RBAC permission checking
 	fees := protected.Group("/fees")
@@ -186,14 +312,30 @@ func main() {
 	reports.Get("/members", middleware.RequirePermission(models.ResourceReport, models.ActionRead), memberHandler.Report)
 	reports.Get("/fees", middleware.RequirePermission(models.ResourceReport, models.ActionRead), feeHandler.Report)
 	reports.Get("/events", middleware.RequirePermission(models.ResourceReport, models.ActionRead), eventHandler.Report)
-	reports.Get("/dashboard", middleware.RequirePermission(models.ResourceReport, models.ActionRead), handlers.DashboardReport)
-	reports.Get("/export/:type", middleware.RequirePermission(models.ResourceReport, models.ActionExport), handlers.ExportReport)
+	reports.Get("/profiles", middleware.RequirePermission(models.ResourceReport, models.ActionRead), memberHandler.IncompleteProfilesReport)
+	reports.Get("/referrals", middleware.RequirePermission(models.ResourceReport, models.ActionRead), memberHandler.ReferralReport)
+	reports.Get("/participation", middleware.RequirePermission(models.ResourceReport, models.ActionRead), participationHandler.Leaderboard)
+	reports.Get("/dashboard", middleware.RequirePermission(models.ResourceReport, models.ActionRead), reportHandler.DashboardReport)
+	reports.Get("/export/:type", middleware.RequirePermission(models.ResourceReport, models.ActionExport), exportHandler.ExportReport)
+
+	// Background exports - for exports too large to stream within the request
+	exports := protected.Group("/exports")
+	exports.Post("/:type", middleware.RequirePermission(models.ResourceReport, models.ActionExport), exportHandler.CreateJob)
+	exports.Get("/:id", middleware.RequirePermission(models.ResourceReport, models.ActionExport), exportHandler.GetJob)
 
 	// Profile (self)
 	protected.Get("/profile", memberHandler.GetProfile)
 	protected.Put("/profile", memberHandler.UpdateProfile)
+	protected.Post("/profile/avatar", imageHandler.UploadMyAvatar)
+	protected.Get("/profile/card", cardHandler.GetMyCard)
+	protected.Get("/profile/completeness", memberHandler.GetMyCompleteness)
 	protected.Get("/profile/fees", feeHandler.GetMyFees)
 	protected.Get("/profile/events", eventHandler.GetMyEvents)
+	protected.Get("/profile/participation", participationHandler.GetMine)
+	protected.Post("/profile/calendar-token", calendarHandler.CreateToken)
+	protected.Delete("/profile/calendar-token", calendarHandler.RevokeToken)
+	protected.Get("/profile/notifications", notificationHandler.ListMine)
+	protected.Post("/profile/notifications/:id/read", notificationHandler.MarkRead)
 
 	// Admin endpoints - Settings and Audit Logs (national_admin only)
 	admin := protected.Group("/admin", middleware.RequirePermission(models.ResourceSettings, models.ActionRead))
@@ -214,6 +356,7 @@ func main() {
 
 	<-quit
 	log.Info().Msg("Shutting down server...")
+	stopWorkers()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -23,6 +23,32 @@ type Config struct {
 	MinioAccessKey string
 	MinioSecretKey string
 	MinioBucket    string
+	MinioUseSSL    bool
+	// MinioPublicURL is the base URL clients use to reach MinIO (e.g.
+	// https://minio.e-sdy.mn). Presigned URLs are signed for this host.
+	// When empty, MinioEndpoint is used.
+	MinioPublicURL string
+
+	// Membership renewal: months a paid annual (no month) or monthly fee
+	// extends membership_expires_at by
+	MembershipAnnualTermMonths  int
+	MembershipMonthlyTermMonths int
+
+	// DocumentMaxSizeMB is the largest member document accepted for upload
+	DocumentMaxSizeMB int
+
+	// CardSigningKey signs the QR tokens on membership cards. When empty a
+	// key is derived from JWTSecret. Changing it invalidates printed cards.
+	CardSigningKey string
+
+	// EventSeriesHorizonDays is how far ahead occurrences of recurring
+	// events are created
+	EventSeriesHorizonDays int
+
+	// Participation points: earned per attended event and per credited
+	// volunteer hour
+	ParticipationPointsPerEvent int
+	ParticipationPointsPerHour  int
 }
 
 func Load() (*Config, error) {
@@ -30,6 +56,12 @@ func Load() (*Config, error) {
 
 	viper.SetDefault("APP_ENV", "development")
 	viper.SetDefault("APP_PORT", "8080")
+	viper.SetDefault("MEMBERSHIP_ANNUAL_TERM_MONTHS", 12)
+	viper.SetDefault("MEMBERSHIP_MONTHLY_TERM_MONTHS", 1)
+	viper.SetDefault("DOCUMENT_MAX_SIZE_MB", 10)
+	viper.SetDefault("EVENT_SERIES_HORIZON_DAYS", 90)
+	viper.SetDefault("PARTICIPATION_POINTS_PER_EVENT", 10)
+	viper.SetDefault("PARTICIPATION_POINTS_PER_HOUR", 5)
 
 	cfg := &Config{
 		Env:                  viper.GetString("APP_ENV"),
@@ -46,6 +78,19 @@ func Load() (*Config, error) {
 		MinioAccessKey:       viper.GetString("MINIO_ACCESS_KEY"),
 		MinioSecretKey:       viper.GetString("MINIO_SECRET_KEY"),
 		MinioBucket:          viper.GetString("MINIO_BUCKET"),
+		MinioUseSSL:          viper.GetBool("MINIO_USE_SSL"),
+		MinioPublicURL:       viper.GetString("MINIO_PUBLIC_URL"),
+
+		MembershipAnnualTermMonths:  viper.GetInt("MEMBERSHIP_ANNUAL_TERM_MONTHS"),
+		MembershipMonthlyTermMonths: viper.GetInt("MEMBERSHIP_MONTHLY_TERM_MONTHS"),
+
+		DocumentMaxSizeMB: viper.GetInt("DOCUMENT_MAX_SIZE_MB"),
+		CardSigningKey:    viper.GetString("CARD_SIGNING_KEY"),
+
+		EventSeriesHorizonDays: viper.GetInt("EVENT_SERIES_HORIZON_DAYS"),
+
+		ParticipationPointsPerEvent: viper.GetInt("PARTICIPATION_POINTS_PER_EVENT"),
+		ParticipationPointsPerHour:  viper.GetInt("PARTICIPATION_POINTS_PER_HOUR"),
 	}
 
 	if cfg.AllowedOrigins == "" {
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/sdyn/backend/internal/middleware"
+	"github.com/sdyn/backend/internal/models"
+	"github.com/sdyn/backend/internal/services"
+)
+
+type ApprovalHandler struct {
+	service  *services.ApprovalService
+	validate *validator.Validate
+}
+
+func NewApprovalHandler(service *services.ApprovalService) *ApprovalHandler {
+	return &ApprovalHandler{
+		service:  service,
+		validate: validator.New(),
+	}
+}
+
+// List returns the membership applications in the caller's data scope
+func (h *ApprovalHandler) List(c *fiber.Ctx) error {
+	params := new(models.ApprovalListParams)
+	if err := c.QueryParser(params); err != nil {
+		return BadRequest(c, "Invalid query parameters")
+	}
+
+	if params.Page <= 0 {
+		params.Page = 1
+	}
+	if params.Limit <= 0 || params.Limit > 100 {
+		params.Limit = 20
+	}
+
+	provinceID, districtID, organizationID, ok := resolveScope(c)
+	if !ok {
+		return Forbidden(c, "You don't have access to the approval queue")
+	}
+	params.ProvinceID = provinceID
+	params.DistrictID = districtID
+	if organizationID != nil {
+		params.OrganizationID = organizationID
+		level := models.ApprovalLevelDistrict
+		params.Level = &level
+	}
+
+	result, err := h.service.List(c.Context(), params)
+	if err != nil {
+		return InternalError(c, "Failed to fetch applications")
+	}
+
+	return c.JSON(result)
+}
+
+// Approve approves a pending application and activates the member
+func (h *ApprovalHandler) Approve(c *fiber.Ctx) error {
+	return h.decide(c, h.service.Approve)
+}
+
+// Reject rejects a pending application. reason is required.
+func (h *ApprovalHandler) Reject(c *fiber.Ctx) error {
+	return h.decide(c, h.service.Reject)
+}
+
+// Escalate hands a pending application to the next admin level
+func (h *ApprovalHandler) Escalate(c *fiber.Ctx) error {
+	return h.decide(c, h.service.Escalate)
+}
+
+type approvalAction func(ctx context.Context, id uuid.UUID, req *models.ApprovalDecisionRequest, filter *services.DataScopeFilter, changedBy string) (*models.MembershipApplication, error)
+
+func (h *ApprovalHandler) decide(c *fiber.Ctx, action approvalAction) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return BadRequest(c, "Invalid application ID")
+	}
+
+	req := new(models.ApprovalDecisionRequest)
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(req); err != nil {
+			return BadRequest(c, "Invalid request body")
+		}
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		return ValidationError(c, err.Error())
+	}
+
+	app, err := action(c.Context(), id, req, middleware.GetDataScope(c), middleware.GetUserID(c))
+	if err != nil {
+		var approvalErr *services.ApprovalError
+		if errors.As(err, &approvalErr) {
+			if approvalErr.Forbidden {
+				return Forbidden(c, approvalErr.Message)
+			}
+			return BadRequest(c, approvalErr.Message)
+		}
+		if errors.Is(err, services.ErrApplicationClosed) {
+			return Conflict(c, "Application is no longer pending")
+		}
+		if errors.Is(err, pgx.ErrNoRows) {
+			return NotFound(c, "Application not found")
+		}
+		return InternalError(c, "Failed to update application")
+	}
+
+	return c.JSON(app)
+}
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog/log"
+
+	"github.com/sdyn/backend/internal/middleware"
+	"github.com/sdyn/backend/internal/services"
+)
+
+type CalendarHandler struct {
+	service *services.CalendarService
+}
+
+func NewCalendarHandler(service *services.CalendarService) *CalendarHandler {
+	return &CalendarHandler{service: service}
+}
+
+// CreateToken issues a new calendar feed token for the current user and
+// returns the feed URLs. An earlier token stops working.
+func (h *CalendarHandler) CreateToken(c *fiber.Ctx) error {
+	memberID, err := uuid.Parse(middleware.GetUserID(c))
+	if err != nil {
+		return BadRequest(c, "Invalid user ID")
+	}
+
+	token, err := h.service.CreateToken(c.Context(), memberID)
+	if err != nil {
+		return InternalError(c, "Failed to create calendar token")
+	}
+
+	base := c.BaseURL() + "/api/v1/calendar"
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"token":             token,
+		"member_feed":       base + "/me.ics?token=" + token,
+		"organization_feed": base + "/organizations/{organization_id}.ics?token=" + token,
+		"public_feed":       base + "/public.ics",
+	})
+}
+
+// RevokeToken revokes the current user's calendar feed token
+func (h *CalendarHandler) RevokeToken(c *fiber.Ctx) error {
+	memberID, err := uuid.Parse(middleware.GetUserID(c))
+	if err != nil {
+		return BadRequest(c, "Invalid user ID")
+	}
+
+	revoked, err := h.service.RevokeToken(c.Context(), memberID)
+	if err != nil {
+		return InternalError(c, "Failed to revoke calendar token")
+	}
+	if !revoked {
+		return NotFound(c, "No calendar token")
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// MemberFeed returns the token owner's events as an ICS feed
+func (h *CalendarHandler) MemberFeed(c *fiber.Ctx) error {
+	memberID, err := h.service.Authenticate(c.Context(), c.Query("token"))
+	if err != nil {
+		return calendarTokenError(c, err)
+	}
+
+	ics, err := h.service.MemberCalendar(c.Context(), memberID)
+	if err != nil {
+		log.Error().Err(err).Str("member_id", memberID.String()).Msg("Failed to render member calendar")
+		return InternalError(c, "Failed to generate calendar")
+	}
+	return sendCalendar(c, ics, "no-store")
+}
+
+// OrganizationFeed returns an organization's events as an ICS feed. Any
+// valid feed token can read it, like the events API.
+func (h *CalendarHandler) OrganizationFeed(c *fiber.Ctx) error {
+	orgID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return BadRequest(c, "Invalid organization ID")
+	}
+	if _, err := h.service.Authenticate(c.Context(), c.Query("token")); err != nil {
+		return calendarTokenError(c, err)
+	}
+
+	ics, err := h.service.OrganizationCalendar(c.Context(), orgID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return NotFound(c, "Organization not found")
+		}
+		log.Error().Err(err).Str("organization_id", orgID.String()).Msg("Failed to render organization calendar")
+		return InternalError(c, "Failed to generate calendar")
+	}
+	return sendCalendar(c, ics, "no-store")
+}
+
+// PublicFeed returns the public events as an ICS feed. It needs no token.
+func (h *CalendarHandler) PublicFeed(c *fiber.Ctx) error {
+	ics, err := h.service.PublicCalendar(c.Context())
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to render public calendar")
+		return InternalError(c, "Failed to generate calendar")
+	}
+	return sendCalendar(c, ics, "public, max-age=900")
+}
+
+func calendarTokenError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Unauthorized(c, "Invalid calendar token")
+	}
+	return InternalError(c, "Failed to check calendar token")
+}
+
+func sendCalendar(c *fiber.Ctx, ics []byte, cacheControl string) error {
+	c.Set(fiber.HeaderContentType, "text/calendar; charset=utf-8")
+	c.Set(fiber.HeaderCacheControl, cacheControl)
+	return c.Send(ics)
+}
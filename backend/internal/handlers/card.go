@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog/log"
+
+	"github.com/sdyn/backend/internal/middleware"
+	"github.com/sdyn/backend/internal/services"
+)
+
+type CardHandler struct {
+	service *services.MemberCardService
+}
+
+func NewCardHandler(service *services.MemberCardService) *CardHandler {
+	return &CardHandler{service: service}
+}
+
+// GetCard returns the membership card of the member in :id as a PDF
+func (h *CardHandler) GetCard(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return BadRequest(c, "Invalid member ID")
+	}
+	return h.render(c, id)
+}
+
+// GetMyCard returns current user's membership card
+func (h *CardHandler) GetMyCard(c *fiber.Ctx) error {
+	id, err := uuid.Parse(middleware.GetUserID(c))
+	if err != nil {
+		return BadRequest(c, "Invalid user ID")
+	}
+	return h.render(c, id)
+}
+
+func (h *CardHandler) render(c *fiber.Ctx, id uuid.UUID) error {
+	var buf bytes.Buffer
+	member, err := h.service.Render(c.Context(), id, &buf)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return NotFound(c, "Member not found")
+		}
+		log.Error().Err(err).Str("member_id", id.String()).Msg("Failed to render membership card")
+		return InternalError(c, "Failed to generate card")
+	}
+
+	c.Set(fiber.HeaderContentType, "application/pdf")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`inline; filename="card-%s.pdf"`, member.MemberID))
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.Send(buf.Bytes())
+}
+
+// Verify checks the token from a membership card QR code. It is public so
+// anyone can check a card; only the token holder's basic details are shown.
+func (h *CardHandler) Verify(c *fiber.Ctx) error {
+	token := c.Query("token")
+	if token == "" {
+		return BadRequest(c, "Token is required")
+	}
+
+	result, err := h.service.Verify(c.Context(), token)
+	if err != nil {
+		return InternalError(c, "Failed to verify card")
+	}
+
+	return c.JSON(result)
+}
@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog/log"
+
+	"github.com/sdyn/backend/internal/middleware"
+	"github.com/sdyn/backend/internal/models"
+	"github.com/sdyn/backend/internal/services"
+)
+
+type DocumentHandler struct {
+	service  *services.DocumentService
+	validate *validator.Validate
+}
+
+func NewDocumentHandler(service *services.DocumentService) *DocumentHandler {
+	return &DocumentHandler{
+		service:  service,
+		validate: validator.New(),
+	}
+}
+
+// List returns the documents of the member in :id
+func (h *DocumentHandler) List(c *fiber.Ctx) error {
+	memberID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return BadRequest(c, "Invalid member ID")
+	}
+
+	documents, err := h.service.List(c.Context(), memberID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return NotFound(c, "Member not found")
+		}
+		return InternalError(c, "Failed to fetch documents")
+	}
+
+	return c.JSON(documents)
+}
+
+// Get returns a document with a short-lived download URL
+func (h *DocumentHandler) Get(c *fiber.Ctx) error {
+	memberID, documentID, ok := documentParams(c)
+	if !ok {
+		return BadRequest(c, "Invalid member or document ID")
+	}
+
+	doc, err := h.service.Get(c.Context(), memberID, documentID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return NotFound(c, "Document not found")
+		}
+		log.Error().Err(err).Str("document_id", documentID.String()).Msg("Failed to presign document download")
+		return InternalError(c, "Failed to create download URL")
+	}
+
+	return c.JSON(doc)
+}
+
+// Upload stores a document against the member in :id. The multipart form
+// carries the file in "file" and the document type in "type".
+func (h *DocumentHandler) Upload(c *fiber.Ctx) error {
+	memberID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return BadRequest(c, "Invalid member ID")
+	}
+
+	req := new(models.UploadDocumentRequest)
+	if err := c.BodyParser(req); err != nil {
+		return BadRequest(c, "Invalid request body")
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		return ValidationError(c, err.Error())
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return BadRequest(c, "File is required")
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return BadRequest(c, "Failed to read file")
+	}
+	defer file.Close()
+
+	doc, err := h.service.Upload(c.Context(), memberID, req, fileHeader.Filename, file, fileHeader.Size, middleware.GetUserID(c))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return NotFound(c, "Member not found")
+		}
+		var docErr *services.DocumentError
+		if errors.As(err, &docErr) {
+			return BadRequest(c, docErr.Message)
+		}
+		log.Error().Err(err).Str("member_id", memberID.String()).Msg("Failed to upload document")
+		return InternalError(c, "Failed to upload document")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(doc)
+}
+
+// Delete removes a document and its file
+func (h *DocumentHandler) Delete(c *fiber.Ctx) error {
+	memberID, documentID, ok := documentParams(c)
+	if !ok {
+		return BadRequest(c, "Invalid member or document ID")
+	}
+
+	if err := h.service.Delete(c.Context(), memberID, documentID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return NotFound(c, "Document not found")
+		}
+		return InternalError(c, "Failed to delete document")
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func documentParams(c *fiber.Ctx) (uuid.UUID, uuid.UUID, bool) {
+	memberID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, false
+	}
+	documentID, err := uuid.Parse(c.Params("documentId"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, false
+	}
+	return memberID, documentID, true
+}
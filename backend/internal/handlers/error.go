@@ -87,6 +87,14 @@ func Forbidden(c *fiber.Ctx, message string) error {
 	})
 }
 
+func Conflict(c *fiber.Ctx, message string) error {
+	return c.Status(fiber.StatusConflict).JSON(ErrorResponse{
+		Error:   "Conflict",
+		Message: message,
+		Code:    fiber.StatusConflict,
+	})
+}
+
 func InternalError(c *fiber.Ctx, message string) error {
 	return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
 		Error:   "Internal Server Error",
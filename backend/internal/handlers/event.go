@@ -1,9 +1,12 @@
 package handlers
 
 import (
+	"errors"
+
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 
 	"github.com/sdyn/backend/internal/middleware"
 	"github.com/sdyn/backend/internal/models"
@@ -12,12 +15,14 @@ import (
 
 type EventHandler struct {
 	service  *services.EventService
+	series   *services.EventSeriesService
 	validate *validator.Validate
 }
 
-func NewEventHandler(service *services.EventService) *EventHandler {
+func NewEventHandler(service *services.EventService, series *services.EventSeriesService) *EventHandler {
 	return &EventHandler{
 		service:  service,
+		series:   series,
 		validate: validator.New(),
 	}
 }
@@ -36,8 +41,15 @@ func (h *EventHandler) List(c *fiber.Ctx) error {
 		params.Limit = 20
 	}
 
+	if err := h.validate.Struct(params); err != nil {
+		return ValidationError(c, err.Error())
+	}
+
 	result, err := h.service.List(c.Context(), params)
 	if err != nil {
+		if errors.Is(err, models.ErrInvalidCursor) {
+			return BadRequest(c, "Invalid cursor")
+		}
 		return InternalError(c, "Failed to fetch events")
 	}
 
@@ -79,13 +91,19 @@ func (h *EventHandler) Create(c *fiber.Ctx) error {
 	return c.Status(fiber.StatusCreated).JSON(event)
 }
 
-// Update updates an event
+// Update updates an event. For an occurrence of a series, ?scope=future
+// applies the change to the series and the following occurrences too.
 func (h *EventHandler) Update(c *fiber.Ctx) error {
 	id, err := uuid.Parse(c.Params("id"))
 	if err != nil {
 		return BadRequest(c, "Invalid event ID")
 	}
 
+	scope := c.Query("scope", models.EventUpdateScopeThis)
+	if scope != models.EventUpdateScopeThis && scope != models.EventUpdateScopeFuture {
+		return BadRequest(c, "Invalid scope")
+	}
+
 	req := new(models.UpdateEventRequest)
 	if err := c.BodyParser(req); err != nil {
 		return BadRequest(c, "Invalid request body")
@@ -95,8 +113,15 @@ func (h *EventHandler) Update(c *fiber.Ctx) error {
 		return ValidationError(c, err.Error())
 	}
 
-	event, err := h.service.Update(c.Context(), id, req)
+	event, err := h.series.Update(c.Context(), id, req, scope)
 	if err != nil {
+		var eventErr *services.EventError
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			return NotFound(c, "Event not found")
+		case errors.As(err, &eventErr):
+			return BadRequest(c, eventErr.Message)
+		}
 		return InternalError(c, "Failed to update event")
 	}
 
@@ -130,15 +155,112 @@ func (h *EventHandler) Register(c *fiber.Ctx) error {
 		return BadRequest(c, "Invalid member ID")
 	}
 
-	if err := h.service.RegisterParticipant(c.Context(), eventID, mID); err != nil {
-		return InternalError(c, "Failed to register: "+err.Error())
+	participant, err := h.service.RegisterParticipant(c.Context(), eventID, mID)
+	if err != nil {
+		var eventErr *services.EventError
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			return NotFound(c, "Event not found")
+		case errors.As(err, &eventErr):
+			return BadRequest(c, eventErr.Message)
+		}
+		return InternalError(c, "Failed to register")
+	}
+
+	message := "Successfully registered for event"
+	if participant.Status == models.ParticipantStatusWaitlisted {
+		message = "Event is full, you have been added to the waitlist"
 	}
 
 	return c.JSON(fiber.Map{
-		"message": "Successfully registered for event",
+		"message":     message,
+		"participant": participant,
 	})
 }
 
+// CancelRegistration cancels current user's registration for the event
+func (h *EventHandler) CancelRegistration(c *fiber.Ctx) error {
+	eventID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return BadRequest(c, "Invalid event ID")
+	}
+
+	memberID, err := uuid.Parse(middleware.GetUserID(c))
+	if err != nil {
+		return BadRequest(c, "Invalid member ID")
+	}
+
+	participant, err := h.service.CancelRegistration(c.Context(), eventID, memberID)
+	if err != nil {
+		var eventErr *services.EventError
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			return NotFound(c, "You are not registered for this event")
+		case errors.As(err, &eventErr):
+			return BadRequest(c, eventErr.Message)
+		}
+		return InternalError(c, "Failed to cancel registration")
+	}
+
+	return c.JSON(participant)
+}
+
+// RemoveParticipant removes a participant from the event with a reason
+func (h *EventHandler) RemoveParticipant(c *fiber.Ctx) error {
+	eventID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return BadRequest(c, "Invalid event ID")
+	}
+
+	memberID, err := uuid.Parse(c.Params("memberId"))
+	if err != nil {
+		return BadRequest(c, "Invalid member ID")
+	}
+
+	req := new(models.RemoveParticipantRequest)
+	if err := c.BodyParser(req); err != nil {
+		return BadRequest(c, "Invalid request body")
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		return ValidationError(c, err.Error())
+	}
+
+	participant, err := h.service.RemoveParticipant(c.Context(), eventID, memberID, req, middleware.GetUserID(c))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return NotFound(c, "Participant not found")
+		}
+		return InternalError(c, "Failed to remove participant")
+	}
+
+	return c.JSON(participant)
+}
+
+// GetMyRegistration returns current user's registration for the event,
+// including the waitlist position
+func (h *EventHandler) GetMyRegistration(c *fiber.Ctx) error {
+	eventID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return BadRequest(c, "Invalid event ID")
+	}
+
+	memberID, err := uuid.Parse(middleware.GetUserID(c))
+	if err != nil {
+		return BadRequest(c, "Invalid member ID")
+	}
+
+	participant, err := h.service.GetRegistration(c.Context(), eventID, memberID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return NotFound(c, "You are not registered for this event")
+		}
+		return InternalError(c, "Failed to fetch registration")
+	}
+
+	return c.JSON(participant)
+}
+
 // MarkAttendance marks attendance for participants
 func (h *EventHandler) MarkAttendance(c *fiber.Ctx) error {
 	eventID, err := uuid.Parse(c.Params("id"))
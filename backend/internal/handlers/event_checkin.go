@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/sdyn/backend/internal/middleware"
+	"github.com/sdyn/backend/internal/models"
+	"github.com/sdyn/backend/internal/services"
+)
+
+type CheckinHandler struct {
+	service  *services.EventCheckinService
+	validate *validator.Validate
+}
+
+func NewCheckinHandler(service *services.EventCheckinService) *CheckinHandler {
+	return &CheckinHandler{
+		service:  service,
+		validate: validator.New(),
+	}
+}
+
+// GetSettings returns the settings of the event in :id
+func (h *CheckinHandler) GetSettings(c *fiber.Ctx) error {
+	eventID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return BadRequest(c, "Invalid event ID")
+	}
+
+	settings, err := h.service.GetSettings(c.Context(), eventID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return NotFound(c, "Event not found")
+		}
+		return InternalError(c, "Failed to fetch event settings")
+	}
+
+	return c.JSON(settings)
+}
+
+// UpdateSettings changes the settings of the event in :id
+func (h *CheckinHandler) UpdateSettings(c *fiber.Ctx) error {
+	eventID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return BadRequest(c, "Invalid event ID")
+	}
+
+	req := new(models.UpdateEventSettingsRequest)
+	if err := c.BodyParser(req); err != nil {
+		return BadRequest(c, "Invalid request body")
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		return ValidationError(c, err.Error())
+	}
+
+	settings, err := h.service.UpdateSettings(c.Context(), eventID, req)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return NotFound(c, "Event not found")
+		}
+		return InternalError(c, "Failed to update event settings")
+	}
+
+	return c.JSON(settings)
+}
+
+// GetMyTicket returns current user's check-in ticket for the event in :id
+func (h *CheckinHandler) GetMyTicket(c *fiber.Ctx) error {
+	eventID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return BadRequest(c, "Invalid event ID")
+	}
+
+	memberID, err := uuid.Parse(middleware.GetUserID(c))
+	if err != nil {
+		return BadRequest(c, "Invalid member ID")
+	}
+
+	ticket, err := h.service.Ticket(c.Context(), eventID, memberID)
+	if err != nil {
+		var checkinErr *services.CheckinError
+		switch {
+		case errors.Is(err, services.ErrNotRegistered):
+			return NotFound(c, "You are not registered for this event")
+		case errors.As(err, &checkinErr):
+			return Conflict(c, checkinErr.Message)
+		}
+		return InternalError(c, "Failed to create ticket")
+	}
+
+	return c.JSON(ticket)
+}
+
+// CheckIn checks in the holder of a scanned ticket or membership card
+func (h *CheckinHandler) CheckIn(c *fiber.Ctx) error {
+	eventID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return BadRequest(c, "Invalid event ID")
+	}
+
+	req := new(models.CheckinRequest)
+	if err := c.BodyParser(req); err != nil {
+		return BadRequest(c, "Invalid request body")
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		return ValidationError(c, err.Error())
+	}
+
+	result, err := h.service.CheckIn(c.Context(), eventID, req)
+	if err != nil {
+		var checkinErr *services.CheckinError
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			return NotFound(c, "Event not found")
+		case errors.Is(err, services.ErrNotRegistered):
+			return Forbidden(c, "Member is not registered for this event")
+		case errors.Is(err, services.ErrAlreadyCheckedIn):
+			return Conflict(c, "Member has already checked in")
+		case errors.As(err, &checkinErr):
+			return BadRequest(c, checkinErr.Message)
+		}
+		return InternalError(c, "Failed to check in")
+	}
+
+	return c.JSON(result)
+}
+
+// CheckOut checks out the holder of a scanned ticket or membership card and
+// credits their hours
+func (h *CheckinHandler) CheckOut(c *fiber.Ctx) error {
+	eventID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return BadRequest(c, "Invalid event ID")
+	}
+
+	req := new(models.CheckinRequest)
+	if err := c.BodyParser(req); err != nil {
+		return BadRequest(c, "Invalid request body")
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		return ValidationError(c, err.Error())
+	}
+
+	participant, err := h.service.CheckOut(c.Context(), eventID, req)
+	if err != nil {
+		var checkinErr *services.CheckinError
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			return NotFound(c, "Event not found")
+		case errors.Is(err, services.ErrNotCheckedIn):
+			return Forbidden(c, "Member has not checked in")
+		case errors.Is(err, services.ErrAlreadyCheckedOut):
+			return Conflict(c, "Member has already checked out")
+		case errors.As(err, &checkinErr):
+			return BadRequest(c, checkinErr.Message)
+		}
+		return InternalError(c, "Failed to check out")
+	}
+
+	return c.JSON(participant)
+}
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/sdyn/backend/internal/middleware"
+	"github.com/sdyn/backend/internal/models"
+	"github.com/sdyn/backend/internal/services"
+)
+
+type EventSeriesHandler struct {
+	service  *services.EventSeriesService
+	validate *validator.Validate
+}
+
+func NewEventSeriesHandler(service *services.EventSeriesService) *EventSeriesHandler {
+	return &EventSeriesHandler{
+		service:  service,
+		validate: validator.New(),
+	}
+}
+
+// Create creates a recurring event and its first occurrences
+func (h *EventSeriesHandler) Create(c *fiber.Ctx) error {
+	req := new(models.CreateEventSeriesRequest)
+	if err := c.BodyParser(req); err != nil {
+		return BadRequest(c, "Invalid request body")
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		return ValidationError(c, err.Error())
+	}
+
+	series, err := h.service.Create(c.Context(), req, middleware.GetUserID(c))
+	if err != nil {
+		var eventErr *services.EventError
+		if errors.As(err, &eventErr) {
+			return BadRequest(c, eventErr.Message)
+		}
+		return InternalError(c, "Failed to create event series")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(series)
+}
+
+// Get returns a series with its occurrences
+func (h *EventSeriesHandler) Get(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return BadRequest(c, "Invalid series ID")
+	}
+
+	series, err := h.service.GetByID(c.Context(), id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return NotFound(c, "Event series not found")
+		}
+		return InternalError(c, "Failed to fetch event series")
+	}
+
+	return c.JSON(series)
+}
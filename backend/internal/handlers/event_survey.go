@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/sdyn/backend/internal/middleware"
+	"github.com/sdyn/backend/internal/models"
+	"github.com/sdyn/backend/internal/services"
+)
+
+type EventSurveyHandler struct {
+	service  *services.EventSurveyService
+	validate *validator.Validate
+}
+
+func NewEventSurveyHandler(service *services.EventSurveyService) *EventSurveyHandler {
+	return &EventSurveyHandler{
+		service:  service,
+		validate: validator.New(),
+	}
+}
+
+// Get returns the survey of the event in :id
+func (h *EventSurveyHandler) Get(c *fiber.Ctx) error {
+	eventID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return BadRequest(c, "Invalid event ID")
+	}
+
+	survey, err := h.service.Get(c.Context(), eventID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return NotFound(c, "Survey not found")
+		}
+		return InternalError(c, "Failed to fetch survey")
+	}
+
+	return c.JSON(survey)
+}
+
+// Update replaces the survey questions of the event in :id
+func (h *EventSurveyHandler) Update(c *fiber.Ctx) error {
+	eventID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return BadRequest(c, "Invalid event ID")
+	}
+
+	req := new(models.UpdateEventSurveyRequest)
+	if err := c.BodyParser(req); err != nil {
+		return BadRequest(c, "Invalid request body")
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		return ValidationError(c, err.Error())
+	}
+
+	survey, err := h.service.Update(c.Context(), eventID, req)
+	if err != nil {
+		var eventErr *services.EventError
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			return NotFound(c, "Event not found")
+		case errors.Is(err, models.ErrSurveyAnswered):
+			return Conflict(c, "Survey already has responses")
+		case errors.As(err, &eventErr):
+			return BadRequest(c, eventErr.Message)
+		}
+		return InternalError(c, "Failed to update survey")
+	}
+
+	return c.JSON(survey)
+}
+
+// Submit saves current user's answers to the survey of the event in :id
+func (h *EventSurveyHandler) Submit(c *fiber.Ctx) error {
+	eventID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return BadRequest(c, "Invalid event ID")
+	}
+
+	memberID, err := uuid.Parse(middleware.GetUserID(c))
+	if err != nil {
+		return BadRequest(c, "Invalid member ID")
+	}
+
+	req := new(models.SubmitSurveyRequest)
+	if err := c.BodyParser(req); err != nil {
+		return BadRequest(c, "Invalid request body")
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		return ValidationError(c, err.Error())
+	}
+
+	response, err := h.service.Submit(c.Context(), eventID, memberID, req)
+	if err != nil {
+		var eventErr *services.EventError
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			return NotFound(c, "Survey not found")
+		case errors.Is(err, services.ErrNotAttended):
+			return Forbidden(c, "Only attendees can answer the survey")
+		case errors.Is(err, models.ErrSurveySubmitted):
+			return Conflict(c, "You have already answered the survey")
+		case errors.As(err, &eventErr):
+			return BadRequest(c, eventErr.Message)
+		}
+		return InternalError(c, "Failed to submit survey")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(response)
+}
+
+// Results returns the aggregated answers to the survey of the event in :id
+func (h *EventSurveyHandler) Results(c *fiber.Ctx) error {
+	eventID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return BadRequest(c, "Invalid event ID")
+	}
+
+	results, err := h.service.Results(c.Context(), eventID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return NotFound(c, "Survey not found")
+		}
+		return InternalError(c, "Failed to fetch survey results")
+	}
+
+	return c.JSON(results)
+}
@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+
+	"github.com/sdyn/backend/internal/middleware"
+	"github.com/sdyn/backend/internal/models"
+	"github.com/sdyn/backend/internal/services"
+)
+
+type ExportHandler struct {
+	service      *services.ExportService
+	jobService   *services.ExportJobService
+	authzService *services.AuthorizationService
+}
+
+func NewExportHandler(service *services.ExportService, jobService *services.ExportJobService, authzService *services.AuthorizationService) *ExportHandler {
+	return &ExportHandler{
+		service:      service,
+		jobService:   jobService,
+		authzService: authzService,
+	}
+}
+
+// ExportReport streams a CSV or XLSX export of members, fees, events or organizations
+func (h *ExportHandler) ExportReport(c *fiber.Ctx) error {
+	params, ok := parseExportParams(c)
+	if !ok {
+		return nil
+	}
+
+	c.Set(fiber.HeaderContentType, params.Format.ContentType())
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`, services.ExportFileName(params, time.Now())))
+
+	// The stream writer runs after the handler returns, so it must not touch c
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		if err := h.service.Export(context.Background(), w, params); err != nil {
+			log.Error().Err(err).Str("type", string(params.Type)).Msg("Export failed")
+		}
+		w.Flush()
+	})
+
+	return nil
+}
+
+// CreateJob queues a background export. Use it for exports that are too large
+// to stream within the request timeout.
+func (h *ExportHandler) CreateJob(c *fiber.Ctx) error {
+	params, ok := parseExportParams(c)
+	if !ok {
+		return nil
+	}
+
+	job, err := h.jobService.Create(c.Context(), middleware.GetUserID(c), params)
+	if err != nil {
+		return InternalError(c, "Failed to create export job")
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(job)
+}
+
+// GetJob returns the status of an export job. Completed jobs include a
+// short-lived download URL; every URL handed out is recorded in the audit log.
+func (h *ExportHandler) GetJob(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return BadRequest(c, "Invalid export job ID")
+	}
+
+	job, err := h.jobService.GetByID(c.Context(), id, middleware.GetUserID(c))
+	if err != nil {
+		if errors.Is(err, services.ErrExportJobNotFound) {
+			return NotFound(c, "Export job not found")
+		}
+		return InternalError(c, "Failed to fetch export job")
+	}
+
+	if err := h.jobService.AttachDownloadURL(c.Context(), job); err != nil {
+		log.Error().Err(err).Str("job_id", job.ID.String()).Msg("Failed to presign export download")
+		return InternalError(c, "Failed to create download URL")
+	}
+
+	if job.DownloadURL != nil {
+		h.auditDownload(c, job)
+	}
+
+	return c.JSON(job)
+}
+
+func (h *ExportHandler) auditDownload(c *fiber.Ctx, job *models.ExportJob) {
+	auditLog := &models.AuditLog{
+		ID:            uuid.New().String(),
+		Timestamp:     time.Now().UTC().Format(time.RFC3339),
+		UserID:        middleware.GetUserID(c),
+		MemberID:      middleware.GetMemberID(c),
+		Action:        "download",
+		Resource:      "exports",
+		ResourceID:    job.ID.String(),
+		IPAddress:     c.IP(),
+		UserAgent:     c.Get("User-Agent"),
+		Status:        "success",
+		StatusCode:    fiber.StatusOK,
+		RequestMethod: c.Method(),
+		RequestPath:   c.Path(),
+		Changes: map[string]interface{}{
+			"type":      job.Params.Type,
+			"format":    job.Params.Format,
+			"file_name": job.FileName,
+			"file_size": job.FileSize,
+		},
+	}
+	if email, ok := c.Locals("email").(string); ok {
+		auditLog.Email = email
+	}
+	if orgID := middleware.GetOrganizationID(c); orgID != nil {
+		auditLog.OrganizationID = *orgID
+	}
+
+	if err := h.authzService.SaveAuditLog(c.Context(), auditLog); err != nil {
+		log.Error().Err(err).Str("job_id", job.ID.String()).Msg("Failed to audit export download")
+	}
+}
+
+// parseExportParams reads export params from the request, applies the
+// caller's data scope and validates the result. If it is not ok the error
+// response has already been written.
+func parseExportParams(c *fiber.Ctx) (*models.ExportParams, bool) {
+	params := new(models.ExportParams)
+	if err := c.QueryParser(params); err != nil {
+		BadRequest(c, "Invalid query parameters")
+		return nil, false
+	}
+	params.Type = models.ExportType(c.Params("type"))
+
+	if columns := c.Query("columns"); columns != "" {
+		for _, col := range strings.Split(columns, ",") {
+			if col = strings.TrimSpace(col); col != "" {
+				params.Columns = append(params.Columns, col)
+			}
+		}
+	}
+
+	provinceID, districtID, organizationID, ok := resolveScope(c)
+	if !ok {
+		Forbidden(c, "Your data scope does not allow exports")
+		return nil, false
+	}
+	params.ProvinceID = provinceID
+	params.DistrictID = districtID
+	if organizationID != nil {
+		params.OrganizationID = organizationID
+	}
+
+	if err := services.ValidateExportParams(params); err != nil {
+		BadRequest(c, err.Error())
+		return nil, false
+	}
+
+	return params, true
+}
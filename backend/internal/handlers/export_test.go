@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sdyn/backend/internal/models"
+	"github.com/sdyn/backend/internal/services"
+)
+
+func setupExportTestApp(scope *services.DataScopeFilter) *fiber.App {
+	app := setupTestApp()
+	app.Use(func(c *fiber.Ctx) error {
+		if scope != nil {
+			c.Locals("data_scope", scope)
+		}
+		return c.Next()
+	})
+
+	h := NewExportHandler(nil, nil, nil)
+	app.Get("/api/v1/reports/export/:type", h.ExportReport)
+	app.Post("/api/v1/exports/:type", h.CreateJob)
+	return app
+}
+
+func TestExportHandler_ExportReportRejectsInvalidType(t *testing.T) {
+	app := setupExportTestApp(&services.DataScopeFilter{Scope: models.ScopeAll})
+
+	req := httptest.NewRequest("GET", "/api/v1/reports/export/positions", nil)
+	resp, err := app.Test(req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 400, resp.StatusCode)
+}
+
+func TestExportHandler_ExportReportRejectsCallerWithoutScope(t *testing.T) {
+	app := setupExportTestApp(nil)
+
+	req := httptest.NewRequest("GET", "/api/v1/reports/export/members", nil)
+	resp, err := app.Test(req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 403, resp.StatusCode)
+}
+
+func TestExportHandler_CreateJobRejectsInvalidType(t *testing.T) {
+	app := setupExportTestApp(&services.DataScopeFilter{Scope: models.ScopeAll})
+
+	req := httptest.NewRequest("POST", "/api/v1/exports/positions", nil)
+	resp, err := app.Test(req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 400, resp.StatusCode)
+}
+
+func TestExportHandler_CreateJobRejectsCallerWithoutScope(t *testing.T) {
+	app := setupExportTestApp(nil)
+
+	req := httptest.NewRequest("POST", "/api/v1/exports/members", nil)
+	resp, err := app.Test(req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 403, resp.StatusCode)
+}
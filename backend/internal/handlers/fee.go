@@ -1,6 +1,8 @@
 package handlers
 
 import (
+	"errors"
+
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
@@ -36,8 +38,15 @@ func (h *FeeHandler) List(c *fiber.Ctx) error {
 		params.Limit = 20
 	}
 
+	if err := h.validate.Struct(params); err != nil {
+		return ValidationError(c, err.Error())
+	}
+
 	result, err := h.service.List(c.Context(), params)
 	if err != nil {
+		if errors.Is(err, models.ErrInvalidCursor) {
+			return BadRequest(c, "Invalid cursor")
+		}
 		return InternalError(c, "Failed to fetch fees")
 	}
 
@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog/log"
+
+	"github.com/sdyn/backend/internal/middleware"
+	"github.com/sdyn/backend/internal/models"
+	"github.com/sdyn/backend/internal/services"
+)
+
+type ImageHandler struct {
+	service *services.ImageService
+}
+
+func NewImageHandler(service *services.ImageService) *ImageHandler {
+	return &ImageHandler{service: service}
+}
+
+// UploadAvatar replaces the avatar of the member in :id with the image in
+// the multipart "file" field
+func (h *ImageHandler) UploadAvatar(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return BadRequest(c, "Invalid member ID")
+	}
+	return h.uploadAvatar(c, id)
+}
+
+// UploadMyAvatar replaces current user's avatar
+func (h *ImageHandler) UploadMyAvatar(c *fiber.Ctx) error {
+	id, err := uuid.Parse(middleware.GetUserID(c))
+	if err != nil {
+		return BadRequest(c, "Invalid user ID")
+	}
+	return h.uploadAvatar(c, id)
+}
+
+func (h *ImageHandler) uploadAvatar(c *fiber.Ctx, id uuid.UUID) error {
+	return h.upload(c, "Member not found", func(file io.Reader) (*models.ImageUpload, error) {
+		return h.service.UploadAvatar(c.Context(), id, file, middleware.GetUserID(c))
+	})
+}
+
+// DeleteAvatar clears the avatar of the member in :id
+func (h *ImageHandler) DeleteAvatar(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return BadRequest(c, "Invalid member ID")
+	}
+
+	if err := h.service.DeleteAvatar(c.Context(), id, middleware.GetUserID(c)); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return NotFound(c, "Member not found")
+		}
+		return InternalError(c, "Failed to delete avatar")
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// UploadEventCover replaces the cover image of the event in :id
+func (h *ImageHandler) UploadEventCover(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return BadRequest(c, "Invalid event ID")
+	}
+
+	return h.upload(c, "Event not found", func(file io.Reader) (*models.ImageUpload, error) {
+		return h.service.UploadEventCover(c.Context(), id, file)
+	})
+}
+
+// DeleteEventCover clears the cover image of the event in :id
+func (h *ImageHandler) DeleteEventCover(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return BadRequest(c, "Invalid event ID")
+	}
+
+	if err := h.service.DeleteEventCover(c.Context(), id); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return NotFound(c, "Event not found")
+		}
+		return InternalError(c, "Failed to delete cover image")
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// upload opens the multipart "file" field and maps the errors of store
+func (h *ImageHandler) upload(c *fiber.Ctx, notFound string, store func(io.Reader) (*models.ImageUpload, error)) error {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return BadRequest(c, "File is required")
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return BadRequest(c, "Failed to read file")
+	}
+	defer file.Close()
+
+	result, err := store(file)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return NotFound(c, notFound)
+		}
+		var imageErr *services.ImageError
+		if errors.As(err, &imageErr) {
+			return BadRequest(c, imageErr.Message)
+		}
+		log.Error().Err(err).Msg("Failed to upload image")
+		return InternalError(c, "Failed to upload image")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(result)
+}
@@ -1,9 +1,15 @@
 package handlers
 
 import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"time"
+
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 
 	"github.com/sdyn/backend/internal/middleware"
 	"github.com/sdyn/backend/internal/models"
@@ -37,6 +43,10 @@ func (h *MemberHandler) List(c *fiber.Ctx) error {
 		params.Limit = 20
 	}
 
+	if err := h.validate.Struct(params); err != nil {
+		return ValidationError(c, err.Error())
+	}
+
 	// Check organization access for non-national admins
 	if !middleware.HasRole(c, "national_admin") {
 		orgID := middleware.GetOrganizationID(c)
@@ -47,6 +57,9 @@ func (h *MemberHandler) List(c *fiber.Ctx) error {
 
 	result, err := h.service.List(c.Context(), params)
 	if err != nil {
+		if errors.Is(err, models.ErrInvalidCursor) {
+			return BadRequest(c, "Invalid cursor")
+		}
 		return InternalError(c, "Failed to fetch members")
 	}
 
@@ -103,8 +116,12 @@ func (h *MemberHandler) Update(c *fiber.Ctx) error {
 		return ValidationError(c, err.Error())
 	}
 
-	member, err := h.service.Update(c.Context(), id, req)
+	member, err := h.service.Update(c.Context(), id, req, middleware.GetUserID(c))
 	if err != nil {
+		var memberErr *services.MemberError
+		if errors.As(err, &memberErr) {
+			return BadRequest(c, memberErr.Message)
+		}
 		return InternalError(c, "Failed to update member: "+err.Error())
 	}
 
@@ -125,6 +142,176 @@ func (h *MemberHandler) Delete(c *fiber.Ctx) error {
 	return c.SendStatus(fiber.StatusNoContent)
 }
 
+// Import creates members from a CSV or XLSX file uploaded as "file". With
+// dry_run=true the file is only validated and the report returned.
+func (h *MemberHandler) Import(c *fiber.Ctx) error {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return BadRequest(c, "File is required")
+	}
+
+	format := strings.ToLower(strings.TrimPrefix(filepath.Ext(fileHeader.Filename), "."))
+	if format != "csv" && format != "xlsx" {
+		return BadRequest(c, "Only .csv and .xlsx files are supported")
+	}
+
+	provinceID, districtID, organizationID, ok := resolveScope(c)
+	if !ok {
+		return Forbidden(c, "Your data scope does not allow imports")
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return BadRequest(c, "Failed to read file")
+	}
+	defer file.Close()
+
+	rows, err := services.ParseMemberImport(file, format)
+	if err != nil {
+		var importErr *services.ImportError
+		if errors.As(err, &importErr) {
+			return BadRequest(c, importErr.Message)
+		}
+		return BadRequest(c, "Failed to read file")
+	}
+
+	for i := range rows {
+		if err := h.validate.Struct(&rows[i].Request); err != nil {
+			rows[i].Errors = append(rows[i].Errors, services.ImportValidationErrors(rows[i].Row, err)...)
+		}
+	}
+
+	scope := services.ImportScope{
+		ProvinceID:     provinceID,
+		DistrictID:     districtID,
+		OrganizationID: organizationID,
+	}
+	dryRun := c.QueryBool("dry_run")
+
+	result, err := h.service.Import(c.Context(), rows, scope, dryRun)
+	if err != nil {
+		return InternalError(c, "Failed to import members")
+	}
+
+	if !dryRun && len(result.Errors) > 0 {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(result)
+	}
+	if result.Imported > 0 {
+		return c.Status(fiber.StatusCreated).JSON(result)
+	}
+	return c.JSON(result)
+}
+
+// ListDuplicates returns suspected duplicate members within the caller's scope
+func (h *MemberHandler) ListDuplicates(c *fiber.Ctx) error {
+	params := new(models.DuplicateListParams)
+	if err := c.QueryParser(params); err != nil {
+		return BadRequest(c, "Invalid query parameters")
+	}
+
+	provinceID, districtID, organizationID, ok := resolveScope(c)
+	if !ok {
+		return Forbidden(c, "Your data scope does not allow this action")
+	}
+	params.ProvinceID = provinceID
+	params.DistrictID = districtID
+	if organizationID != nil {
+		params.OrganizationID = organizationID
+	}
+
+	duplicates, err := h.service.FindDuplicates(c.Context(), params)
+	if err != nil {
+		return InternalError(c, "Failed to find duplicates")
+	}
+
+	return c.JSON(duplicates)
+}
+
+// ListExpiring returns active members in the caller's scope whose
+// membership expires within ?days= days (default 30)
+func (h *MemberHandler) ListExpiring(c *fiber.Ctx) error {
+	params := new(models.MemberListParams)
+	if err := c.QueryParser(params); err != nil {
+		return BadRequest(c, "Invalid query parameters")
+	}
+
+	if params.Page <= 0 {
+		params.Page = 1
+	}
+	if params.Limit <= 0 || params.Limit > 100 {
+		params.Limit = 20
+	}
+
+	provinceID, districtID, organizationID, ok := resolveScope(c)
+	if !ok {
+		return Forbidden(c, "Your data scope does not allow this action")
+	}
+	if provinceID != nil {
+		params.ProvinceID = provinceID
+	}
+	if districtID != nil {
+		params.DistrictID = districtID
+	}
+	if organizationID != nil {
+		params.OrganizationID = organizationID
+	}
+
+	result, err := h.service.ListExpiring(c.Context(), params, c.QueryInt("days", 30))
+	if err != nil {
+		var memberErr *services.MemberError
+		if errors.As(err, &memberErr) {
+			return BadRequest(c, memberErr.Message)
+		}
+		if errors.Is(err, models.ErrInvalidCursor) {
+			return BadRequest(c, "Invalid cursor")
+		}
+		return InternalError(c, "Failed to fetch expiring members")
+	}
+
+	return c.JSON(result)
+}
+
+// Merge merges the member in duplicate_id into the member in the path
+func (h *MemberHandler) Merge(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return BadRequest(c, "Invalid member ID")
+	}
+
+	req := new(models.MergeMembersRequest)
+	if err := c.BodyParser(req); err != nil {
+		return BadRequest(c, "Invalid request body")
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		return ValidationError(c, err.Error())
+	}
+
+	// The route only checks access to the surviving member
+	duplicateID, _ := uuid.Parse(req.DuplicateID)
+	canAccess, err := middleware.CanAccessResource(c, models.ResourceMember, duplicateID)
+	if err != nil {
+		return InternalError(c, "Failed to check access permissions")
+	}
+	if !canAccess {
+		return Forbidden(c, "You don't have access to the duplicate member")
+	}
+
+	member, err := h.service.Merge(c.Context(), id, req, middleware.GetUserID(c))
+	if err != nil {
+		var memberErr *services.MemberError
+		if errors.As(err, &memberErr) {
+			return BadRequest(c, memberErr.Message)
+		}
+		if errors.Is(err, pgx.ErrNoRows) {
+			return NotFound(c, "Member not found")
+		}
+		return InternalError(c, "Failed to merge members")
+	}
+
+	return c.JSON(member)
+}
+
 // UpdateStatus updates member's status
 func (h *MemberHandler) UpdateStatus(c *fiber.Ctx) error {
 	id, err := uuid.Parse(c.Params("id"))
@@ -144,20 +331,36 @@ func (h *MemberHandler) UpdateStatus(c *fiber.Ctx) error {
 	changedBy := middleware.GetUserID(c)
 	member, err := h.service.UpdateStatus(c.Context(), id, req, changedBy)
 	if err != nil {
+		var transitionErr *services.StatusTransitionError
+		if errors.As(err, &transitionErr) {
+			return Conflict(c, transitionErr.Error())
+		}
+		if errors.Is(err, services.ErrMemberStatusChanged) {
+			return Conflict(c, "Member status was changed by another request")
+		}
+		var memberErr *services.MemberError
+		if errors.As(err, &memberErr) {
+			return BadRequest(c, memberErr.Message)
+		}
+		if errors.Is(err, pgx.ErrNoRows) {
+			return NotFound(c, "Member not found")
+		}
 		return InternalError(c, "Failed to update status")
 	}
 
 	return c.JSON(member)
 }
 
-// GetHistory returns member's change history
+// GetHistory returns member's change history with per-field diffs. National
+// IDs are masked for roles without the member view_sensitive permission.
 func (h *MemberHandler) GetHistory(c *fiber.Ctx) error {
 	id, err := uuid.Parse(c.Params("id"))
 	if err != nil {
 		return BadRequest(c, "Invalid member ID")
 	}
 
-	history, err := h.service.GetHistory(c.Context(), id)
+	showSensitive := middleware.HasPermission(c, models.ResourceMember, models.ActionViewSensitive)
+	history, err := h.service.GetHistory(c.Context(), id, showSensitive)
 	if err != nil {
 		return InternalError(c, "Failed to fetch history")
 	}
@@ -165,6 +368,125 @@ func (h *MemberHandler) GetHistory(c *fiber.Ctx) error {
 	return c.JSON(history)
 }
 
+// GetAsOf returns the member record as it was at the "at" query parameter,
+// either a date (end of that day) or an RFC 3339 timestamp
+func (h *MemberHandler) GetAsOf(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return BadRequest(c, "Invalid member ID")
+	}
+
+	at, ok := parseAsOf(c.Query("at"))
+	if !ok {
+		return BadRequest(c, "at must be a date (YYYY-MM-DD) or an RFC 3339 timestamp")
+	}
+
+	showSensitive := middleware.HasPermission(c, models.ResourceMember, models.ActionViewSensitive)
+	member, err := h.service.GetAsOf(c.Context(), id, at, showSensitive)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) || errors.Is(err, services.ErrMemberNotYetCreated) {
+			return NotFound(c, "Member not found")
+		}
+		return InternalError(c, "Failed to fetch member")
+	}
+
+	return c.JSON(member)
+}
+
+// parseAsOf parses an "as of" time. A bare date means the end of that day.
+func parseAsOf(s string) (time.Time, bool) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, true
+	}
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		return t.Add(24*time.Hour - time.Nanosecond), true
+	}
+	return time.Time{}, false
+}
+
+// GetReferrals returns the tree of members referred by the member, depth
+// levels deep (default 3)
+func (h *MemberHandler) GetReferrals(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return BadRequest(c, "Invalid member ID")
+	}
+
+	tree, err := h.service.GetReferrals(c.Context(), id, c.QueryInt("depth", 0))
+	if err != nil {
+		var memberErr *services.MemberError
+		if errors.As(err, &memberErr) {
+			return BadRequest(c, memberErr.Message)
+		}
+		if errors.Is(err, pgx.ErrNoRows) {
+			return NotFound(c, "Member not found")
+		}
+		return InternalError(c, "Failed to fetch referrals")
+	}
+
+	return c.JSON(tree)
+}
+
+// GetCompleteness returns the member's profile completeness and field
+// verification state
+func (h *MemberHandler) GetCompleteness(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return BadRequest(c, "Invalid member ID")
+	}
+
+	return h.completeness(c, id)
+}
+
+// VerifyField marks a profile field as verified by the admin
+func (h *MemberHandler) VerifyField(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return BadRequest(c, "Invalid member ID")
+	}
+
+	req := new(models.VerifyFieldRequest)
+	if err := c.BodyParser(req); err != nil {
+		return BadRequest(c, "Invalid request body")
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		return ValidationError(c, err.Error())
+	}
+
+	result, err := h.service.VerifyField(c.Context(), id, req, middleware.GetUserID(c))
+	if err != nil {
+		var memberErr *services.MemberError
+		if errors.As(err, &memberErr) {
+			return BadRequest(c, memberErr.Message)
+		}
+		if errors.Is(err, pgx.ErrNoRows) {
+			return NotFound(c, "Member not found")
+		}
+		return InternalError(c, "Failed to verify field")
+	}
+
+	return c.JSON(result)
+}
+
+// UnverifyField withdraws the verification of the field in :field
+func (h *MemberHandler) UnverifyField(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return BadRequest(c, "Invalid member ID")
+	}
+
+	result, err := h.service.UnverifyField(c.Context(), id, c.Params("field"))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return NotFound(c, "Verification not found")
+		}
+		return InternalError(c, "Failed to remove verification")
+	}
+
+	return c.JSON(result)
+}
+
 // GetProfile returns current user's profile
 func (h *MemberHandler) GetProfile(c *fiber.Ctx) error {
 	userID := middleware.GetUserID(c)
@@ -181,6 +503,28 @@ func (h *MemberHandler) GetProfile(c *fiber.Ctx) error {
 	return c.JSON(member)
 }
 
+// GetMyCompleteness returns current user's profile completeness
+func (h *MemberHandler) GetMyCompleteness(c *fiber.Ctx) error {
+	id, err := uuid.Parse(middleware.GetUserID(c))
+	if err != nil {
+		return BadRequest(c, "Invalid user ID")
+	}
+
+	return h.completeness(c, id)
+}
+
+func (h *MemberHandler) completeness(c *fiber.Ctx, id uuid.UUID) error {
+	result, err := h.service.GetCompleteness(c.Context(), id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return NotFound(c, "Member not found")
+		}
+		return InternalError(c, "Failed to fetch profile completeness")
+	}
+
+	return c.JSON(result)
+}
+
 // UpdateProfile updates current user's profile
 func (h *MemberHandler) UpdateProfile(c *fiber.Ctx) error {
 	userID := middleware.GetUserID(c)
@@ -202,7 +546,7 @@ func (h *MemberHandler) UpdateProfile(c *fiber.Ctx) error {
 		Bio:       req.Bio,
 	}
 
-	member, err := h.service.Update(c.Context(), id, allowedReq)
+	member, err := h.service.Update(c.Context(), id, allowedReq, userID)
 	if err != nil {
 		return InternalError(c, "Failed to update profile")
 	}
@@ -210,6 +554,73 @@ func (h *MemberHandler) UpdateProfile(c *fiber.Ctx) error {
 	return c.JSON(member)
 }
 
+// ReferralReport returns the referral leaderboard within the caller's data
+// scope
+func (h *MemberHandler) ReferralReport(c *fiber.Ctx) error {
+	params := new(models.ReferralLeaderboardParams)
+	if err := c.QueryParser(params); err != nil {
+		return BadRequest(c, "Invalid query parameters")
+	}
+
+	if err := h.validate.Struct(params); err != nil {
+		return ValidationError(c, err.Error())
+	}
+
+	provinceID, districtID, organizationID, ok := resolveScope(c)
+	if !ok {
+		return Forbidden(c, "Your data scope does not allow this report")
+	}
+	params.ProvinceID = provinceID
+	params.DistrictID = districtID
+	if organizationID != nil {
+		params.OrganizationID = organizationID
+	}
+
+	report, err := h.service.ReferralLeaderboard(c.Context(), params)
+	if err != nil {
+		return InternalError(c, "Failed to generate report")
+	}
+
+	return c.JSON(report)
+}
+
+// IncompleteProfilesReport lists members with incomplete profiles within the
+// caller's data scope, with a summary per organization
+func (h *MemberHandler) IncompleteProfilesReport(c *fiber.Ctx) error {
+	params := new(models.IncompleteProfileParams)
+	if err := c.QueryParser(params); err != nil {
+		return BadRequest(c, "Invalid query parameters")
+	}
+
+	if err := h.validate.Struct(params); err != nil {
+		return ValidationError(c, err.Error())
+	}
+
+	if params.Page <= 0 {
+		params.Page = 1
+	}
+	if params.Limit <= 0 || params.Limit > 100 {
+		params.Limit = 20
+	}
+
+	provinceID, districtID, organizationID, ok := resolveScope(c)
+	if !ok {
+		return Forbidden(c, "Your data scope does not allow this report")
+	}
+	params.ProvinceID = provinceID
+	params.DistrictID = districtID
+	if organizationID != nil {
+		params.OrganizationID = organizationID
+	}
+
+	report, err := h.service.IncompleteProfiles(c.Context(), params)
+	if err != nil {
+		return InternalError(c, "Failed to generate report")
+	}
+
+	return c.JSON(report)
+}
+
 // Report generates member statistics report
 func (h *MemberHandler) Report(c *fiber.Ctx) error {
 	// Get organization ID from query or user context
@@ -271,3 +271,27 @@ func TestMemberHandler_UpdateStatus(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, 400, resp.StatusCode)
 }
+
+func TestMemberHandler_ListRejectsUnknownSearchMode(t *testing.T) {
+	app := setupTestApp()
+	h := NewMemberHandler(nil)
+	app.Get("/api/v1/members", h.List)
+
+	req := httptest.NewRequest("GET", "/api/v1/members?search=bat&search_mode=soundex", nil)
+	resp, err := app.Test(req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 422, resp.StatusCode)
+}
+
+func TestMemberHandler_ReferralReportRejectsInvalidDate(t *testing.T) {
+	app := setupTestApp()
+	h := NewMemberHandler(nil)
+	app.Get("/api/v1/reports/referrals", h.ReferralReport)
+
+	req := httptest.NewRequest("GET", "/api/v1/reports/referrals?from=2026-13-01", nil)
+	resp, err := app.Test(req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 422, resp.StatusCode)
+}
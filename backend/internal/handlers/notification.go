@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/sdyn/backend/internal/middleware"
+	"github.com/sdyn/backend/internal/services"
+)
+
+type NotificationHandler struct {
+	service *services.NotificationService
+}
+
+func NewNotificationHandler(service *services.NotificationService) *NotificationHandler {
+	return &NotificationHandler{service: service}
+}
+
+// ListMine returns current user's notifications, newest first.
+// ?unread=true returns only unread ones.
+func (h *NotificationHandler) ListMine(c *fiber.Ctx) error {
+	memberID, err := uuid.Parse(middleware.GetUserID(c))
+	if err != nil {
+		return BadRequest(c, "Invalid user ID")
+	}
+
+	notifications, err := h.service.List(c.Context(), memberID, c.QueryBool("unread"))
+	if err != nil {
+		return InternalError(c, "Failed to fetch notifications")
+	}
+
+	return c.JSON(notifications)
+}
+
+// MarkRead marks one of current user's notifications read
+func (h *NotificationHandler) MarkRead(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return BadRequest(c, "Invalid notification ID")
+	}
+	memberID, err := uuid.Parse(middleware.GetUserID(c))
+	if err != nil {
+		return BadRequest(c, "Invalid user ID")
+	}
+
+	if err := h.service.MarkRead(c.Context(), id, memberID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return NotFound(c, "Notification not found")
+		}
+		return InternalError(c, "Failed to update notification")
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/sdyn/backend/internal/middleware"
+	"github.com/sdyn/backend/internal/models"
+	"github.com/sdyn/backend/internal/services"
+)
+
+type ParticipationHandler struct {
+	service  *services.ParticipationService
+	validate *validator.Validate
+}
+
+func NewParticipationHandler(service *services.ParticipationService) *ParticipationHandler {
+	return &ParticipationHandler{
+		service:  service,
+		validate: validator.New(),
+	}
+}
+
+// SetHours credits hours entered by an organizer to a participant of the
+// event in :id
+func (h *ParticipationHandler) SetHours(c *fiber.Ctx) error {
+	eventID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return BadRequest(c, "Invalid event ID")
+	}
+
+	memberID, err := uuid.Parse(c.Params("memberId"))
+	if err != nil {
+		return BadRequest(c, "Invalid member ID")
+	}
+
+	req := new(models.SetParticipantHoursRequest)
+	if err := c.BodyParser(req); err != nil {
+		return BadRequest(c, "Invalid request body")
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		return ValidationError(c, err.Error())
+	}
+
+	participant, err := h.service.SetHours(c.Context(), eventID, memberID, req, middleware.GetUserID(c))
+	if err != nil {
+		var eventErr *services.EventError
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			return NotFound(c, "Event not found")
+		case errors.Is(err, services.ErrNotAttended):
+			return BadRequest(c, "Hours can only be credited to attended participants")
+		case errors.As(err, &eventErr):
+			return BadRequest(c, eventErr.Message)
+		}
+		return InternalError(c, "Failed to set hours")
+	}
+
+	return c.JSON(participant)
+}
+
+// GetMine returns current user's participation summary
+func (h *ParticipationHandler) GetMine(c *fiber.Ctx) error {
+	id, err := uuid.Parse(middleware.GetUserID(c))
+	if err != nil {
+		return BadRequest(c, "Invalid user ID")
+	}
+
+	return h.summary(c, id)
+}
+
+// GetMember returns the participation summary of the member in :id
+func (h *ParticipationHandler) GetMember(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return BadRequest(c, "Invalid member ID")
+	}
+
+	return h.summary(c, id)
+}
+
+func (h *ParticipationHandler) summary(c *fiber.Ctx, id uuid.UUID) error {
+	summary, err := h.service.MemberSummary(c.Context(), id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return NotFound(c, "Member not found")
+		}
+		return InternalError(c, "Failed to fetch participation")
+	}
+
+	return c.JSON(summary)
+}
+
+// Leaderboard returns the participation leaderboard within the caller's
+// data scope
+func (h *ParticipationHandler) Leaderboard(c *fiber.Ctx) error {
+	params := new(models.ParticipationLeaderboardParams)
+	if err := c.QueryParser(params); err != nil {
+		return BadRequest(c, "Invalid query parameters")
+	}
+
+	if err := h.validate.Struct(params); err != nil {
+		return ValidationError(c, err.Error())
+	}
+
+	provinceID, districtID, organizationID, ok := resolveScope(c)
+	if !ok {
+		return Forbidden(c, "Your data scope does not allow this report")
+	}
+	params.ProvinceID = provinceID
+	params.DistrictID = districtID
+	if organizationID != nil {
+		params.OrganizationID = organizationID
+	}
+
+	report, err := h.service.Leaderboard(c.Context(), params)
+	if err != nil {
+		return InternalError(c, "Failed to generate report")
+	}
+
+	return c.JSON(report)
+}
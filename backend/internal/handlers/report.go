@@ -1,18 +1,27 @@
 package handlers
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog/log"
 )
 
 type ReportHandler struct {
-	db *pgxpool.Pool
+	db    *pgxpool.Pool
+	redis *redis.Client
 }
 
-func NewReportHandler(db *pgxpool.Pool) *ReportHandler {
-	return &ReportHandler{db: db}
+func NewReportHandler(db *pgxpool.Pool, redis *redis.Client) *ReportHandler {
+	return &ReportHandler{
+		db:    db,
+		redis: redis,
+	}
 }
 
 type DashboardStats struct {
@@ -49,10 +58,88 @@ type GrowthStats struct {
 	TotalMembers int    `json:"total_members"`
 }
 
-// DashboardReport returns dashboard statistics
+// dashboardCacheTTL is how long dashboard stats are cached per data scope
+const dashboardCacheTTL = 2 * time.Minute
+
+// reportScope restricts report queries to the requester's data scope. column
+// is province_id, district_id or organization_id, or empty for national scope.
+type reportScope struct {
+	column string
+	value  string
+}
+
+// condition returns an " AND ..." clause restricting rows of a table with
+// province_id, district_id and organization_id columns, using placeholder $arg
+func (s reportScope) condition(alias string, arg int) string {
+	if s.column == "" {
+		return ""
+	}
+	return fmt.Sprintf(" AND %s.%s = $%d", alias, s.column, arg)
+}
+
+// orgCondition is like condition, for the organizations table itself
+func (s reportScope) orgCondition(alias string, arg int) string {
+	if s.column == "organization_id" {
+		return fmt.Sprintf(" AND %s.id = $%d", alias, arg)
+	}
+	return s.condition(alias, arg)
+}
+
+// args appends the scope value to args if the scope filters anything
+func (s reportScope) args(args ...interface{}) []interface{} {
+	if s.column == "" {
+		return args
+	}
+	return append(args, s.value)
+}
+
+func (s reportScope) cacheKey() string {
+	if s.column == "" {
+		return "report:dashboard:all"
+	}
+	return "report:dashboard:" + s.column + ":" + s.value
+}
+
+// DashboardReport returns dashboard statistics for the requester's data scope
 func (h *ReportHandler) DashboardReport(c *fiber.Ctx) error {
+	provinceID, districtID, organizationID, ok := resolveScope(c)
+	if !ok {
+		return Forbidden(c, "Your data scope does not allow reports")
+	}
+
+	var scope reportScope
+	switch {
+	case provinceID != nil:
+		scope = reportScope{column: "province_id", value: *provinceID}
+	case districtID != nil:
+		scope = reportScope{column: "district_id", value: *districtID}
+	case organizationID != nil:
+		scope = reportScope{column: "organization_id", value: *organizationID}
+	}
+
 	ctx := c.Context()
+	key := scope.cacheKey()
+
+	if cached, err := h.redis.Get(ctx, key).Bytes(); err == nil {
+		c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+		return c.Send(cached)
+	}
+
+	stats := h.dashboardStats(ctx, scope)
+
+	data, err := json.Marshal(stats)
+	if err != nil {
+		return InternalError(c, "Failed to generate report")
+	}
+	if err := h.redis.Set(ctx, key, data, dashboardCacheTTL).Err(); err != nil {
+		log.Warn().Err(err).Msg("Failed to cache dashboard report")
+	}
+
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	return c.Send(data)
+}
 
+func (h *ReportHandler) dashboardStats(ctx context.Context, scope reportScope) DashboardStats {
 	stats := DashboardStats{
 		MembersByProvince: []ProvinceStats{},
 		RecentActivities:  []RecentActivity{},
@@ -65,50 +152,62 @@ func (h *ReportHandler) DashboardReport(c *fiber.Ctx) error {
 			COUNT(*) as total,
 			COUNT(*) FILTER (WHERE status = 'active') as active,
 			COUNT(*) FILTER (WHERE status = 'pending') as pending
-		FROM members
-	`
-	h.db.QueryRow(ctx, memberQuery).Scan(&stats.TotalMembers, &stats.ActiveMembers, &stats.PendingMembers)
+		FROM members m
+		WHERE 1=1` + scope.condition("m", 1)
+	h.db.QueryRow(ctx, memberQuery, scope.args()...).Scan(&stats.TotalMembers, &stats.ActiveMembers, &stats.PendingMembers)
 
 	// Get organization count
-	h.db.QueryRow(ctx, "SELECT COUNT(*) FROM organizations WHERE is_active = true").Scan(&stats.TotalOrganizations)
+	orgQuery := `SELECT COUNT(*) FROM organizations o WHERE o.is_active = true` + scope.orgCondition("o", 1)
+	h.db.QueryRow(ctx, orgQuery, scope.args()...).Scan(&stats.TotalOrganizations)
 
 	// Get event counts
 	eventQuery := `
 		SELECT
 			COUNT(*) as total,
-			COUNT(*) FILTER (WHERE start_date > NOW() AND status != 'cancelled') as upcoming
-		FROM events
-	`
-	h.db.QueryRow(ctx, eventQuery).Scan(&stats.TotalEvents, &stats.UpcomingEvents)
+			COUNT(*) FILTER (WHERE e.start_date > NOW() AND e.status != 'cancelled') as upcoming
+		FROM events e
+		LEFT JOIN organizations o ON e.organization_id = o.id
+		WHERE 1=1` + scope.orgCondition("o", 1)
+	h.db.QueryRow(ctx, eventQuery, scope.args()...).Scan(&stats.TotalEvents, &stats.UpcomingEvents)
 
 	// Calculate fee collection rate
 	currentYear := time.Now().Year()
 	feeQuery := `
 		SELECT
-			COALESCE(SUM(amount) FILTER (WHERE status = 'paid'), 0) as collected,
-			COALESCE(SUM(amount), 0) as total
-		FROM membership_fees
-		WHERE year = $1
-	`
+			COALESCE(SUM(f.amount) FILTER (WHERE f.status = 'paid'), 0) as collected,
+			COALESCE(SUM(f.amount), 0) as total
+		FROM membership_fees f
+		JOIN members m ON f.member_id = m.id
+		WHERE f.year = $1` + scope.condition("m", 2)
 	var collected, totalFees float64
-	h.db.QueryRow(ctx, feeQuery, currentYear).Scan(&collected, &totalFees)
+	h.db.QueryRow(ctx, feeQuery, scope.args(currentYear)...).Scan(&collected, &totalFees)
 	stats.TotalFeesCollected = collected
 	if totalFees > 0 {
 		stats.FeeCollectionRate = (collected / totalFees) * 100
 	}
 
-	// Get members by province
+	// Get members by province. A province admin only sees their province;
+	// narrower scopes only see provinces they have members in.
+	provinceWhere, provinceHaving := "", ""
+	switch scope.column {
+	case "":
+	case "province_id":
+		provinceWhere = " WHERE p.id = $1"
+	default:
+		provinceHaving = " HAVING COUNT(m.id) > 0"
+	}
 	provinceQuery := `
 		SELECT p.id, p.name,
 			   COUNT(m.id) as total,
 			   COUNT(m.id) FILTER (WHERE m.status = 'active') as active
 		FROM provinces p
-		LEFT JOIN members m ON m.province_id = p.id
-		GROUP BY p.id, p.name
+		LEFT JOIN members m ON m.province_id = p.id` + scope.condition("m", 1) +
+		provinceWhere + `
+		GROUP BY p.id, p.name` + provinceHaving + `
 		ORDER BY total DESC
 		LIMIT 10
 	`
-	rows, err := h.db.Query(ctx, provinceQuery)
+	rows, err := h.db.Query(ctx, provinceQuery, scope.args()...)
 	if err == nil {
 		defer rows.Close()
 		for rows.Next() {
@@ -127,10 +226,11 @@ func (h *ReportHandler) DashboardReport(c *fiber.Ctx) error {
 			   COALESCE(m.first_name || ' ' || m.last_name, 'System') as actor
 		FROM member_history mh
 		LEFT JOIN members m ON mh.member_id = m.id
+		WHERE 1=1` + scope.condition("m", 1) + `
 		ORDER BY mh.created_at DESC
 		LIMIT 10
 	`
-	actRows, err := h.db.Query(ctx, activityQuery)
+	actRows, err := h.db.Query(ctx, activityQuery, scope.args()...)
 	if err == nil {
 		defer actRows.Close()
 		for actRows.Next() {
@@ -157,11 +257,11 @@ func (h *ReportHandler) DashboardReport(c *fiber.Ctx) error {
 			COALESCE(COUNT(mem.id) FILTER (WHERE date_trunc('month', mem.created_at) = m.month), 0) as new_members,
 			COALESCE(COUNT(mem.id) FILTER (WHERE mem.created_at <= (m.month + interval '1 month' - interval '1 day')), 0) as total_members
 		FROM months m
-		LEFT JOIN members mem ON mem.created_at <= (m.month + interval '1 month' - interval '1 day')
+		LEFT JOIN members mem ON mem.created_at <= (m.month + interval '1 month' - interval '1 day')` + scope.condition("mem", 1) + `
 		GROUP BY m.month
 		ORDER BY m.month
 	`
-	growthRows, err := h.db.Query(ctx, growthQuery)
+	growthRows, err := h.db.Query(ctx, growthQuery, scope.args()...)
 	if err == nil {
 		defer growthRows.Close()
 		for growthRows.Next() {
@@ -172,54 +272,5 @@ func (h *ReportHandler) DashboardReport(c *fiber.Ctx) error {
 		}
 	}
 
-	return c.JSON(stats)
-}
-
-// Legacy function for backward compatibility
-func DashboardReport(c *fiber.Ctx) error {
-	stats := DashboardStats{
-		TotalMembers:       0,
-		ActiveMembers:      0,
-		PendingMembers:     0,
-		TotalOrganizations: 0,
-		TotalEvents:        0,
-		UpcomingEvents:     0,
-		FeeCollectionRate:  0.0,
-		TotalFeesCollected: 0.0,
-		MembersByProvince:  []ProvinceStats{},
-		RecentActivities:   []RecentActivity{},
-		MemberGrowth:       []GrowthStats{},
-	}
-
-	return c.JSON(stats)
-}
-
-// ExportReport handles export requests for various report types
-func ExportReport(c *fiber.Ctx) error {
-	reportType := c.Params("type")
-
-	// Validate report type
-	validTypes := map[string]bool{
-		"members":       true,
-		"fees":          true,
-		"events":        true,
-		"organizations": true,
-	}
-
-	if !validTypes[reportType] {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error":   "Bad Request",
-			"message": "Invalid report type. Valid types: members, fees, events, organizations",
-		})
-	}
-
-	// For now, return a placeholder response
-	// In production, this would generate CSV/Excel files
-	return c.JSON(fiber.Map{
-		"message": "Export request received",
-		"type":    reportType,
-		"format":  c.Query("format", "csv"),
-		"status":  "processing",
-		"note":    "Export functionality will be implemented with file generation",
-	})
+	return stats
 }
@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReportScope_National(t *testing.T) {
+	var scope reportScope
+
+	assert.Equal(t, "", scope.condition("m", 1))
+	assert.Equal(t, "", scope.orgCondition("o", 1))
+	assert.Equal(t, []interface{}{2024}, scope.args(2024))
+	assert.Equal(t, "report:dashboard:all", scope.cacheKey())
+}
+
+func TestReportScope_District(t *testing.T) {
+	scope := reportScope{column: "district_id", value: "d-1"}
+
+	assert.Equal(t, " AND m.district_id = $2", scope.condition("m", 2))
+	assert.Equal(t, " AND o.district_id = $1", scope.orgCondition("o", 1))
+	assert.Equal(t, []interface{}{2024, "d-1"}, scope.args(2024))
+	assert.Equal(t, "report:dashboard:district_id:d-1", scope.cacheKey())
+}
+
+func TestReportScope_Organization(t *testing.T) {
+	scope := reportScope{column: "organization_id", value: "org-1"}
+
+	assert.Equal(t, " AND m.organization_id = $1", scope.condition("m", 1))
+	assert.Equal(t, " AND o.id = $1", scope.orgCondition("o", 1))
+}
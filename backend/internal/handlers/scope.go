@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/sdyn/backend/internal/middleware"
+	"github.com/sdyn/backend/internal/models"
+)
+
+// resolveScope translates the caller's data scope into province, district and
+// organization filters. ok is false if the scope cannot be expressed at that
+// granularity (own scope, or an admin without an organization), in which case
+// the request should be rejected rather than run unfiltered.
+func resolveScope(c *fiber.Ctx) (provinceID, districtID, organizationID *string, ok bool) {
+	filter := middleware.GetDataScope(c)
+	if filter == nil {
+		return nil, nil, nil, false
+	}
+
+	switch filter.Scope {
+	case models.ScopeAll:
+		return nil, nil, nil, true
+	case models.ScopeProvince:
+		if filter.ProvinceID != nil {
+			id := filter.ProvinceID.String()
+			return &id, nil, nil, true
+		}
+	case models.ScopeDistrict:
+		if filter.DistrictID != nil {
+			id := filter.DistrictID.String()
+			return nil, &id, nil, true
+		}
+		if filter.OrganizationID != nil {
+			id := filter.OrganizationID.String()
+			return nil, nil, &id, true
+		}
+	}
+
+	return nil, nil, nil, false
+}
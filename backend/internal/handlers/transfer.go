@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/sdyn/backend/internal/middleware"
+	"github.com/sdyn/backend/internal/models"
+	"github.com/sdyn/backend/internal/services"
+)
+
+type TransferHandler struct {
+	service  *services.TransferService
+	validate *validator.Validate
+}
+
+func NewTransferHandler(service *services.TransferService) *TransferHandler {
+	return &TransferHandler{
+		service:  service,
+		validate: validator.New(),
+	}
+}
+
+// List returns the transfers into and out of the organization in :id.
+// Pending transfers are returned unless status is given.
+func (h *TransferHandler) List(c *fiber.Ctx) error {
+	orgID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return BadRequest(c, "Invalid organization ID")
+	}
+
+	params := new(models.TransferListParams)
+	if err := c.QueryParser(params); err != nil {
+		return BadRequest(c, "Invalid query parameters")
+	}
+
+	if err := h.validate.Struct(params); err != nil {
+		return ValidationError(c, err.Error())
+	}
+
+	if params.Page <= 0 {
+		params.Page = 1
+	}
+	if params.Limit <= 0 || params.Limit > 100 {
+		params.Limit = 20
+	}
+	params.OrganizationID = orgID
+
+	result, err := h.service.List(c.Context(), params)
+	if err != nil {
+		return InternalError(c, "Failed to fetch transfers")
+	}
+
+	return c.JSON(result)
+}
+
+// Create requests a transfer of the member in :id to another organization
+func (h *TransferHandler) Create(c *fiber.Ctx) error {
+	memberID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return BadRequest(c, "Invalid member ID")
+	}
+
+	req := new(models.CreateTransferRequest)
+	if err := c.BodyParser(req); err != nil {
+		return BadRequest(c, "Invalid request body")
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		return ValidationError(c, err.Error())
+	}
+
+	transfer, err := h.service.Create(c.Context(), memberID, req, middleware.GetDataScope(c), middleware.GetUserID(c))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return NotFound(c, "Member not found")
+		}
+		return h.transferError(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(transfer)
+}
+
+// Accept moves the member to the receiving organization
+func (h *TransferHandler) Accept(c *fiber.Ctx) error {
+	return h.decide(c, h.service.Accept)
+}
+
+// Decline rejects the transfer
+func (h *TransferHandler) Decline(c *fiber.Ctx) error {
+	return h.decide(c, h.service.Decline)
+}
+
+// Cancel withdraws the transfer
+func (h *TransferHandler) Cancel(c *fiber.Ctx) error {
+	return h.decide(c, h.service.Cancel)
+}
+
+type transferAction func(ctx context.Context, id uuid.UUID, req *models.TransferDecisionRequest, filter *services.DataScopeFilter, changedBy string) (*models.MemberTransfer, error)
+
+func (h *TransferHandler) decide(c *fiber.Ctx, action transferAction) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return BadRequest(c, "Invalid transfer ID")
+	}
+
+	req := new(models.TransferDecisionRequest)
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(req); err != nil {
+			return BadRequest(c, "Invalid request body")
+		}
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		return ValidationError(c, err.Error())
+	}
+
+	transfer, err := action(c.Context(), id, req, middleware.GetDataScope(c), middleware.GetUserID(c))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return NotFound(c, "Transfer not found")
+		}
+		return h.transferError(c, err)
+	}
+
+	return c.JSON(transfer)
+}
+
+func (h *TransferHandler) transferError(c *fiber.Ctx, err error) error {
+	var transferErr *services.TransferError
+	if errors.As(err, &transferErr) {
+		if transferErr.Forbidden {
+			return Forbidden(c, transferErr.Message)
+		}
+		return BadRequest(c, transferErr.Message)
+	}
+	if errors.Is(err, services.ErrTransferClosed) {
+		return Conflict(c, "Transfer is no longer pending")
+	}
+	if errors.Is(err, services.ErrTransferExists) {
+		return Conflict(c, "Member already has a pending transfer")
+	}
+	return InternalError(c, "Failed to update transfer")
+}
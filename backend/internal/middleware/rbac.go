@@ -100,6 +100,24 @@ func RequireResourceAccess(resource models.Resource) fiber.Handler {
 	}
 }
 
+// HasPermission reports whether the user's roles grant action on resource,
+// for handlers that vary their response by permission
+func HasPermission(c *fiber.Ctx, resource models.Resource, action models.Action) bool {
+	if authzService == nil {
+		return false
+	}
+	return authzService.CanAccess(GetUserRoles(c), resource, action)
+}
+
+// CanAccessResource reports whether the user may access a resource instance
+// that is not the :id route param, e.g. the second member of a merge
+func CanAccessResource(c *fiber.Ctx, resource models.Resource, resourceID uuid.UUID) (bool, error) {
+	if authzService == nil {
+		return false, nil
+	}
+	return authzService.CanAccessResource(c.Context(), c, resource, resourceID)
+}
+
 // DataScope middleware adds data scoping filter to context
 func DataScope() fiber.Handler {
 	return func(c *fiber.Ctx) error {
@@ -0,0 +1,78 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ApprovalStatus string
+
+const (
+	ApprovalStatusPending  ApprovalStatus = "pending"
+	ApprovalStatusApproved ApprovalStatus = "approved"
+	ApprovalStatusRejected ApprovalStatus = "rejected"
+)
+
+// ApprovalLevel is the admin level responsible for deciding an application
+type ApprovalLevel string
+
+const (
+	ApprovalLevelDistrict ApprovalLevel = "district"
+	ApprovalLevelProvince ApprovalLevel = "province"
+	ApprovalLevelNational ApprovalLevel = "national"
+)
+
+// MembershipApplication is a pending member waiting for an admin decision
+type MembershipApplication struct {
+	ID               uuid.UUID      `json:"id" db:"id"`
+	MemberID         uuid.UUID      `json:"member_id" db:"member_id"`
+	Status           ApprovalStatus `json:"status" db:"status"`
+	Level            ApprovalLevel  `json:"level" db:"level"`
+	ProvinceID       *uuid.UUID     `json:"province_id,omitempty" db:"province_id"`
+	DistrictID       *uuid.UUID     `json:"district_id,omitempty" db:"district_id"`
+	SubmittedAt      time.Time      `json:"submitted_at" db:"submitted_at"`
+	DueAt            time.Time      `json:"due_at" db:"due_at"`
+	EscalatedAt      *time.Time     `json:"escalated_at,omitempty" db:"escalated_at"`
+	EscalatedBy      *uuid.UUID     `json:"escalated_by,omitempty" db:"escalated_by"`
+	EscalationReason *string        `json:"escalation_reason,omitempty" db:"escalation_reason"`
+	DecidedAt        *time.Time     `json:"decided_at,omitempty" db:"decided_at"`
+	DecidedBy        *uuid.UUID     `json:"decided_by,omitempty" db:"decided_by"`
+	DecisionReason   *string        `json:"decision_reason,omitempty" db:"decision_reason"`
+	CreatedAt        time.Time      `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time      `json:"updated_at" db:"updated_at"`
+
+	// Joined fields
+	MemberCode           string       `json:"member_code" db:"member_code"`
+	MemberName           string       `json:"member_name" db:"member_name"`
+	MemberStatus         MemberStatus `json:"member_status" db:"member_status"`
+	MemberOrganizationID *uuid.UUID   `json:"member_organization_id,omitempty" db:"member_organization_id"`
+	ProvinceName         *string      `json:"province_name,omitempty" db:"province_name"`
+	DistrictName         *string      `json:"district_name,omitempty" db:"district_name"`
+	IsOverdue            bool         `json:"is_overdue" db:"is_overdue"`
+}
+
+type ApprovalListParams struct {
+	Page           int             `query:"page"`
+	Limit          int             `query:"limit"`
+	Status         *ApprovalStatus `query:"status"`
+	Level          *ApprovalLevel  `query:"level"`
+	Overdue        bool            `query:"overdue"`
+	OrganizationID *string         `query:"organization_id"`
+
+	// Data scope (set by the server)
+	ProvinceID *string `query:"-"`
+	DistrictID *string `query:"-"`
+}
+
+type ApprovalListResponse struct {
+	Applications []MembershipApplication `json:"applications"`
+	Total        int                     `json:"total"`
+	Page         int                     `json:"page"`
+	Limit        int                     `json:"limit"`
+	TotalPages   int                     `json:"total_pages"`
+}
+
+type ApprovalDecisionRequest struct {
+	Reason *string `json:"reason,omitempty" validate:"omitempty,max=1000"`
+}
@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CardVerification is the public result of checking a membership card QR
+// code. Member details are only set for a valid token.
+type CardVerification struct {
+	Valid  bool `json:"valid"`
+	Active bool `json:"active"`
+
+	ID                  *uuid.UUID    `json:"id,omitempty"`
+	MemberID            *string       `json:"member_id,omitempty"`
+	FirstName           *string       `json:"first_name,omitempty"`
+	LastName            *string       `json:"last_name,omitempty"`
+	OrganizationName    *string       `json:"organization_name,omitempty"`
+	Status              *MemberStatus `json:"status,omitempty"`
+	MembershipExpiresAt *time.Time    `json:"membership_expires_at,omitempty"`
+	IssuedAt            *time.Time    `json:"issued_at,omitempty"`
+}
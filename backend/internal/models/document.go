@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type DocumentType string
+
+const (
+	DocumentTypeIDScan          DocumentType = "id_scan"
+	DocumentTypeApplicationForm DocumentType = "application_form"
+	DocumentTypeSignedPledge    DocumentType = "signed_pledge"
+	DocumentTypeOther           DocumentType = "other"
+)
+
+// MemberDocument is the metadata of a file uploaded against a member. The
+// file itself lives in MinIO under ObjectKey.
+type MemberDocument struct {
+	ID          uuid.UUID    `json:"id"`
+	MemberID    uuid.UUID    `json:"member_id"`
+	Type        DocumentType `json:"type"`
+	FileName    string       `json:"file_name"`
+	ContentType string       `json:"content_type"`
+	Size        int64        `json:"size"`
+	SHA256      string       `json:"sha256"`
+	ObjectKey   string       `json:"-"`
+	Description *string      `json:"description,omitempty"`
+	UploadedBy  *uuid.UUID   `json:"uploaded_by,omitempty"`
+	CreatedAt   time.Time    `json:"created_at"`
+
+	// Set when a single document is fetched
+	DownloadURL       *string    `json:"download_url,omitempty"`
+	DownloadExpiresAt *time.Time `json:"download_expires_at,omitempty"`
+}
+
+// UploadDocumentRequest holds the form fields sent with a document file
+type UploadDocumentRequest struct {
+	Type        DocumentType `form:"type" validate:"required,oneof=id_scan application_form signed_pledge other"`
+	Description *string      `form:"description" validate:"omitempty,max=1000"`
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
@@ -28,6 +29,10 @@ const (
 	EventStatusCancelled EventStatus = "cancelled"
 )
 
+// ErrEventClosed is returned when registering for a cancelled or completed
+// event
+var ErrEventClosed = errors.New("event is closed")
+
 type Event struct {
 	ID                   uuid.UUID   `json:"id" db:"id"`
 	OrganizationID       *uuid.UUID  `json:"organization_id,omitempty" db:"organization_id"`
@@ -50,27 +55,51 @@ type Event struct {
 	UpdatedAt            time.Time   `json:"updated_at" db:"updated_at"`
 
 	// Joined
-	OrganizationName *string `json:"organization_name,omitempty" db:"organization_name"`
-	OrganizerName    *string `json:"organizer_name,omitempty" db:"organizer_name"`
+	OrganizationName *string    `json:"organization_name,omitempty" db:"organization_name"`
+	OrganizerName    *string    `json:"organizer_name,omitempty" db:"organizer_name"`
+	SeriesID         *uuid.UUID `json:"series_id,omitempty" db:"series_id"`
 
 	// Stats
 	TotalRegistered int `json:"total_registered,omitempty" db:"total_registered"`
 	TotalAttended   int `json:"total_attended,omitempty" db:"total_attended"`
+	TotalWaitlisted int `json:"total_waitlisted,omitempty" db:"total_waitlisted"`
 }
 
+type ParticipantStatus string
+
+const (
+	ParticipantStatusRegistered ParticipantStatus = "registered"
+	ParticipantStatusWaitlisted ParticipantStatus = "waitlisted"
+	ParticipantStatusCancelled  ParticipantStatus = "cancelled"
+	ParticipantStatusAttended   ParticipantStatus = "attended"
+	ParticipantStatusNoShow     ParticipantStatus = "no_show"
+)
+
 type EventParticipant struct {
-	ID           uuid.UUID  `json:"id" db:"id"`
-	EventID      uuid.UUID  `json:"event_id" db:"event_id"`
-	MemberID     uuid.UUID  `json:"member_id" db:"member_id"`
-	RegisteredAt time.Time  `json:"registered_at" db:"registered_at"`
-	Attended     bool       `json:"attended" db:"attended"`
-	AttendedAt   *time.Time `json:"attended_at,omitempty" db:"attended_at"`
-	Notes        *string    `json:"notes,omitempty" db:"notes"`
+	ID           uuid.UUID         `json:"id" db:"id"`
+	EventID      uuid.UUID         `json:"event_id" db:"event_id"`
+	MemberID     uuid.UUID         `json:"member_id" db:"member_id"`
+	Status       ParticipantStatus `json:"status" db:"status"`
+	RegisteredAt time.Time         `json:"registered_at" db:"registered_at"`
+	// Attended mirrors Status == ParticipantStatusAttended
+	Attended           bool       `json:"attended" db:"attended"`
+	AttendedAt         *time.Time `json:"attended_at,omitempty" db:"attended_at"`
+	Notes              *string    `json:"notes,omitempty" db:"notes"`
+	CancelledAt        *time.Time `json:"cancelled_at,omitempty" db:"cancelled_at"`
+	CancelledBy        *uuid.UUID `json:"cancelled_by,omitempty" db:"cancelled_by"`
+	CancellationReason *string    `json:"cancellation_reason,omitempty" db:"cancellation_reason"`
+	// Hours credited for a volunteer or campaign event
+	CheckedOutAt   *time.Time `json:"checked_out_at,omitempty" db:"checked_out_at"`
+	Hours          *float64   `json:"hours,omitempty" db:"hours"`
+	HoursEnteredBy *uuid.UUID `json:"hours_entered_by,omitempty" db:"hours_entered_by"`
 
 	// Joined
 	MemberName  string  `json:"member_name,omitempty" db:"member_name"`
 	MemberEmail *string `json:"member_email,omitempty" db:"member_email"`
 	MemberPhone *string `json:"member_phone,omitempty" db:"member_phone"`
+
+	// WaitlistPosition is the 1-based place in line of a waitlisted participant
+	WaitlistPosition *int `json:"waitlist_position,omitempty" db:"waitlist_position"`
 }
 
 type CreateEventRequest struct {
@@ -117,9 +146,64 @@ type EventListParams struct {
 	StartDateFrom  *string      `query:"start_date_from"`
 	StartDateTo    *string      `query:"start_date_to"`
 	IsPublic       *bool        `query:"is_public"`
+	ProvinceID     *string      `query:"province_id"`
+	DistrictID     *string      `query:"district_id"`
+	SortBy         string       `query:"sort_by" validate:"omitempty,oneof=start_date created_at title"`
+	SortOrder      string       `query:"sort_order" validate:"omitempty,oneof=asc desc"`
+	Cursor         string       `query:"cursor"`
+	Total          TotalMode    `query:"total" validate:"omitempty,oneof=exact approx none"`
+}
+
+type EventListResponse struct {
+	Events           []Event `json:"events"`
+	Total            *int    `json:"total,omitempty"`
+	TotalApproximate bool    `json:"total_approximate,omitempty"`
+	Limit            int     `json:"limit"`
+	NextCursor       *string `json:"next_cursor"`
+	PrevCursor       *string `json:"prev_cursor"`
 }
 
 type MarkAttendanceRequest struct {
 	MemberIDs []string `json:"member_ids" validate:"required,min=1"`
 	Attended  bool     `json:"attended"`
 }
+
+type RemoveParticipantRequest struct {
+	Reason string `json:"reason" validate:"required,max=1000"`
+}
+
+// EventSettings holds per-event options stored next to the event. Events
+// without stored settings use the defaults.
+type EventSettings struct {
+	EventID      uuid.UUID `json:"event_id"`
+	AllowWalkIns bool      `json:"allow_walk_ins"`
+	// CancellationCutoffHours closes cancellation this many hours before
+	// the start; 0 allows it until the start
+	CancellationCutoffHours int        `json:"cancellation_cutoff_hours"`
+	UpdatedAt               *time.Time `json:"updated_at,omitempty"`
+}
+
+type UpdateEventSettingsRequest struct {
+	AllowWalkIns            *bool `json:"allow_walk_ins,omitempty"`
+	CancellationCutoffHours *int  `json:"cancellation_cutoff_hours,omitempty" validate:"omitempty,min=0,max=720"`
+}
+
+// EventTicket is a participant's signed check-in ticket
+type EventTicket struct {
+	EventID  uuid.UUID `json:"event_id"`
+	MemberID uuid.UUID `json:"member_id"`
+	Token    string    `json:"token"`
+	// QRCode is the token as a PNG data URL
+	QRCode string `json:"qr_code"`
+}
+
+// CheckinRequest carries a scanned QR code: an event ticket, or for walk-ins
+// a membership card
+type CheckinRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+type CheckinResult struct {
+	Participant EventParticipant `json:"participant"`
+	WalkIn      bool             `json:"walk_in"`
+}
@@ -0,0 +1,72 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EventSeries is the template of a recurring event. Occurrences are
+// generated as regular events up to a horizon and linked to the series.
+type EventSeries struct {
+	ID              uuid.UUID   `json:"id"`
+	OrganizationID  *uuid.UUID  `json:"organization_id,omitempty"`
+	Title           string      `json:"title"`
+	Description     *string     `json:"description,omitempty"`
+	Type            EventType   `json:"type"`
+	Status          EventStatus `json:"status"`
+	Location        *string     `json:"location,omitempty"`
+	Address         *string     `json:"address,omitempty"`
+	IsOnline        bool        `json:"is_online"`
+	OnlineURL       *string     `json:"online_url,omitempty"`
+	MaxParticipants *int        `json:"max_participants,omitempty"`
+	IsPublic        bool        `json:"is_public"`
+	CoverImageURL   *string     `json:"cover_image_url,omitempty"`
+	OrganizerID     *uuid.UUID  `json:"organizer_id,omitempty"`
+	// Recurrence is an RRULE such as FREQ=WEEKLY;BYDAY=TU
+	Recurrence string `json:"recurrence"`
+	// StartDate is the start of the first occurrence; its local time of day
+	// in Timezone is kept by every occurrence
+	StartDate       time.Time  `json:"start_date"`
+	DurationMinutes *int       `json:"duration_minutes,omitempty"`
+	Timezone        string     `json:"timezone"`
+	GeneratedUntil  *time.Time `json:"generated_until,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+
+	Occurrences []Event `json:"occurrences,omitempty"`
+}
+
+// EventOccurrence links a generated event to its series. Detached
+// occurrences were edited on their own and are left alone by series edits.
+type EventOccurrence struct {
+	SeriesID      uuid.UUID  `json:"series_id"`
+	EventID       *uuid.UUID `json:"event_id,omitempty"`
+	OriginalStart time.Time  `json:"original_start"`
+	Detached      bool       `json:"detached"`
+}
+
+type CreateEventSeriesRequest struct {
+	Title           string      `json:"title" validate:"required,min=2,max=255"`
+	Description     *string     `json:"description,omitempty"`
+	Type            EventType   `json:"type" validate:"required,oneof=meeting training campaign volunteer cultural sports other"`
+	Status          EventStatus `json:"status,omitempty" validate:"omitempty,oneof=draft planned"`
+	StartDate       string      `json:"start_date" validate:"required"`
+	EndDate         *string     `json:"end_date,omitempty"`
+	Recurrence      string      `json:"recurrence" validate:"required,max=255"`
+	Timezone        *string     `json:"timezone,omitempty"`
+	Location        *string     `json:"location,omitempty"`
+	Address         *string     `json:"address,omitempty"`
+	IsOnline        bool        `json:"is_online"`
+	OnlineURL       *string     `json:"online_url,omitempty"`
+	MaxParticipants *int        `json:"max_participants,omitempty" validate:"omitempty,min=1"`
+	IsPublic        bool        `json:"is_public"`
+	CoverImageURL   *string     `json:"cover_image_url,omitempty"`
+	OrganizationID  *string     `json:"organization_id,omitempty"`
+}
+
+// Update scopes of an occurrence
+const (
+	EventUpdateScopeThis   = "this"
+	EventUpdateScopeFuture = "future"
+)
@@ -0,0 +1,104 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type SurveyQuestionType string
+
+const (
+	// SurveyQuestionRating is answered with 1 to 5
+	SurveyQuestionRating SurveyQuestionType = "rating"
+	// SurveyQuestionChoice is answered with one of its options
+	SurveyQuestionChoice SurveyQuestionType = "choice"
+	SurveyQuestionText   SurveyQuestionType = "text"
+)
+
+// Rating answers range from MinSurveyRating to MaxSurveyRating
+const (
+	MinSurveyRating = 1
+	MaxSurveyRating = 5
+)
+
+var (
+	// ErrSurveyAnswered is returned when changing a survey that already
+	// has responses
+	ErrSurveyAnswered = errors.New("survey already has responses")
+	// ErrSurveySubmitted is returned when a member submits a survey twice
+	ErrSurveySubmitted = errors.New("survey already submitted")
+)
+
+type SurveyQuestion struct {
+	ID       uuid.UUID          `json:"id"`
+	EventID  uuid.UUID          `json:"event_id"`
+	Position int                `json:"position"`
+	Type     SurveyQuestionType `json:"type"`
+	Prompt   string             `json:"prompt"`
+	Options  []string           `json:"options,omitempty"`
+	Required bool               `json:"required"`
+}
+
+// EventSurvey is the feedback survey of an event
+type EventSurvey struct {
+	EventID   uuid.UUID        `json:"event_id"`
+	Questions []SurveyQuestion `json:"questions"`
+	Responses int              `json:"responses"`
+}
+
+type SurveyQuestionInput struct {
+	Type     SurveyQuestionType `json:"type" validate:"required,oneof=rating choice text"`
+	Prompt   string             `json:"prompt" validate:"required,max=500"`
+	Options  []string           `json:"options,omitempty" validate:"omitempty,max=20,dive,required,max=255"`
+	Required bool               `json:"required"`
+}
+
+type UpdateEventSurveyRequest struct {
+	Questions []SurveyQuestionInput `json:"questions" validate:"required,min=1,max=30,dive"`
+}
+
+// SurveyAnswer answers one question; the field matching the question's
+// type is set
+type SurveyAnswer struct {
+	QuestionID uuid.UUID `json:"question_id" validate:"required"`
+	Rating     *int      `json:"rating,omitempty"`
+	Choice     *string   `json:"choice,omitempty"`
+	Text       *string   `json:"text,omitempty" validate:"omitempty,max=5000"`
+}
+
+type SubmitSurveyRequest struct {
+	Answers []SurveyAnswer `json:"answers" validate:"required,max=30,dive"`
+}
+
+// SurveyResponse is a member's submitted survey
+type SurveyResponse struct {
+	ID          uuid.UUID      `json:"id"`
+	EventID     uuid.UUID      `json:"event_id"`
+	MemberID    uuid.UUID      `json:"member_id"`
+	SubmittedAt time.Time      `json:"submitted_at"`
+	Answers     []SurveyAnswer `json:"answers"`
+}
+
+// SurveyQuestionResult aggregates the answers to one question
+type SurveyQuestionResult struct {
+	SurveyQuestion
+	Answers int `json:"answers"`
+	// Rating questions
+	AverageRating *float64    `json:"average_rating,omitempty"`
+	Ratings       map[int]int `json:"ratings,omitempty"`
+	// Choice questions: answers per option
+	Choices map[string]int `json:"choices,omitempty"`
+	// Text questions: the latest answers
+	Texts []string `json:"texts,omitempty"`
+}
+
+// SurveyResults aggregates the responses to an event's survey
+type SurveyResults struct {
+	EventID      uuid.UUID              `json:"event_id"`
+	Responses    int                    `json:"responses"`
+	Attended     int                    `json:"attended"`
+	ResponseRate float64                `json:"response_rate"`
+	Questions    []SurveyQuestionResult `json:"questions"`
+}
@@ -0,0 +1,77 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ExportType string
+
+const (
+	ExportTypeMembers       ExportType = "members"
+	ExportTypeFees          ExportType = "fees"
+	ExportTypeEvents        ExportType = "events"
+	ExportTypeOrganizations ExportType = "organizations"
+)
+
+type ExportFormat string
+
+const (
+	ExportFormatCSV  ExportFormat = "csv"
+	ExportFormatXLSX ExportFormat = "xlsx"
+)
+
+// ContentType returns the MIME type of files in this format
+func (f ExportFormat) ContentType() string {
+	if f == ExportFormatXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// ExportParams describes the contents of an export file. Filters map onto the
+// list params of the exported resource; ProvinceID, DistrictID and
+// OrganizationID are also used to apply the requester's data scope.
+type ExportParams struct {
+	Type           ExportType   `json:"type"`
+	Format         ExportFormat `json:"format" query:"format"`
+	Columns        []string     `json:"columns,omitempty"`
+	Status         *string      `json:"status,omitempty" query:"status"`
+	OrganizationID *string      `json:"organization_id,omitempty" query:"organization_id"`
+	Year           *int         `json:"year,omitempty" query:"year"`
+	DateFrom       *string      `json:"date_from,omitempty" query:"date_from"`
+	DateTo         *string      `json:"date_to,omitempty" query:"date_to"`
+
+	// Data scope (set by the server, never from the query string)
+	ProvinceID *string `json:"province_id,omitempty" query:"-"`
+	DistrictID *string `json:"district_id,omitempty" query:"-"`
+}
+
+type ExportJobStatus string
+
+const (
+	ExportJobPending    ExportJobStatus = "pending"
+	ExportJobProcessing ExportJobStatus = "processing"
+	ExportJobCompleted  ExportJobStatus = "completed"
+	ExportJobFailed     ExportJobStatus = "failed"
+)
+
+type ExportJob struct {
+	ID           uuid.UUID       `json:"id"`
+	RequestedBy  string          `json:"requested_by"`
+	Params       ExportParams    `json:"params"`
+	Status       ExportJobStatus `json:"status"`
+	ObjectKey    *string         `json:"-"`
+	FileName     *string         `json:"file_name,omitempty"`
+	FileSize     *int64          `json:"file_size,omitempty"`
+	ErrorMessage *string         `json:"error_message,omitempty"`
+	Attempts     int             `json:"attempts"`
+	CreatedAt    time.Time       `json:"created_at"`
+	StartedAt    *time.Time      `json:"started_at,omitempty"`
+	CompletedAt  *time.Time      `json:"completed_at,omitempty"`
+
+	// Set when the job is completed and fetched by its owner
+	DownloadURL       *string    `json:"download_url,omitempty"`
+	DownloadExpiresAt *time.Time `json:"download_expires_at,omitempty"`
+}
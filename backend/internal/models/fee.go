@@ -49,11 +49,12 @@ type CreateFeeRequest struct {
 }
 
 type UpdateFeeRequest struct {
-	Amount        *float64 `json:"amount,omitempty" validate:"omitempty,min=0"`
-	Status        *string  `json:"status,omitempty" validate:"omitempty,oneof=pending paid overdue waived"`
-	PaymentMethod *string  `json:"payment_method,omitempty"`
-	ReceiptNumber *string  `json:"receipt_number,omitempty"`
-	Notes         *string  `json:"notes,omitempty"`
+	Amount        *float64   `json:"amount,omitempty" validate:"omitempty,min=0"`
+	Status        *string    `json:"status,omitempty" validate:"omitempty,oneof=pending paid overdue waived"`
+	PaidAt        *time.Time `json:"paid_at,omitempty"`
+	PaymentMethod *string    `json:"payment_method,omitempty"`
+	ReceiptNumber *string    `json:"receipt_number,omitempty"`
+	Notes         *string    `json:"notes,omitempty"`
 }
 
 type FeeListParams struct {
@@ -64,6 +65,23 @@ type FeeListParams struct {
 	Month          *int           `query:"month"`
 	Status         *PaymentStatus `query:"status"`
 	OrganizationID *string        `query:"organization_id"`
+	ProvinceID     *string        `query:"province_id"`
+	DistrictID     *string        `query:"district_id"`
+	PaidFrom       *string        `query:"paid_from"`
+	PaidTo         *string        `query:"paid_to"`
+	SortBy         string         `query:"sort_by" validate:"omitempty,oneof=period paid_at created_at amount"`
+	SortOrder      string         `query:"sort_order" validate:"omitempty,oneof=asc desc"`
+	Cursor         string         `query:"cursor"`
+	Total          TotalMode      `query:"total" validate:"omitempty,oneof=exact approx none"`
+}
+
+type FeeListResponse struct {
+	Fees             []MembershipFee `json:"fees"`
+	Total            *int            `json:"total,omitempty"`
+	TotalApproximate bool            `json:"total_approximate,omitempty"`
+	Limit            int             `json:"limit"`
+	NextCursor       *string         `json:"next_cursor"`
+	PrevCursor       *string         `json:"prev_cursor"`
 }
 
 type BulkCreateFeeRequest struct {
@@ -0,0 +1,9 @@
+package models
+
+// ImageUpload is the result of an avatar or event cover upload
+type ImageUpload struct {
+	URL          string `json:"url"`
+	ThumbnailURL string `json:"thumbnail_url"`
+	Width        int    `json:"width"`
+	Height       int    `json:"height"`
+}
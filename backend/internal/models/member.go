@@ -16,6 +16,26 @@ const (
 	MemberStatusExpelled  MemberStatus = "expelled"
 )
 
+// MemberStatusTransitions lists the statuses a member can move to from each
+// status. Expulsion is final.
+var MemberStatusTransitions = map[MemberStatus][]MemberStatus{
+	MemberStatusPending:   {MemberStatusActive, MemberStatusInactive},
+	MemberStatusActive:    {MemberStatusInactive, MemberStatusSuspended, MemberStatusExpelled},
+	MemberStatusInactive:  {MemberStatusActive, MemberStatusExpelled},
+	MemberStatusSuspended: {MemberStatusActive, MemberStatusInactive, MemberStatusExpelled},
+	MemberStatusExpelled:  {},
+}
+
+// CanTransitionTo reports whether a member in status s can be moved to next
+func (s MemberStatus) CanTransitionTo(next MemberStatus) bool {
+	for _, allowed := range MemberStatusTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
 type Gender string
 
 const (
@@ -91,6 +111,17 @@ type MemberHistory struct {
 	ChangedBy *uuid.UUID `json:"changed_by,omitempty" db:"changed_by"`
 	Reason    *string    `json:"reason,omitempty" db:"reason"`
 	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+
+	// Changes holds the per-field diff of an "updated" entry
+	Changes []FieldChange `json:"changes,omitempty" db:"changes"`
+}
+
+// FieldChange is the before/after value of one member field. A nil value
+// means the field was empty.
+type FieldChange struct {
+	Field    string  `json:"field"`
+	OldValue *string `json:"old_value"`
+	NewValue *string `json:"new_value"`
 }
 
 type CreateMemberRequest struct {
@@ -130,23 +161,49 @@ type UpdateMemberRequest struct {
 	AvatarURL      *string `json:"avatar_url,omitempty"`
 	Bio            *string `json:"bio,omitempty"`
 	Notes          *string `json:"notes,omitempty"`
+
+	// Reason is recorded in the member history with the changes
+	Reason *string `json:"reason,omitempty" validate:"omitempty,max=1000"`
 }
 
 type UpdateStatusRequest struct {
 	Status MemberStatus `json:"status" validate:"required,oneof=pending active inactive suspended expelled"`
-	Reason *string      `json:"reason,omitempty"`
+	Reason *string      `json:"reason,omitempty" validate:"omitempty,max=1000"`
+	// SuspendedUntil ends a suspension automatically; omit for an indefinite one
+	SuspendedUntil *time.Time `json:"suspended_until,omitempty"`
 }
 
+// SearchMode selects how MemberListParams.Search is matched
+type SearchMode string
+
+const (
+	// SearchModeSimple is a case-insensitive substring match
+	SearchModeSimple SearchMode = "simple"
+	// SearchModeFullText matches whole words, ranked by relevance
+	SearchModeFullText SearchMode = "fulltext"
+	// SearchModeFuzzy also matches partial words and typos, ranked by relevance
+	SearchModeFuzzy SearchMode = "fuzzy"
+)
+
 type MemberListParams struct {
 	Page           int           `query:"page"`
 	Limit          int           `query:"limit"`
 	Search         string        `query:"search"`
+	SearchMode     SearchMode    `query:"search_mode" validate:"omitempty,oneof=simple fulltext fuzzy"`
 	Status         *MemberStatus `query:"status"`
 	OrganizationID *string       `query:"organization_id"`
 	ProvinceID     *string       `query:"province_id"`
 	DistrictID     *string       `query:"district_id"`
-	SortBy         string        `query:"sort_by"`
-	SortOrder      string        `query:"sort_order"`
+	CreatedFrom    *string       `query:"created_from"`
+	CreatedTo      *string       `query:"created_to"`
+	SortBy         string        `query:"sort_by" validate:"omitempty,oneof=created_at updated_at joined_at membership_expires_at first_name last_name member_id status"`
+	SortOrder      string        `query:"sort_order" validate:"omitempty,oneof=asc desc"`
+	Cursor         string        `query:"cursor"`
+	Total          TotalMode     `query:"total" validate:"omitempty,oneof=exact approx none"`
+
+	// Membership expiry window (set by the server for /members/expiring)
+	ExpiresFrom *time.Time `query:"-"`
+	ExpiresTo   *time.Time `query:"-"`
 }
 
 type MemberListResponse struct {
@@ -155,4 +212,54 @@ type MemberListResponse struct {
 	Page       int      `json:"page"`
 	Limit      int      `json:"limit"`
 	TotalPages int      `json:"total_pages"`
+
+	NextCursor       *string `json:"next_cursor"`
+	PrevCursor       *string `json:"prev_cursor"`
+	TotalApproximate bool    `json:"total_approximate,omitempty"`
+}
+
+// MemberImportRow is a single data row of an import file. Row is the 1-based
+// line number in the file, counting the header.
+type MemberImportRow struct {
+	Row     int
+	Request CreateMemberRequest
+	Errors  []MemberImportError
+}
+
+type MemberImportError struct {
+	Row     int    `json:"row"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+type MemberImportResult struct {
+	DryRun    bool                `json:"dry_run"`
+	TotalRows int                 `json:"total_rows"`
+	ValidRows int                 `json:"valid_rows"`
+	Imported  int                 `json:"imported"`
+	Errors    []MemberImportError `json:"errors"`
+}
+
+// DuplicateCandidate is a pair of members that are suspected to be the same
+// person. Reasons lists the fields that matched.
+type DuplicateCandidate struct {
+	Member    Member   `json:"member"`
+	Duplicate Member   `json:"duplicate"`
+	Score     int      `json:"score"`
+	Reasons   []string `json:"reasons"`
+}
+
+type DuplicateListParams struct {
+	MinScore       int     `query:"min_score"`
+	Limit          int     `query:"limit"`
+	OrganizationID *string `query:"organization_id"`
+
+	// Data scope (set by the server)
+	ProvinceID *string `query:"-"`
+	DistrictID *string `query:"-"`
+}
+
+type MergeMembersRequest struct {
+	DuplicateID string  `json:"duplicate_id" validate:"required,uuid"`
+	Reason      *string `json:"reason,omitempty"`
 }
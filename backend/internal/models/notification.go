@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Notification types
+const (
+	NotificationEventCancelled = "event_cancelled"
+)
+
+// Notification is an in-app message to a member
+type Notification struct {
+	ID        uuid.UUID  `json:"id"`
+	MemberID  uuid.UUID  `json:"member_id"`
+	Type      string     `json:"type"`
+	Title     string     `json:"title"`
+	Body      *string    `json:"body,omitempty"`
+	EventID   *uuid.UUID `json:"event_id,omitempty"`
+	ReadAt    *time.Time `json:"read_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
@@ -124,6 +124,7 @@ type OrganizationListParams struct {
 	Search     string    `query:"search"`
 	Level      *OrgLevel `query:"level"`
 	ProvinceID *string   `query:"province_id"`
+	DistrictID *string   `query:"district_id"`
 	ParentID   *string   `query:"parent_id"`
 	IsActive   *bool     `query:"is_active"`
 }
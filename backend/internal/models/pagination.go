@@ -0,0 +1,56 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+// TotalMode selects how a list endpoint counts the matching rows
+type TotalMode string
+
+const (
+	// TotalExact runs COUNT(*) over the filtered rows
+	TotalExact TotalMode = "exact"
+	// TotalApprox uses the query planner's row estimate, which stays cheap on
+	// large tables
+	TotalApprox TotalMode = "approx"
+	// TotalNone skips counting
+	TotalNone TotalMode = "none"
+)
+
+// ErrInvalidCursor is returned for a cursor that cannot be decoded or was
+// issued for a different sort order
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor is the decoded form of the opaque next_cursor/prev_cursor values.
+// It holds the sort key of the row at the edge of a page, so the adjacent
+// page can be read with a keyset condition instead of OFFSET.
+type Cursor struct {
+	Sort   string    `json:"s"`
+	Values []string  `json:"v"`
+	ID     uuid.UUID `json:"id"`
+	// Prev pages backwards from the row
+	Prev bool `json:"p,omitempty"`
+}
+
+// Encode returns the opaque string form of the cursor
+func (c *Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor parses a cursor produced by Encode
+func DecodeCursor(s string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c Cursor
+	if err := json.Unmarshal(data, &c); err != nil || c.ID == uuid.Nil {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
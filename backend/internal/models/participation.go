@@ -0,0 +1,63 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CreditsHours reports whether participants of the event type are credited
+// volunteer hours
+func (t EventType) CreditsHours() bool {
+	return t == EventTypeVolunteer || t == EventTypeCampaign
+}
+
+type SetParticipantHoursRequest struct {
+	Hours float64 `json:"hours" validate:"gte=0,lte=500"`
+}
+
+// ParticipationSummary is a member's attended events, volunteer hours and
+// participation points
+type ParticipationSummary struct {
+	MemberID       uuid.UUID `json:"member_id"`
+	EventsAttended int       `json:"events_attended"`
+	VolunteerHours float64   `json:"volunteer_hours"`
+	Points         int       `json:"points"`
+	// LastAttendedAt is the start of the latest attended event
+	LastAttendedAt *time.Time `json:"last_attended_at,omitempty"`
+}
+
+type ParticipationLeaderboardParams struct {
+	OrganizationID *string `query:"organization_id"`
+	From           *string `query:"from" validate:"omitempty,datetime=2006-01-02"`
+	To             *string `query:"to" validate:"omitempty,datetime=2006-01-02"`
+	Limit          int     `query:"limit"`
+
+	// Data scope (set by the server)
+	ProvinceID *string `query:"-"`
+	DistrictID *string `query:"-"`
+}
+
+// ParticipationLeader is a member's participation in events that started
+// within the leaderboard period
+type ParticipationLeader struct {
+	Rank             int        `json:"rank"`
+	ID               uuid.UUID  `json:"id"`
+	MemberID         string     `json:"member_id"`
+	FirstName        string     `json:"first_name"`
+	LastName         string     `json:"last_name"`
+	OrganizationID   *uuid.UUID `json:"organization_id,omitempty"`
+	OrganizationName *string    `json:"organization_name,omitempty"`
+	EventsAttended   int        `json:"events_attended"`
+	VolunteerHours   float64    `json:"volunteer_hours"`
+	Points           int        `json:"points"`
+}
+
+type ParticipationLeaderboard struct {
+	From    *string               `json:"from,omitempty"`
+	To      *string               `json:"to,omitempty"`
+	Leaders []ParticipationLeader `json:"leaders"`
+	// Points per attended event and per volunteer hour
+	PointsPerEvent int `json:"points_per_event"`
+	PointsPerHour  int `json:"points_per_hour"`
+}
@@ -28,6 +28,9 @@ const (
 	ActionImport Action = "import"
 	ActionApprove Action = "approve"
 	ActionReject  Action = "reject"
+	// ActionViewSensitive allows reading sensitive member fields such as the
+	// national ID in history
+	ActionViewSensitive Action = "view_sensitive"
 )
 
 // Permission represents a specific permission (resource + action)
@@ -74,6 +77,7 @@ var PermissionMatrix = map[string]RolePermission{
 			{ResourceMember, ActionImport},
 			{ResourceMember, ActionApprove},
 			{ResourceMember, ActionReject},
+			{ResourceMember, ActionViewSensitive},
 			// Organizations - Full access
 			{ResourceOrganization, ActionCreate},
 			{ResourceOrganization, ActionRead},
@@ -126,6 +130,7 @@ var PermissionMatrix = map[string]RolePermission{
 			{ResourceMember, ActionExport},
 			{ResourceMember, ActionApprove},
 			{ResourceMember, ActionReject},
+			{ResourceMember, ActionViewSensitive},
 			// Organizations - Province scope (read only sub-orgs)
 			{ResourceOrganization, ActionRead},
 			{ResourceOrganization, ActionUpdate},
@@ -167,6 +172,8 @@ var PermissionMatrix = map[string]RolePermission{
 			{ResourceMember, ActionUpdate},
 			{ResourceMember, ActionList},
 			{ResourceMember, ActionExport},
+			{ResourceMember, ActionApprove},
+			{ResourceMember, ActionReject},
 			// Organizations - District scope (read only)
 			{ResourceOrganization, ActionRead},
 			{ResourceOrganization, ActionList},
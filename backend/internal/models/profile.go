@@ -0,0 +1,119 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ProfileField is a member field counted in the profile completeness score.
+// Verifiable fields can be marked verified by an admin.
+type ProfileField struct {
+	Name       string
+	Weight     int
+	Verifiable bool
+}
+
+// ProfileFields are the fields of a complete profile. The weights add up to
+// 100, so the score is a percentage.
+var ProfileFields = []ProfileField{
+	{Name: "national_id", Weight: 15, Verifiable: true},
+	{Name: "birth_date", Weight: 10, Verifiable: true},
+	{Name: "gender", Weight: 5},
+	{Name: "phone", Weight: 15, Verifiable: true},
+	{Name: "email", Weight: 10, Verifiable: true},
+	{Name: "address", Weight: 5, Verifiable: true},
+	{Name: "province_id", Weight: 10},
+	{Name: "district_id", Weight: 10},
+	{Name: "education", Weight: 10, Verifiable: true},
+	{Name: "occupation", Weight: 5},
+	{Name: "workplace", Weight: 5},
+}
+
+// FindProfileField looks up a profile field by name
+func FindProfileField(name string) (ProfileField, bool) {
+	for _, f := range ProfileFields {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return ProfileField{}, false
+}
+
+// FieldVerification records that an admin checked a member field, e.g. the
+// national ID against an identity document. It is removed when the field
+// changes.
+type FieldVerification struct {
+	MemberID   uuid.UUID  `json:"member_id" db:"member_id"`
+	Field      string     `json:"field" db:"field"`
+	VerifiedBy *uuid.UUID `json:"verified_by,omitempty" db:"verified_by"`
+	VerifiedAt time.Time  `json:"verified_at" db:"verified_at"`
+	Note       *string    `json:"note,omitempty" db:"note"`
+}
+
+type ProfileFieldStatus struct {
+	Field        string             `json:"field"`
+	Weight       int                `json:"weight"`
+	Filled       bool               `json:"filled"`
+	Verifiable   bool               `json:"verifiable"`
+	Verified     bool               `json:"verified"`
+	Verification *FieldVerification `json:"verification,omitempty"`
+}
+
+type ProfileCompleteness struct {
+	MemberID uuid.UUID            `json:"member_id"`
+	Score    int                  `json:"score"`
+	Missing  []string             `json:"missing"`
+	Fields   []ProfileFieldStatus `json:"fields"`
+}
+
+type VerifyFieldRequest struct {
+	Field string  `json:"field" validate:"required"`
+	Note  *string `json:"note,omitempty" validate:"omitempty,max=1000"`
+}
+
+type IncompleteProfileParams struct {
+	Page           int           `query:"page"`
+	Limit          int           `query:"limit"`
+	OrganizationID *string       `query:"organization_id"`
+	Status         *MemberStatus `query:"status" validate:"omitempty,oneof=pending active inactive suspended expelled"`
+	// MaxScore lists members scoring at most this much (default 99)
+	MaxScore *int `query:"max_score" validate:"omitempty,min=0,max=100"`
+
+	// Data scope (set by the server)
+	ProvinceID *string `query:"-"`
+	DistrictID *string `query:"-"`
+}
+
+// IncompleteProfile is a member in the incomplete profiles report
+type IncompleteProfile struct {
+	ID               uuid.UUID    `json:"id"`
+	MemberID         string       `json:"member_id"`
+	FirstName        string       `json:"first_name"`
+	LastName         string       `json:"last_name"`
+	Status           MemberStatus `json:"status"`
+	Phone            *string      `json:"phone,omitempty"`
+	OrganizationID   *uuid.UUID   `json:"organization_id,omitempty"`
+	OrganizationName *string      `json:"organization_name,omitempty"`
+	Score            int          `json:"score"`
+	Missing          []string     `json:"missing"`
+}
+
+// OrganizationCompleteness summarises profile completeness of an
+// organization's members
+type OrganizationCompleteness struct {
+	OrganizationID   *uuid.UUID `json:"organization_id,omitempty"`
+	OrganizationName *string    `json:"organization_name,omitempty"`
+	Members          int        `json:"members"`
+	Incomplete       int        `json:"incomplete"`
+	AverageScore     float64    `json:"average_score"`
+}
+
+type IncompleteProfileReport struct {
+	Organizations []OrganizationCompleteness `json:"organizations"`
+	Members       []IncompleteProfile        `json:"members"`
+	Total         int                        `json:"total"`
+	Page          int                        `json:"page"`
+	Limit         int                        `json:"limit"`
+	TotalPages    int                        `json:"total_pages"`
+}
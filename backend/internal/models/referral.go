@@ -0,0 +1,67 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ReferralNode is a member in a referral tree with the members they
+// referred. Counts only include referred members who reached active status.
+type ReferralNode struct {
+	ID         uuid.UUID    `json:"id"`
+	MemberID   string       `json:"member_id"`
+	FirstName  string       `json:"first_name"`
+	LastName   string       `json:"last_name"`
+	Status     MemberStatus `json:"status"`
+	ReferredBy uuid.UUID    `json:"referred_by"`
+	Level      int          `json:"level"`
+	JoinedAt   *time.Time   `json:"joined_at,omitempty"`
+	CreatedAt  time.Time    `json:"created_at"`
+
+	// Activated is whether the member ever reached active status
+	Activated bool `json:"activated"`
+	// ActiveReferrals counts this member's direct activated referrals and
+	// TotalActiveReferrals those of the whole subtree
+	ActiveReferrals      int            `json:"active_referrals"`
+	TotalActiveReferrals int            `json:"total_active_referrals"`
+	Referrals            []ReferralNode `json:"referrals"`
+}
+
+type ReferralTree struct {
+	MemberID             uuid.UUID      `json:"member_id"`
+	Depth                int            `json:"depth"`
+	ActiveReferrals      int            `json:"active_referrals"`
+	TotalActiveReferrals int            `json:"total_active_referrals"`
+	Referrals            []ReferralNode `json:"referrals"`
+}
+
+type ReferralLeaderboardParams struct {
+	OrganizationID *string `query:"organization_id"`
+	From           *string `query:"from" validate:"omitempty,datetime=2006-01-02"`
+	To             *string `query:"to" validate:"omitempty,datetime=2006-01-02"`
+	Limit          int     `query:"limit"`
+
+	// Data scope (set by the server)
+	ProvinceID *string `query:"-"`
+	DistrictID *string `query:"-"`
+}
+
+// ReferralLeader is a referrer's count of referred members who became
+// active within the leaderboard period
+type ReferralLeader struct {
+	Rank             int        `json:"rank"`
+	ID               uuid.UUID  `json:"id"`
+	MemberID         string     `json:"member_id"`
+	FirstName        string     `json:"first_name"`
+	LastName         string     `json:"last_name"`
+	OrganizationID   *uuid.UUID `json:"organization_id,omitempty"`
+	OrganizationName *string    `json:"organization_name,omitempty"`
+	ActiveReferrals  int        `json:"active_referrals"`
+}
+
+type ReferralLeaderboard struct {
+	From    *string          `json:"from,omitempty"`
+	To      *string          `json:"to,omitempty"`
+	Leaders []ReferralLeader `json:"leaders"`
+}
@@ -0,0 +1,76 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type TransferStatus string
+
+const (
+	TransferStatusPending   TransferStatus = "pending"
+	TransferStatusAccepted  TransferStatus = "accepted"
+	TransferStatusDeclined  TransferStatus = "declined"
+	TransferStatusCancelled TransferStatus = "cancelled"
+)
+
+// MemberTransfer is a request to move a member to another organization. It
+// is initiated by the member or an admin of the source organization and
+// decided by an admin of the receiving organization.
+type MemberTransfer struct {
+	ID                 uuid.UUID      `json:"id" db:"id"`
+	MemberID           uuid.UUID      `json:"member_id" db:"member_id"`
+	FromOrganizationID *uuid.UUID     `json:"from_organization_id,omitempty" db:"from_organization_id"`
+	ToOrganizationID   uuid.UUID      `json:"to_organization_id" db:"to_organization_id"`
+	Status             TransferStatus `json:"status" db:"status"`
+	Reason             *string        `json:"reason,omitempty" db:"reason"`
+	RequestedBy        *uuid.UUID     `json:"requested_by,omitempty" db:"requested_by"`
+	RequestedAt        time.Time      `json:"requested_at" db:"requested_at"`
+	DecidedAt          *time.Time     `json:"decided_at,omitempty" db:"decided_at"`
+	DecidedBy          *uuid.UUID     `json:"decided_by,omitempty" db:"decided_by"`
+	DecisionReason     *string        `json:"decision_reason,omitempty" db:"decision_reason"`
+	CreatedAt          time.Time      `json:"created_at" db:"created_at"`
+	UpdatedAt          time.Time      `json:"updated_at" db:"updated_at"`
+
+	// Joined fields
+	MemberCode           string  `json:"member_code" db:"member_code"`
+	MemberName           string  `json:"member_name" db:"member_name"`
+	FromOrganizationName *string `json:"from_organization_name,omitempty" db:"from_organization_name"`
+	ToOrganizationName   string  `json:"to_organization_name" db:"to_organization_name"`
+}
+
+// TransferDirection filters an organization's transfers by side
+type TransferDirection string
+
+const (
+	TransferDirectionIncoming TransferDirection = "incoming"
+	TransferDirectionOutgoing TransferDirection = "outgoing"
+)
+
+type TransferListParams struct {
+	Page      int               `query:"page"`
+	Limit     int               `query:"limit"`
+	Status    *TransferStatus   `query:"status" validate:"omitempty,oneof=pending accepted declined cancelled"`
+	Direction TransferDirection `query:"direction" validate:"omitempty,oneof=incoming outgoing"`
+
+	// Set from the route
+	OrganizationID uuid.UUID `query:"-"`
+}
+
+type TransferListResponse struct {
+	Transfers  []MemberTransfer `json:"transfers"`
+	Total      int              `json:"total"`
+	Page       int              `json:"page"`
+	Limit      int              `json:"limit"`
+	TotalPages int              `json:"total_pages"`
+}
+
+type CreateTransferRequest struct {
+	ToOrganizationID string  `json:"to_organization_id" validate:"required,uuid"`
+	Reason           *string `json:"reason,omitempty" validate:"omitempty,max=1000"`
+}
+
+type TransferDecisionRequest struct {
+	Reason *string `json:"reason,omitempty" validate:"omitempty,max=1000"`
+}
@@ -0,0 +1,200 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/sdyn/backend/internal/models"
+)
+
+type ApprovalRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewApprovalRepository(db *pgxpool.Pool) *ApprovalRepository {
+	return &ApprovalRepository{db: db}
+}
+
+const approvalSelect = `
+	SELECT a.id, a.member_id, a.status, a.level, a.province_id, a.district_id,
+		a.submitted_at, a.due_at, a.escalated_at, a.escalated_by, a.escalation_reason,
+		a.decided_at, a.decided_by, a.decision_reason, a.created_at, a.updated_at,
+		m.member_id as member_code, (m.first_name || ' ' || m.last_name) as member_name,
+		m.status as member_status, m.organization_id as member_organization_id,
+		p.name as province_name, d.name as district_name,
+		(a.status = 'pending' AND a.due_at < NOW()) as is_overdue
+	FROM membership_applications a
+	JOIN members m ON a.member_id = m.id
+	LEFT JOIN provinces p ON a.province_id = p.id
+	LEFT JOIN districts d ON a.district_id = d.id
+`
+
+func scanApproval(row pgx.Row) (*models.MembershipApplication, error) {
+	var a models.MembershipApplication
+	err := row.Scan(
+		&a.ID, &a.MemberID, &a.Status, &a.Level, &a.ProvinceID, &a.DistrictID,
+		&a.SubmittedAt, &a.DueAt, &a.EscalatedAt, &a.EscalatedBy, &a.EscalationReason,
+		&a.DecidedAt, &a.DecidedBy, &a.DecisionReason, &a.CreatedAt, &a.UpdatedAt,
+		&a.MemberCode, &a.MemberName, &a.MemberStatus, &a.MemberOrganizationID,
+		&a.ProvinceName, &a.DistrictName, &a.IsOverdue,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+func (r *ApprovalRepository) List(ctx context.Context, params *models.ApprovalListParams) (*models.ApprovalListResponse, error) {
+	offset := (params.Page - 1) * params.Limit
+
+	where := " WHERE 1=1"
+	args := []interface{}{}
+	argCount := 0
+
+	status := models.ApprovalStatusPending
+	if params.Status != nil {
+		status = *params.Status
+	}
+	argCount++
+	where += fmt.Sprintf(" AND a.status = $%d", argCount)
+	args = append(args, status)
+
+	if params.Level != nil {
+		argCount++
+		where += fmt.Sprintf(" AND a.level = $%d", argCount)
+		args = append(args, *params.Level)
+	}
+
+	if params.Overdue {
+		where += " AND a.status = 'pending' AND a.due_at < NOW()"
+	}
+
+	if params.OrganizationID != nil {
+		argCount++
+		where += fmt.Sprintf(" AND m.organization_id = $%d", argCount)
+		args = append(args, *params.OrganizationID)
+	}
+
+	if params.ProvinceID != nil {
+		argCount++
+		// National applications are beyond a province admin's review
+		where += fmt.Sprintf(" AND a.province_id = $%d AND a.level <> 'national'", argCount)
+		args = append(args, *params.ProvinceID)
+	}
+
+	if params.DistrictID != nil {
+		argCount++
+		where += fmt.Sprintf(" AND a.district_id = $%d AND a.level = 'district'", argCount)
+		args = append(args, *params.DistrictID)
+	}
+
+	var total int
+	countQuery := "SELECT COUNT(*) FROM membership_applications a JOIN members m ON a.member_id = m.id" + where
+	if err := r.db.QueryRow(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, err
+	}
+
+	// Oldest deadline first, so the queue is worked in SLA order
+	query := approvalSelect + where + fmt.Sprintf(" ORDER BY a.due_at ASC LIMIT $%d OFFSET $%d", argCount+1, argCount+2)
+	args = append(args, params.Limit, offset)
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applications := []models.MembershipApplication{}
+	for rows.Next() {
+		a, err := scanApproval(rows)
+		if err != nil {
+			return nil, err
+		}
+		applications = append(applications, *a)
+	}
+
+	return &models.ApprovalListResponse{
+		Applications: applications,
+		Total:        total,
+		Page:         params.Page,
+		Limit:        params.Limit,
+		TotalPages:   (total + params.Limit - 1) / params.Limit,
+	}, nil
+}
+
+func (r *ApprovalRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.MembershipApplication, error) {
+	return scanApproval(r.db.QueryRow(ctx, approvalSelect+" WHERE a.id = $1", id))
+}
+
+// Decide closes a pending application, sets the member's new status and
+// writes the member history row in one transaction. It returns
+// pgx.ErrNoRows if the application is no longer pending.
+func (r *ApprovalRepository) Decide(ctx context.Context, id uuid.UUID, status models.ApprovalStatus, memberStatus models.MemberStatus, decidedBy *uuid.UUID, reason *string, history *models.MemberHistory) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var memberID uuid.UUID
+	err = tx.QueryRow(ctx, `
+		UPDATE membership_applications
+		SET status = $2, decided_at = NOW(), decided_by = $3, decision_reason = $4, updated_at = NOW()
+		WHERE id = $1 AND status = 'pending'
+		RETURNING member_id
+	`, id, status, decidedBy, reason).Scan(&memberID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE members
+		SET status = $2,
+			joined_at = CASE WHEN $3 THEN COALESCE(joined_at, NOW()) ELSE joined_at END
+		WHERE id = $1
+	`, memberID, memberStatus, memberStatus == models.MemberStatusActive)
+	if err != nil {
+		return err
+	}
+
+	history.MemberID = memberID
+	if err := insertHistory(ctx, tx, history); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// Escalate moves a pending application to a higher review level with a new
+// deadline and writes the member history row. It returns pgx.ErrNoRows if
+// the application is no longer pending.
+func (r *ApprovalRepository) Escalate(ctx context.Context, id uuid.UUID, level models.ApprovalLevel, dueAt time.Time, escalatedBy *uuid.UUID, reason *string, history *models.MemberHistory) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var memberID uuid.UUID
+	err = tx.QueryRow(ctx, `
+		UPDATE membership_applications
+		SET level = $2, due_at = $3, escalated_at = NOW(), escalated_by = $4, escalation_reason = $5, updated_at = NOW()
+		WHERE id = $1 AND status = 'pending'
+		RETURNING member_id
+	`, id, level, dueAt, escalatedBy, reason).Scan(&memberID)
+	if err != nil {
+		return err
+	}
+
+	history.MemberID = memberID
+	if err := insertHistory(ctx, tx, history); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
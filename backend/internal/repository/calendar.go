@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/sdyn/backend/internal/models"
+)
+
+type CalendarTokenRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewCalendarTokenRepository(db *pgxpool.Pool) *CalendarTokenRepository {
+	return &CalendarTokenRepository{db: db}
+}
+
+// Save stores the member's token hash, replacing an earlier token
+func (r *CalendarTokenRepository) Save(ctx context.Context, memberID uuid.UUID, tokenHash string) error {
+	_, err := r.db.Exec(ctx, `
+		INSERT INTO calendar_tokens (member_id, token_hash)
+		VALUES ($1, $2)
+		ON CONFLICT (member_id) DO UPDATE
+		SET token_hash = EXCLUDED.token_hash, created_at = NOW(), last_used_at = NULL
+	`, memberID, tokenHash)
+	return err
+}
+
+// Delete revokes the member's token. It reports whether there was one.
+func (r *CalendarTokenRepository) Delete(ctx context.Context, memberID uuid.UUID) (bool, error) {
+	tag, err := r.db.Exec(ctx, "DELETE FROM calendar_tokens WHERE member_id = $1", memberID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// MemberID returns the owner of the token hash and records its use, or
+// pgx.ErrNoRows if the token is unknown or revoked
+func (r *CalendarTokenRepository) MemberID(ctx context.Context, tokenHash string) (uuid.UUID, error) {
+	var memberID uuid.UUID
+	err := r.db.QueryRow(ctx, `
+		UPDATE calendar_tokens SET last_used_at = NOW()
+		WHERE token_hash = $1
+		RETURNING member_id
+	`, tokenHash).Scan(&memberID)
+	return memberID, err
+}
+
+// CalendarEvents returns the events of a calendar feed starting after
+// since: drafts are left out, orgID limits them to one organization and
+// publicOnly to public events
+func (r *EventRepository) CalendarEvents(ctx context.Context, orgID *uuid.UUID, publicOnly bool, since time.Time, limit int) ([]models.Event, error) {
+	where := " WHERE e.status <> 'draft' AND e.start_date >= $1"
+	args := []interface{}{since}
+	argCount := 1
+
+	if orgID != nil {
+		argCount++
+		where += fmt.Sprintf(" AND e.organization_id = $%d", argCount)
+		args = append(args, *orgID)
+	}
+	if publicOnly {
+		where += " AND e.is_public = true"
+	}
+
+	query := `
+		SELECT e.*, o.name as organization_name
+		FROM events e
+		LEFT JOIN organizations o ON e.organization_id = o.id
+	` + where + fmt.Sprintf(" ORDER BY e.start_date LIMIT $%d", argCount+1)
+	args = append(args, limit)
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []models.Event{}
+	for rows.Next() {
+		var e models.Event
+		err := rows.Scan(
+			&e.ID, &e.OrganizationID, &e.Title, &e.Description, &e.Type, &e.Status,
+			&e.StartDate, &e.EndDate, &e.Location, &e.Address, &e.IsOnline, &e.OnlineURL,
+			&e.MaxParticipants, &e.RegistrationDeadline, &e.IsPublic, &e.CoverImageURL,
+			&e.OrganizerID, &e.CreatedAt, &e.UpdatedAt,
+			&e.OrganizationName,
+		)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+
+	return events, rows.Err()
+}
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/sdyn/backend/internal/models"
+)
+
+type DocumentRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewDocumentRepository(db *pgxpool.Pool) *DocumentRepository {
+	return &DocumentRepository{db: db}
+}
+
+const documentColumns = `
+	id, member_id, type, file_name, content_type, size_bytes, sha256, object_key,
+	description, uploaded_by, created_at
+`
+
+func scanDocument(row pgx.Row) (*models.MemberDocument, error) {
+	var d models.MemberDocument
+	err := row.Scan(
+		&d.ID, &d.MemberID, &d.Type, &d.FileName, &d.ContentType, &d.Size, &d.SHA256, &d.ObjectKey,
+		&d.Description, &d.UploadedBy, &d.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// ListByMember returns the member's documents, newest first
+func (r *DocumentRepository) ListByMember(ctx context.Context, memberID uuid.UUID) ([]models.MemberDocument, error) {
+	query := `SELECT ` + documentColumns + ` FROM member_documents WHERE member_id = $1 ORDER BY created_at DESC, id`
+
+	rows, err := r.db.Query(ctx, query, memberID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	documents := []models.MemberDocument{}
+	for rows.Next() {
+		d, err := scanDocument(rows)
+		if err != nil {
+			return nil, err
+		}
+		documents = append(documents, *d)
+	}
+
+	return documents, rows.Err()
+}
+
+// GetByID returns a document of the member. A document of another member is
+// pgx.ErrNoRows.
+func (r *DocumentRepository) GetByID(ctx context.Context, memberID, id uuid.UUID) (*models.MemberDocument, error) {
+	query := `SELECT ` + documentColumns + ` FROM member_documents WHERE id = $1 AND member_id = $2`
+	return scanDocument(r.db.QueryRow(ctx, query, id, memberID))
+}
+
+func (r *DocumentRepository) Create(ctx context.Context, d *models.MemberDocument) error {
+	query := `
+		INSERT INTO member_documents (id, member_id, type, file_name, content_type, size_bytes, sha256,
+			object_key, description, uploaded_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING created_at
+	`
+
+	return r.db.QueryRow(ctx, query,
+		d.ID, d.MemberID, d.Type, d.FileName, d.ContentType, d.Size, d.SHA256,
+		d.ObjectKey, d.Description, d.UploadedBy,
+	).Scan(&d.CreatedAt)
+}
+
+// Delete removes a document's metadata and returns it so the caller can
+// remove the file
+func (r *DocumentRepository) Delete(ctx context.Context, memberID, id uuid.UUID) (*models.MemberDocument, error) {
+	query := `DELETE FROM member_documents WHERE id = $1 AND member_id = $2 RETURNING ` + documentColumns
+	return scanDocument(r.db.QueryRow(ctx, query, id, memberID))
+}
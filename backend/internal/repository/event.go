@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/sdyn/backend/internal/models"
@@ -19,52 +20,167 @@ func NewEventRepository(db *pgxpool.Pool) *EventRepository {
 	return &EventRepository{db: db}
 }
 
-func (r *EventRepository) List(ctx context.Context, params *models.EventListParams) ([]models.Event, error) {
-	query := `
-		SELECT e.*, o.name as organization_name,
-			   (m.first_name || ' ' || m.last_name) as organizer_name,
-			   (SELECT COUNT(*) FROM event_participants ep WHERE ep.event_id = e.id) as total_registered,
-			   (SELECT COUNT(*) FROM event_participants ep WHERE ep.event_id = e.id AND ep.attended = true) as total_attended
+// eventOrders are the sort_by options of the event list
+var eventOrders = map[string][]keysetColumn{
+	"start_date": {{"e.start_date", "timestamptz"}},
+	"created_at": {{"e.created_at", "timestamptz"}},
+	"title":      {{"e.title", "text"}},
+}
+
+func (r *EventRepository) List(ctx context.Context, params *models.EventListParams) (*models.EventListResponse, error) {
+	from := `
 		FROM events e
 		LEFT JOIN organizations o ON e.organization_id = o.id
 		LEFT JOIN members m ON e.organizer_id = m.id
-		ORDER BY e.start_date DESC
+		LEFT JOIN event_series_occurrences so ON so.event_id = e.id
+		WHERE 1=1
 	`
+	where := ""
+	args := []interface{}{}
+	argCount := 0
 
-	rows, err := r.db.Query(ctx, query)
+	if params.Search != "" {
+		argCount++
+		where += fmt.Sprintf(" AND (e.title ILIKE $%d OR e.location ILIKE $%d)", argCount, argCount)
+		args = append(args, "%"+params.Search+"%")
+	}
+
+	if params.Type != nil {
+		argCount++
+		where += fmt.Sprintf(" AND e.type = $%d", argCount)
+		args = append(args, *params.Type)
+	}
+
+	if params.Status != nil {
+		argCount++
+		where += fmt.Sprintf(" AND e.status = $%d", argCount)
+		args = append(args, *params.Status)
+	}
+
+	if params.OrganizationID != nil {
+		argCount++
+		where += fmt.Sprintf(" AND e.organization_id = $%d", argCount)
+		args = append(args, *params.OrganizationID)
+	}
+
+	if params.ProvinceID != nil {
+		argCount++
+		where += fmt.Sprintf(" AND o.province_id = $%d", argCount)
+		args = append(args, *params.ProvinceID)
+	}
+
+	if params.DistrictID != nil {
+		argCount++
+		where += fmt.Sprintf(" AND o.district_id = $%d", argCount)
+		args = append(args, *params.DistrictID)
+	}
+
+	if params.StartDateFrom != nil {
+		argCount++
+		where += fmt.Sprintf(" AND e.start_date >= $%d", argCount)
+		args = append(args, *params.StartDateFrom)
+	}
+
+	if params.StartDateTo != nil {
+		argCount++
+		where += fmt.Sprintf(" AND e.start_date < ($%d::date + interval '1 day')", argCount)
+		args = append(args, *params.StartDateTo)
+	}
+
+	if params.IsPublic != nil {
+		argCount++
+		where += fmt.Sprintf(" AND e.is_public = $%d", argCount)
+		args = append(args, *params.IsPublic)
+	}
+
+	// Counting is opt-in here; these lists never returned a total
+	totalMode := params.Total
+	if totalMode == "" {
+		totalMode = models.TotalNone
+	}
+	total, err := countRows(ctx, r.db, totalMode, from+where, args)
+	if err != nil {
+		return nil, err
+	}
+
+	order := newKeysetOrder(eventOrders, params.SortBy, "start_date", params.SortOrder, "e.id", true)
+
+	var cursor *models.Cursor
+	offset := 0
+	if params.Cursor != "" {
+		if cursor, err = models.DecodeCursor(params.Cursor); err != nil {
+			return nil, err
+		}
+		condition, err := order.condition(cursor, &argCount, &args)
+		if err != nil {
+			return nil, err
+		}
+		where += condition
+	} else if params.Page > 1 {
+		offset = (params.Page - 1) * params.Limit
+	}
+
+	query := `
+		SELECT e.*, o.name as organization_name,
+			   (m.first_name || ' ' || m.last_name) as organizer_name, so.series_id,
+			   (SELECT COUNT(*) FROM event_participants ep WHERE ep.event_id = e.id AND ep.status IN ('registered', 'attended', 'no_show')) as total_registered,
+			   (SELECT COUNT(*) FROM event_participants ep WHERE ep.event_id = e.id AND ep.status = 'attended') as total_attended,
+			   (SELECT COUNT(*) FROM event_participants ep WHERE ep.event_id = e.id AND ep.status = 'waitlisted') as total_waitlisted, ` +
+		order.selectKey() + from + where + order.orderBy(cursor != nil && cursor.Prev) +
+		fmt.Sprintf(" LIMIT $%d OFFSET $%d", argCount+1, argCount+2)
+	args = append(args, params.Limit+1, offset)
+
+	rows, err := r.db.Query(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
 	events := []models.Event{}
+	keys := []keysetKey{}
 	for rows.Next() {
 		var e models.Event
+		var key keysetKey
 		err := rows.Scan(
 			&e.ID, &e.OrganizationID, &e.Title, &e.Description, &e.Type, &e.Status,
 			&e.StartDate, &e.EndDate, &e.Location, &e.Address, &e.IsOnline, &e.OnlineURL,
 			&e.MaxParticipants, &e.RegistrationDeadline, &e.IsPublic, &e.CoverImageURL,
 			&e.OrganizerID, &e.CreatedAt, &e.UpdatedAt,
-			&e.OrganizationName, &e.OrganizerName, &e.TotalRegistered, &e.TotalAttended,
+			&e.OrganizationName, &e.OrganizerName, &e.SeriesID, &e.TotalRegistered, &e.TotalAttended, &e.TotalWaitlisted,
+			&key.Values,
 		)
 		if err != nil {
 			return nil, err
 		}
+		key.ID = e.ID
 		events = append(events, e)
+		keys = append(keys, key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
 	}
 
-	return events, nil
+	result := &models.EventListResponse{
+		Total:            total,
+		TotalApproximate: totalMode == models.TotalApprox,
+		Limit:            params.Limit,
+	}
+	result.Events, result.NextCursor, result.PrevCursor = keysetPage(order, events, keys, params.Limit, cursor, offset)
+
+	return result, nil
 }
 
 func (r *EventRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Event, error) {
 	query := `
 		SELECT e.*, o.name as organization_name,
-			   (m.first_name || ' ' || m.last_name) as organizer_name,
-			   (SELECT COUNT(*) FROM event_participants ep WHERE ep.event_id = e.id) as total_registered,
-			   (SELECT COUNT(*) FROM event_participants ep WHERE ep.event_id = e.id AND ep.attended = true) as total_attended
+			   (m.first_name || ' ' || m.last_name) as organizer_name, so.series_id,
+			   (SELECT COUNT(*) FROM event_participants ep WHERE ep.event_id = e.id AND ep.status IN ('registered', 'attended', 'no_show')) as total_registered,
+			   (SELECT COUNT(*) FROM event_participants ep WHERE ep.event_id = e.id AND ep.status = 'attended') as total_attended,
+			   (SELECT COUNT(*) FROM event_participants ep WHERE ep.event_id = e.id AND ep.status = 'waitlisted') as total_waitlisted
 		FROM events e
 		LEFT JOIN organizations o ON e.organization_id = o.id
 		LEFT JOIN members m ON e.organizer_id = m.id
+		LEFT JOIN event_series_occurrences so ON so.event_id = e.id
 		WHERE e.id = $1
 	`
 
@@ -74,7 +190,7 @@ func (r *EventRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Ev
 		&e.StartDate, &e.EndDate, &e.Location, &e.Address, &e.IsOnline, &e.OnlineURL,
 		&e.MaxParticipants, &e.RegistrationDeadline, &e.IsPublic, &e.CoverImageURL,
 		&e.OrganizerID, &e.CreatedAt, &e.UpdatedAt,
-		&e.OrganizationName, &e.OrganizerName, &e.TotalRegistered, &e.TotalAttended,
+		&e.OrganizationName, &e.OrganizerName, &e.SeriesID, &e.TotalRegistered, &e.TotalAttended, &e.TotalWaitlisted,
 	)
 	if err != nil {
 		return nil, err
@@ -108,28 +224,32 @@ func (r *EventRepository) Create(ctx context.Context, event *models.Event) (*mod
 }
 
 func (r *EventRepository) Update(ctx context.Context, event *models.Event) (*models.Event, error) {
+	if err := updateEvent(ctx, r.db, event); err != nil {
+		return nil, err
+	}
+
+	return event, nil
+}
+
+// updateEvent saves the event's fields. The status only changes through
+// changeStatus.
+func updateEvent(ctx context.Context, q rowQuerier, event *models.Event) error {
 	query := `
 		UPDATE events SET
-			title = $2, description = $3, type = $4, status = $5,
-			start_date = $6, end_date = $7, location = $8, address = $9,
-			is_online = $10, online_url = $11, max_participants = $12,
-			registration_deadline = $13, is_public = $14, cover_image_url = $15
+			title = $2, description = $3, type = $4,
+			start_date = $5, end_date = $6, location = $7, address = $8,
+			is_online = $9, online_url = $10, max_participants = $11,
+			registration_deadline = $12, is_public = $13, cover_image_url = $14
 		WHERE id = $1
-		RETURNING updated_at
+		RETURNING status, updated_at
 	`
 
-	err := r.db.QueryRow(ctx, query,
-		event.ID, event.Title, event.Description, event.Type, event.Status,
+	return q.QueryRow(ctx, query,
+		event.ID, event.Title, event.Description, event.Type,
 		event.StartDate, event.EndDate, event.Location, event.Address, event.IsOnline,
 		event.OnlineURL, event.MaxParticipants, event.RegistrationDeadline, event.IsPublic,
 		event.CoverImageURL,
-	).Scan(&event.UpdatedAt)
-
-	if err != nil {
-		return nil, err
-	}
-
-	return event, nil
+	).Scan(&event.Status, &event.UpdatedAt)
 }
 
 func (r *EventRepository) Delete(ctx context.Context, id uuid.UUID) error {
@@ -137,27 +257,93 @@ func (r *EventRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	return err
 }
 
+// CountParticipants counts the participants holding a seat
 func (r *EventRepository) CountParticipants(ctx context.Context, eventID uuid.UUID) (int, error) {
 	var count int
-	err := r.db.QueryRow(ctx, "SELECT COUNT(*) FROM event_participants WHERE event_id = $1", eventID).Scan(&count)
+	err := r.db.QueryRow(ctx, "SELECT COUNT(*) FROM event_participants WHERE event_id = $1 AND status IN ('registered', 'attended', 'no_show')", eventID).Scan(&count)
 	return count, err
 }
 
+// RegisterParticipant registers the member, or puts them on the waitlist if
+// the event is full. The event row stays locked from the count to the insert
+// so concurrent registrations cannot overbook; promote_event_waitlist takes
+// the same lock. A member who is already registered or waitlisted keeps
+// their place. A registration the member cancelled is renewed at the back of
+// the line; one removed by an admin stays cancelled. It returns
+// pgx.ErrNoRows if the event does not exist and models.ErrEventClosed if it
+// was cancelled or has ended.
 func (r *EventRepository) RegisterParticipant(ctx context.Context, eventID, memberID uuid.UUID) error {
-	query := `
-		INSERT INTO event_participants (event_id, member_id)
-		VALUES ($1, $2)
-		ON CONFLICT (event_id, member_id) DO NOTHING
-	`
-	_, err := r.db.Exec(ctx, query, eventID, memberID)
-	return err
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var capacity *int
+	var eventStatus models.EventStatus
+	err = tx.QueryRow(ctx, "SELECT max_participants, status FROM events WHERE id = $1 FOR UPDATE", eventID).Scan(&capacity, &eventStatus)
+	if err != nil {
+		return err
+	}
+	if eventStatus == models.EventStatusCancelled || eventStatus == models.EventStatusCompleted {
+		return models.ErrEventClosed
+	}
+
+	status := models.ParticipantStatusRegistered
+	if capacity != nil {
+		var taken int
+		err := tx.QueryRow(ctx, `
+			SELECT COUNT(*) FROM event_participants WHERE event_id = $1 AND status IN ('registered', 'attended', 'no_show')
+		`, eventID).Scan(&taken)
+		if err != nil {
+			return err
+		}
+		if taken >= *capacity {
+			status = models.ParticipantStatusWaitlisted
+		}
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO event_participants (event_id, member_id, status)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (event_id, member_id) DO UPDATE
+		SET status = EXCLUDED.status, registered_at = NOW(), attended_at = NULL,
+			cancelled_at = NULL, cancelled_by = NULL, cancellation_reason = NULL
+		WHERE event_participants.status = 'cancelled' AND event_participants.cancelled_by = event_participants.member_id
+	`, eventID, memberID, status)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// CancelParticipant cancels a registered or waitlisted participation. A
+// freed seat goes to the waitlist through promote_event_waitlist. It returns
+// pgx.ErrNoRows if the member has no such participation.
+func (r *EventRepository) CancelParticipant(ctx context.Context, eventID, memberID, cancelledBy uuid.UUID, reason *string) error {
+	tag, err := r.db.Exec(ctx, `
+		UPDATE event_participants
+		SET status = 'cancelled', cancelled_at = NOW(), cancelled_by = $3, cancellation_reason = $4
+		WHERE event_id = $1 AND member_id = $2 AND status IN ('registered', 'waitlisted')
+	`, eventID, memberID, cancelledBy, reason)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
 }
 
+// MarkAttendance sets a seated participant's status to attended, or to
+// no_show when attended is false
 func (r *EventRepository) MarkAttendance(ctx context.Context, eventID, memberID uuid.UUID, attended bool) error {
 	now := time.Now()
 	query := `
-		UPDATE event_participants SET attended = $3, attended_at = $4
-		WHERE event_id = $1 AND member_id = $2
+		UPDATE event_participants
+		SET status = CASE WHEN $3 THEN 'attended' ELSE 'no_show' END, attended_at = $4
+		WHERE event_id = $1 AND member_id = $2 AND status IN ('registered', 'attended', 'no_show')
 	`
 
 	var attendedAt *time.Time
@@ -169,16 +355,43 @@ func (r *EventRepository) MarkAttendance(ctx context.Context, eventID, memberID
 	return err
 }
 
-func (r *EventRepository) GetParticipants(ctx context.Context, eventID uuid.UUID) ([]models.EventParticipant, error) {
-	query := `
-		SELECT ep.*, (m.first_name || ' ' || m.last_name) as member_name, m.email as member_email, m.phone as member_phone
-		FROM event_participants ep
-		JOIN members m ON ep.member_id = m.id
-		WHERE ep.event_id = $1
-		ORDER BY ep.registered_at
-	`
+// participantSelect selects the participants of event $1 with the place in
+// line of those on the waitlist
+const participantSelect = `
+	SELECT ep.id, ep.event_id, ep.member_id, ep.status, ep.registered_at,
+		   ep.status = 'attended' as attended, ep.attended_at, ep.notes,
+		   ep.cancelled_at, ep.cancelled_by, ep.cancellation_reason,
+		   ep.checked_out_at, ep.hours, ep.hours_entered_by,
+		   (m.first_name || ' ' || m.last_name) as member_name, m.email as member_email, m.phone as member_phone,
+		   ep.waitlist_position
+	FROM (
+		SELECT p.*,
+			   CASE WHEN p.status = 'waitlisted'
+					THEN ROW_NUMBER() OVER (PARTITION BY p.status ORDER BY p.registered_at, p.id)
+			   END as waitlist_position
+		FROM event_participants p
+		WHERE p.event_id = $1
+	) ep
+	JOIN members m ON ep.member_id = m.id
+`
+
+func scanParticipant(row pgx.Row) (*models.EventParticipant, error) {
+	var p models.EventParticipant
+	err := row.Scan(
+		&p.ID, &p.EventID, &p.MemberID, &p.Status, &p.RegisteredAt, &p.Attended, &p.AttendedAt, &p.Notes,
+		&p.CancelledAt, &p.CancelledBy, &p.CancellationReason,
+		&p.CheckedOutAt, &p.Hours, &p.HoursEnteredBy,
+		&p.MemberName, &p.MemberEmail, &p.MemberPhone,
+		&p.WaitlistPosition,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
 
-	rows, err := r.db.Query(ctx, query, eventID)
+func (r *EventRepository) GetParticipants(ctx context.Context, eventID uuid.UUID) ([]models.EventParticipant, error) {
+	rows, err := r.db.Query(ctx, participantSelect+" ORDER BY ep.registered_at", eventID)
 	if err != nil {
 		return nil, err
 	}
@@ -186,15 +399,11 @@ func (r *EventRepository) GetParticipants(ctx context.Context, eventID uuid.UUID
 
 	participants := []models.EventParticipant{}
 	for rows.Next() {
-		var p models.EventParticipant
-		err := rows.Scan(
-			&p.ID, &p.EventID, &p.MemberID, &p.RegisteredAt, &p.Attended, &p.AttendedAt, &p.Notes,
-			&p.MemberName, &p.MemberEmail, &p.MemberPhone,
-		)
+		p, err := scanParticipant(rows)
 		if err != nil {
 			return nil, err
 		}
-		participants = append(participants, p)
+		participants = append(participants, *p)
 	}
 
 	return participants, nil
@@ -206,7 +415,7 @@ func (r *EventRepository) GetMemberEvents(ctx context.Context, memberID uuid.UUI
 		FROM events e
 		JOIN event_participants ep ON e.id = ep.event_id
 		LEFT JOIN organizations o ON e.organization_id = o.id
-		WHERE ep.member_id = $1
+		WHERE ep.member_id = $1 AND ep.status <> 'cancelled'
 		ORDER BY e.start_date DESC
 	`
 
@@ -248,6 +457,7 @@ func (r *EventRepository) GetReport(ctx context.Context, orgID, startDate, endDa
 		"by_status":         map[string]int{},
 		"by_month":          []map[string]interface{}{},
 		"top_events":        []map[string]interface{}{},
+		"by_series":         []map[string]interface{}{},
 	}
 
 	// Build base condition
@@ -303,9 +513,9 @@ func (r *EventRepository) GetReport(ctx context.Context, orgID, startDate, endDa
 		FROM (
 			SELECT
 				COUNT(*) as registered,
-				COUNT(*) FILTER (WHERE ep.attended = true) as attended
+				COUNT(*) FILTER (WHERE ep.status = 'attended') as attended
 			FROM events e
-			LEFT JOIN event_participants ep ON e.id = ep.event_id
+			LEFT JOIN event_participants ep ON e.id = ep.event_id AND ep.status IN ('registered', 'attended', 'no_show')
 			%s
 			GROUP BY e.id
 		) sub
@@ -392,9 +602,9 @@ func (r *EventRepository) GetReport(ctx context.Context, orgID, startDate, endDa
 		SELECT
 			e.id, e.title, e.type, e.start_date,
 			COUNT(ep.id) as registered,
-			COUNT(ep.id) FILTER (WHERE ep.attended = true) as attended
+			COUNT(ep.id) FILTER (WHERE ep.status = 'attended') as attended
 		FROM events e
-		LEFT JOIN event_participants ep ON e.id = ep.event_id
+		LEFT JOIN event_participants ep ON e.id = ep.event_id AND ep.status IN ('registered', 'attended', 'no_show')
 		%s
 		GROUP BY e.id
 		ORDER BY registered DESC
@@ -428,5 +638,110 @@ func (r *EventRepository) GetReport(ctx context.Context, orgID, startDate, endDa
 		report["top_events"] = topEvents
 	}
 
+	// Attendance per recurring series
+	seriesQuery := fmt.Sprintf(`
+		SELECT
+			s.id, s.title,
+			COUNT(DISTINCT e.id) as occurrences,
+			COUNT(DISTINCT e.id) FILTER (WHERE e.status = 'completed') as completed,
+			COUNT(ep.id) as registered,
+			COUNT(ep.id) FILTER (WHERE ep.status = 'attended') as attended
+		FROM (SELECT * FROM events %s) e
+		JOIN event_series_occurrences so ON so.event_id = e.id
+		JOIN event_series s ON s.id = so.series_id
+		LEFT JOIN event_participants ep ON e.id = ep.event_id AND ep.status IN ('registered', 'attended', 'no_show')
+		GROUP BY s.id, s.title
+		ORDER BY s.title
+	`, whereClause)
+	seriesRows, err := r.db.Query(ctx, seriesQuery, args...)
+	if err == nil {
+		defer seriesRows.Close()
+		bySeries := []map[string]interface{}{}
+		for seriesRows.Next() {
+			var id, title string
+			var occurrences, completedCount, regCount, attCount int
+			if seriesRows.Scan(&id, &title, &occurrences, &completedCount, &regCount, &attCount) == nil {
+				series := map[string]interface{}{
+					"series_id":          id,
+					"title":              title,
+					"occurrences":        occurrences,
+					"completed":          completedCount,
+					"registered":         regCount,
+					"attended":           attCount,
+					"average_attendance": 0.0,
+					"attendance_rate":    0.0,
+				}
+				if completedCount > 0 {
+					series["average_attendance"] = float64(attCount) / float64(completedCount)
+				}
+				if regCount > 0 {
+					series["attendance_rate"] = float64(attCount) / float64(regCount) * 100
+				}
+				bySeries = append(bySeries, series)
+			}
+		}
+		report["by_series"] = bySeries
+	}
+
+	// Feedback of events with a survey
+	feedbackQuery := fmt.Sprintf(`
+		SELECT
+			e.id, e.title,
+			(SELECT COUNT(*) FROM event_participants ep WHERE ep.event_id = e.id AND ep.status = 'attended') as attended,
+			(SELECT COUNT(*) FROM event_survey_responses sr WHERE sr.event_id = e.id) as responses,
+			COALESCE(SUM(a.rating), 0) as rating_sum,
+			COUNT(a.rating) as ratings
+		FROM (SELECT * FROM events %s) e
+		JOIN event_survey_questions q ON q.event_id = e.id
+		LEFT JOIN event_survey_answers a ON a.question_id = q.id
+		GROUP BY e.id, e.title, e.start_date
+		ORDER BY e.start_date DESC
+	`, whereClause)
+	feedbackRows, err := r.db.Query(ctx, feedbackQuery, args...)
+	if err == nil {
+		defer feedbackRows.Close()
+		byEvent := []map[string]interface{}{}
+		var totalAttended, totalResponses, totalRatingSum, totalRatings int
+		for feedbackRows.Next() {
+			var id, title string
+			var attCount, responses, ratingSum, ratings int
+			if feedbackRows.Scan(&id, &title, &attCount, &responses, &ratingSum, &ratings) == nil {
+				event := map[string]interface{}{
+					"id":             id,
+					"title":          title,
+					"attended":       attCount,
+					"responses":      responses,
+					"response_rate":  0.0,
+					"average_rating": nil,
+				}
+				if attCount > 0 {
+					event["response_rate"] = float64(responses) / float64(attCount) * 100
+				}
+				if ratings > 0 {
+					event["average_rating"] = float64(ratingSum) / float64(ratings)
+				}
+				byEvent = append(byEvent, event)
+				totalAttended += attCount
+				totalResponses += responses
+				totalRatingSum += ratingSum
+				totalRatings += ratings
+			}
+		}
+		feedback := map[string]interface{}{
+			"surveyed_events": len(byEvent),
+			"responses":       totalResponses,
+			"response_rate":   0.0,
+			"average_rating":  nil,
+			"by_event":        byEvent,
+		}
+		if totalAttended > 0 {
+			feedback["response_rate"] = float64(totalResponses) / float64(totalAttended) * 100
+		}
+		if totalRatings > 0 {
+			feedback["average_rating"] = float64(totalRatingSum) / float64(totalRatings)
+		}
+		report["feedback"] = feedback
+	}
+
 	return report, nil
 }
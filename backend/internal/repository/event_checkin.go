@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/sdyn/backend/internal/models"
+)
+
+// GetSettings returns the event's settings, or the defaults if none were
+// stored
+func (r *EventRepository) GetSettings(ctx context.Context, eventID uuid.UUID) (*models.EventSettings, error) {
+	settings := &models.EventSettings{EventID: eventID}
+	err := r.db.QueryRow(ctx, `
+		SELECT allow_walk_ins, cancellation_cutoff_hours, updated_at FROM event_settings WHERE event_id = $1
+	`, eventID).Scan(&settings.AllowWalkIns, &settings.CancellationCutoffHours, &settings.UpdatedAt)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, err
+	}
+	return settings, nil
+}
+
+func (r *EventRepository) UpdateSettings(ctx context.Context, settings *models.EventSettings) error {
+	return r.db.QueryRow(ctx, `
+		INSERT INTO event_settings (event_id, allow_walk_ins, cancellation_cutoff_hours)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (event_id) DO UPDATE
+		SET allow_walk_ins = EXCLUDED.allow_walk_ins,
+			cancellation_cutoff_hours = EXCLUDED.cancellation_cutoff_hours,
+			updated_at = NOW()
+		RETURNING updated_at
+	`, settings.EventID, settings.AllowWalkIns, settings.CancellationCutoffHours).Scan(&settings.UpdatedAt)
+}
+
+// GetParticipant returns one participant of the event
+func (r *EventRepository) GetParticipant(ctx context.Context, eventID, memberID uuid.UUID) (*models.EventParticipant, error) {
+	return scanParticipant(r.db.QueryRow(ctx, participantSelect+" WHERE ep.member_id = $2", eventID, memberID))
+}
+
+// CheckIn marks a registered participant as attended. With walkIn set an
+// unregistered or waitlisted member, or one who cancelled, is checked in at
+// once; members removed by an admin stay out. It
+// reports whether the check-in happened and whether it was a walk-in; a
+// participant who had already checked in is (false, false, nil), and any
+// other member without walkIn is pgx.ErrNoRows.
+func (r *EventRepository) CheckIn(ctx context.Context, eventID, memberID uuid.UUID, walkIn bool) (bool, bool, error) {
+	// The status guard lets concurrent scans of one ticket succeed once
+	tag, err := r.db.Exec(ctx, `
+		UPDATE event_participants SET status = 'attended', attended_at = NOW()
+		WHERE event_id = $1 AND member_id = $2 AND status IN ('registered', 'no_show')
+	`, eventID, memberID)
+	if err != nil {
+		return false, false, err
+	}
+	if tag.RowsAffected() == 1 {
+		return true, false, nil
+	}
+
+	if walkIn {
+		tag, err := r.db.Exec(ctx, `
+			INSERT INTO event_participants (event_id, member_id, status, attended_at)
+			VALUES ($1, $2, 'attended', NOW())
+			ON CONFLICT (event_id, member_id) DO UPDATE
+			SET status = 'attended', attended_at = NOW(),
+				cancelled_at = NULL, cancelled_by = NULL, cancellation_reason = NULL
+			WHERE event_participants.status = 'waitlisted'
+			   OR (event_participants.status = 'cancelled' AND event_participants.cancelled_by = event_participants.member_id)
+		`, eventID, memberID)
+		if err != nil {
+			return false, false, err
+		}
+		if tag.RowsAffected() == 1 {
+			return true, true, nil
+		}
+	}
+
+	var attended bool
+	err = r.db.QueryRow(ctx, `
+		SELECT EXISTS(SELECT 1 FROM event_participants WHERE event_id = $1 AND member_id = $2 AND status = 'attended')
+	`, eventID, memberID).Scan(&attended)
+	if err != nil {
+		return false, false, err
+	}
+	if !attended {
+		return false, false, pgx.ErrNoRows
+	}
+	return false, false, nil
+}
@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/sdyn/backend/internal/models"
+)
+
+// UpdateWithStatus saves the event's fields and moves it from one status to
+// another in one transaction, so nothing is saved if the status change
+// fails. It returns pgx.ErrNoRows if the event is not in status from, e.g.
+// because the scheduler changed it meanwhile.
+func (r *EventRepository) UpdateWithStatus(ctx context.Context, event *models.Event, from, to models.EventStatus) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// Fields first so a cancellation notice has the new title
+	if err := updateEvent(ctx, tx, event); err != nil {
+		return err
+	}
+	if err := changeStatus(ctx, tx, event.ID, from, to); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// changeStatus moves the event from one status to another. Completing an
+// event marks registered participants who did not check in as no_show;
+// cancelling it notifies registered and waitlisted participants.
+func changeStatus(ctx context.Context, tx pgx.Tx, eventID uuid.UUID, from, to models.EventStatus) error {
+	var title string
+	err := tx.QueryRow(ctx, `
+		UPDATE events SET status = $3, updated_at = NOW()
+		WHERE id = $1 AND status = $2
+		RETURNING title
+	`, eventID, from, to).Scan(&title)
+	if err != nil {
+		return err
+	}
+
+	switch to {
+	case models.EventStatusCompleted:
+		if err := markNoShows(ctx, tx, []uuid.UUID{eventID}); err != nil {
+			return err
+		}
+	case models.EventStatusCancelled:
+		_, err := tx.Exec(ctx, `
+			INSERT INTO member_notifications (member_id, type, title, body, event_id)
+			SELECT member_id, $2, $3, $4, event_id
+			FROM event_participants
+			WHERE event_id = $1 AND status IN ('registered', 'waitlisted')
+		`, eventID, models.NotificationEventCancelled, "Event cancelled", `"`+title+`" has been cancelled`)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// StartDue moves planned events whose start has passed to ongoing and
+// returns how many were started
+func (r *EventRepository) StartDue(ctx context.Context, now time.Time) (int64, error) {
+	tag, err := r.db.Exec(ctx, `
+		UPDATE events SET status = 'ongoing', updated_at = NOW()
+		WHERE status = 'planned' AND start_date <= $1
+	`, now)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// CompleteDue completes ongoing events that have ended and marks their
+// no-shows. Events without an end date end defaultDuration after the
+// start. It returns how many events were completed.
+func (r *EventRepository) CompleteDue(ctx context.Context, now time.Time, defaultDuration time.Duration) (int, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, `
+		UPDATE events SET status = 'completed', updated_at = NOW()
+		WHERE status = 'ongoing'
+		  AND COALESCE(end_date, start_date + $2::bigint * interval '1 second') <= $1
+		RETURNING id
+	`, now, int64(defaultDuration/time.Second))
+	if err != nil {
+		return 0, err
+	}
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	if err := markNoShows(ctx, tx, ids); err != nil {
+		return 0, err
+	}
+	return len(ids), tx.Commit(ctx)
+}
+
+// markNoShows sets registered participants of the events to no_show. Both
+// hold a seat, so the waitlist does not move.
+func markNoShows(ctx context.Context, tx pgx.Tx, eventIDs []uuid.UUID) error {
+	_, err := tx.Exec(ctx, `
+		UPDATE event_participants SET status = 'no_show'
+		WHERE event_id = ANY($1) AND status = 'registered'
+	`, eventIDs)
+	return err
+}
@@ -0,0 +1,269 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/sdyn/backend/internal/models"
+)
+
+type EventSeriesRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewEventSeriesRepository(db *pgxpool.Pool) *EventSeriesRepository {
+	return &EventSeriesRepository{db: db}
+}
+
+const seriesSelect = `
+	SELECT id, organization_id, title, description, type, status, location, address,
+		   is_online, online_url, max_participants, is_public, cover_image_url, organizer_id,
+		   recurrence, start_date, duration_minutes, timezone, generated_until, created_at, updated_at
+	FROM event_series
+`
+
+func scanSeries(row pgx.Row) (*models.EventSeries, error) {
+	var s models.EventSeries
+	err := row.Scan(
+		&s.ID, &s.OrganizationID, &s.Title, &s.Description, &s.Type, &s.Status, &s.Location, &s.Address,
+		&s.IsOnline, &s.OnlineURL, &s.MaxParticipants, &s.IsPublic, &s.CoverImageURL, &s.OrganizerID,
+		&s.Recurrence, &s.StartDate, &s.DurationMinutes, &s.Timezone, &s.GeneratedUntil, &s.CreatedAt, &s.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *EventSeriesRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.EventSeries, error) {
+	return scanSeries(r.db.QueryRow(ctx, seriesSelect+" WHERE id = $1", id))
+}
+
+// ListDue returns the series whose occurrences have not been generated up
+// to horizon
+func (r *EventSeriesRepository) ListDue(ctx context.Context, horizon time.Time) ([]models.EventSeries, error) {
+	rows, err := r.db.Query(ctx, seriesSelect+" WHERE generated_until IS NULL OR generated_until < $1", horizon)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	series := []models.EventSeries{}
+	for rows.Next() {
+		s, err := scanSeries(rows)
+		if err != nil {
+			return nil, err
+		}
+		series = append(series, *s)
+	}
+	return series, rows.Err()
+}
+
+func (r *EventSeriesRepository) Create(ctx context.Context, s *models.EventSeries) error {
+	return r.db.QueryRow(ctx, `
+		INSERT INTO event_series (
+			organization_id, title, description, type, status, location, address,
+			is_online, online_url, max_participants, is_public, cover_image_url, organizer_id,
+			recurrence, start_date, duration_minutes, timezone
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+		RETURNING id, created_at, updated_at
+	`,
+		s.OrganizationID, s.Title, s.Description, s.Type, s.Status, s.Location, s.Address,
+		s.IsOnline, s.OnlineURL, s.MaxParticipants, s.IsPublic, s.CoverImageURL, s.OrganizerID,
+		s.Recurrence, s.StartDate, s.DurationMinutes, s.Timezone,
+	).Scan(&s.ID, &s.CreatedAt, &s.UpdatedAt)
+}
+
+// Occurrences returns the events of the series by start date
+func (r *EventSeriesRepository) Occurrences(ctx context.Context, seriesID uuid.UUID) ([]models.Event, error) {
+	query := `
+		SELECT e.*, o.name as organization_name
+		FROM events e
+		JOIN event_series_occurrences so ON so.event_id = e.id
+		LEFT JOIN organizations o ON e.organization_id = o.id
+		WHERE so.series_id = $1
+		ORDER BY e.start_date
+	`
+
+	rows, err := r.db.Query(ctx, query, seriesID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []models.Event{}
+	for rows.Next() {
+		var e models.Event
+		err := rows.Scan(
+			&e.ID, &e.OrganizationID, &e.Title, &e.Description, &e.Type, &e.Status,
+			&e.StartDate, &e.EndDate, &e.Location, &e.Address, &e.IsOnline, &e.OnlineURL,
+			&e.MaxParticipants, &e.RegistrationDeadline, &e.IsPublic, &e.CoverImageURL,
+			&e.OrganizerID, &e.CreatedAt, &e.UpdatedAt,
+			&e.OrganizationName,
+		)
+		if err != nil {
+			return nil, err
+		}
+		e.SeriesID = &seriesID
+		events = append(events, e)
+	}
+
+	return events, rows.Err()
+}
+
+// GetOccurrence returns the series link of an event, or pgx.ErrNoRows if
+// the event is not part of a series
+func (r *EventSeriesRepository) GetOccurrence(ctx context.Context, eventID uuid.UUID) (*models.EventOccurrence, error) {
+	var o models.EventOccurrence
+	err := r.db.QueryRow(ctx, `
+		SELECT series_id, event_id, original_start, detached
+		FROM event_series_occurrences WHERE event_id = $1
+	`, eventID).Scan(&o.SeriesID, &o.EventID, &o.OriginalStart, &o.Detached)
+	if err != nil {
+		return nil, err
+	}
+	return &o, nil
+}
+
+// AddOccurrences creates the events for the given starts of the series and
+// records that occurrences up to generatedUntil exist. Starts that were
+// generated before, including deleted occurrences, are skipped. It returns
+// the number of events created.
+func (r *EventSeriesRepository) AddOccurrences(ctx context.Context, s *models.EventSeries, starts []time.Time, generatedUntil time.Time) (int, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	created := 0
+	for _, start := range starts {
+		tag, err := tx.Exec(ctx, `
+			INSERT INTO event_series_occurrences (series_id, original_start)
+			VALUES ($1, $2)
+			ON CONFLICT (series_id, original_start) DO NOTHING
+		`, s.ID, start)
+		if err != nil {
+			return 0, err
+		}
+		if tag.RowsAffected() == 0 {
+			continue
+		}
+
+		var end *time.Time
+		if s.DurationMinutes != nil {
+			t := start.Add(time.Duration(*s.DurationMinutes) * time.Minute)
+			end = &t
+		}
+
+		var eventID uuid.UUID
+		err = tx.QueryRow(ctx, `
+			INSERT INTO events (
+				organization_id, title, description, type, status, start_date, end_date,
+				location, address, is_online, online_url, max_participants,
+				is_public, cover_image_url, organizer_id
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+			RETURNING id
+		`,
+			s.OrganizationID, s.Title, s.Description, s.Type, s.Status, start, end,
+			s.Location, s.Address, s.IsOnline, s.OnlineURL, s.MaxParticipants,
+			s.IsPublic, s.CoverImageURL, s.OrganizerID,
+		).Scan(&eventID)
+		if err != nil {
+			return 0, err
+		}
+
+		_, err = tx.Exec(ctx, `
+			UPDATE event_series_occurrences SET event_id = $3 WHERE series_id = $1 AND original_start = $2
+		`, s.ID, start, eventID)
+		if err != nil {
+			return 0, err
+		}
+		created++
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE event_series SET generated_until = GREATEST(COALESCE(generated_until, $2), $2) WHERE id = $1
+	`, s.ID, generatedUntil)
+	if err != nil {
+		return 0, err
+	}
+
+	return created, tx.Commit(ctx)
+}
+
+// Detach marks an event's occurrence as edited on its own. Events outside a
+// series are ignored.
+func (r *EventSeriesRepository) Detach(ctx context.Context, eventID uuid.UUID) error {
+	_, err := r.db.Exec(ctx, "UPDATE event_series_occurrences SET detached = true WHERE event_id = $1", eventID)
+	return err
+}
+
+// UpdateFuture saves the series template and applies it to the occurrence
+// eventID, whose original start is from, and to every later occurrence
+// that is not detached. Their starts move by shift from the original start;
+// the series start, the occurrence keys and the generation horizon move
+// with them so the rule stays in step.
+func (r *EventSeriesRepository) UpdateFuture(ctx context.Context, s *models.EventSeries, eventID uuid.UUID, from time.Time, shift time.Duration) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	seconds := int64(shift / time.Second)
+
+	err = tx.QueryRow(ctx, `
+		UPDATE event_series SET
+			title = $2, description = $3, type = $4, location = $5, address = $6,
+			is_online = $7, online_url = $8, max_participants = $9, is_public = $10,
+			cover_image_url = $11, duration_minutes = $12,
+			start_date = start_date + $13::bigint * interval '1 second',
+			generated_until = generated_until + $13::bigint * interval '1 second',
+			updated_at = NOW()
+		WHERE id = $1
+		RETURNING start_date, generated_until, updated_at
+	`,
+		s.ID, s.Title, s.Description, s.Type, s.Location, s.Address,
+		s.IsOnline, s.OnlineURL, s.MaxParticipants, s.IsPublic,
+		s.CoverImageURL, s.DurationMinutes, seconds,
+	).Scan(&s.StartDate, &s.GeneratedUntil, &s.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE events e SET
+			title = $4, description = $5, type = $6, location = $7, address = $8,
+			is_online = $9, online_url = $10, max_participants = $11, is_public = $12,
+			cover_image_url = $13,
+			start_date = so.original_start + $14::bigint * interval '1 second',
+			end_date = so.original_start + $14::bigint * interval '1 second' + $15::int * interval '1 minute'
+		FROM event_series_occurrences so
+		WHERE so.event_id = e.id AND so.series_id = $1 AND so.original_start >= $2
+		  AND (NOT so.detached OR e.id = $3)
+	`,
+		s.ID, from, eventID, s.Title, s.Description, s.Type, s.Location, s.Address,
+		s.IsOnline, s.OnlineURL, s.MaxParticipants, s.IsPublic,
+		s.CoverImageURL, seconds, s.DurationMinutes,
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE event_series_occurrences
+		SET original_start = original_start + $3::bigint * interval '1 second',
+			detached = CASE WHEN event_id = $4 THEN false ELSE detached END
+		WHERE series_id = $1 AND original_start >= $2
+	`, s.ID, from, seconds, eventID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
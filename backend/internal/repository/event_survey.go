@@ -0,0 +1,282 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/sdyn/backend/internal/models"
+)
+
+// surveyTextLimit caps the text answers returned per question
+const surveyTextLimit = 200
+
+// GetSurvey returns the event's survey questions in order and the number of
+// responses. An event without a survey has no questions.
+func (r *EventRepository) GetSurvey(ctx context.Context, eventID uuid.UUID) (*models.EventSurvey, error) {
+	survey := &models.EventSurvey{EventID: eventID, Questions: []models.SurveyQuestion{}}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT id, event_id, position, type, prompt, options, required
+		FROM event_survey_questions
+		WHERE event_id = $1
+		ORDER BY position
+	`, eventID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var q models.SurveyQuestion
+		if err := rows.Scan(&q.ID, &q.EventID, &q.Position, &q.Type, &q.Prompt, &q.Options, &q.Required); err != nil {
+			return nil, err
+		}
+		survey.Questions = append(survey.Questions, q)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	err = r.db.QueryRow(ctx, "SELECT COUNT(*) FROM event_survey_responses WHERE event_id = $1", eventID).Scan(&survey.Responses)
+	if err != nil {
+		return nil, err
+	}
+	return survey, nil
+}
+
+// ReplaceSurvey replaces the event's survey questions and sets their IDs.
+// It returns pgx.ErrNoRows if the event does not exist and
+// models.ErrSurveyAnswered once the survey has responses.
+func (r *EventRepository) ReplaceSurvey(ctx context.Context, eventID uuid.UUID, questions []models.SurveyQuestion) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// Submissions take a share lock on the event, so none can slip in
+	var answered bool
+	err = tx.QueryRow(ctx, `
+		SELECT EXISTS(SELECT 1 FROM event_survey_responses WHERE event_id = e.id)
+		FROM events e WHERE e.id = $1
+		FOR UPDATE
+	`, eventID).Scan(&answered)
+	if err != nil {
+		return err
+	}
+	if answered {
+		return models.ErrSurveyAnswered
+	}
+
+	if _, err := tx.Exec(ctx, "DELETE FROM event_survey_questions WHERE event_id = $1", eventID); err != nil {
+		return err
+	}
+	for i := range questions {
+		q := &questions[i]
+		q.EventID = eventID
+		err := tx.QueryRow(ctx, `
+			INSERT INTO event_survey_questions (event_id, position, type, prompt, options, required)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			RETURNING id
+		`, eventID, q.Position, q.Type, q.Prompt, q.Options, q.Required).Scan(&q.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// SubmitSurvey saves a member's survey answers. It returns pgx.ErrNoRows if
+// the member did not attend the event and models.ErrSurveySubmitted if they
+// already submitted.
+func (r *EventRepository) SubmitSurvey(ctx context.Context, eventID, memberID uuid.UUID, answers []models.SurveyAnswer) (*models.SurveyResponse, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var attended bool
+	err = tx.QueryRow(ctx, `
+		SELECT EXISTS(
+			SELECT 1 FROM event_participants
+			WHERE event_id = e.id AND member_id = $2 AND status = 'attended'
+		)
+		FROM events e WHERE e.id = $1
+		FOR SHARE
+	`, eventID, memberID).Scan(&attended)
+	if err != nil {
+		return nil, err
+	}
+	if !attended {
+		return nil, pgx.ErrNoRows
+	}
+
+	response := &models.SurveyResponse{EventID: eventID, MemberID: memberID, Answers: answers}
+	err = tx.QueryRow(ctx, `
+		INSERT INTO event_survey_responses (event_id, member_id)
+		VALUES ($1, $2)
+		ON CONFLICT (event_id, member_id) DO NOTHING
+		RETURNING id, submitted_at
+	`, eventID, memberID).Scan(&response.ID, &response.SubmittedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, models.ErrSurveySubmitted
+		}
+		return nil, err
+	}
+
+	for _, a := range answers {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO event_survey_answers (response_id, question_id, rating, choice, text)
+			VALUES ($1, $2, $3, $4, $5)
+		`, response.ID, a.QuestionID, a.Rating, a.Choice, a.Text)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return response, tx.Commit(ctx)
+}
+
+// SurveyResults aggregates the answers to the event's survey. The number
+// of attendees is counted for the response rate.
+func (r *EventRepository) SurveyResults(ctx context.Context, eventID uuid.UUID) (*models.SurveyResults, error) {
+	survey, err := r.GetSurvey(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	results := &models.SurveyResults{
+		EventID:   eventID,
+		Responses: survey.Responses,
+		Questions: make([]models.SurveyQuestionResult, len(survey.Questions)),
+	}
+	byID := map[uuid.UUID]*models.SurveyQuestionResult{}
+	for i, q := range survey.Questions {
+		results.Questions[i] = models.SurveyQuestionResult{SurveyQuestion: q}
+		byID[q.ID] = &results.Questions[i]
+	}
+
+	err = r.db.QueryRow(ctx, `
+		SELECT COUNT(*) FROM event_participants WHERE event_id = $1 AND status = 'attended'
+	`, eventID).Scan(&results.Attended)
+	if err != nil {
+		return nil, err
+	}
+	if results.Attended > 0 {
+		results.ResponseRate = float64(results.Responses) / float64(results.Attended) * 100
+	}
+
+	// Ratings and choices
+	rows, err := r.db.Query(ctx, `
+		SELECT a.question_id, a.rating, a.choice, COUNT(*)
+		FROM event_survey_answers a
+		JOIN event_survey_questions q ON q.id = a.question_id
+		WHERE q.event_id = $1 AND a.text IS NULL
+		GROUP BY a.question_id, a.rating, a.choice
+	`, eventID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ratingSums := map[uuid.UUID]int{}
+	for rows.Next() {
+		var questionID uuid.UUID
+		var rating *int
+		var choice *string
+		var count int
+		if err := rows.Scan(&questionID, &rating, &choice, &count); err != nil {
+			return nil, err
+		}
+		q, ok := byID[questionID]
+		if !ok {
+			continue
+		}
+		q.Answers += count
+		switch {
+		case rating != nil:
+			if q.Ratings == nil {
+				q.Ratings = map[int]int{}
+			}
+			q.Ratings[*rating] += count
+			ratingSums[questionID] += *rating * count
+		case choice != nil:
+			if q.Choices == nil {
+				q.Choices = map[string]int{}
+			}
+			q.Choices[*choice] += count
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	for id, sum := range ratingSums {
+		q := byID[id]
+		average := float64(sum) / float64(q.Answers)
+		q.AverageRating = &average
+	}
+
+	// Latest text answers
+	textRows, err := r.db.Query(ctx, `
+		SELECT question_id, text FROM (
+			SELECT a.question_id, a.text,
+				   ROW_NUMBER() OVER (PARTITION BY a.question_id ORDER BY r.submitted_at DESC) as n,
+				   COUNT(*) OVER (PARTITION BY a.question_id) as total
+			FROM event_survey_answers a
+			JOIN event_survey_responses r ON r.id = a.response_id
+			WHERE r.event_id = $1 AND a.text IS NOT NULL
+		) t
+		WHERE n <= $2
+		ORDER BY question_id, n
+	`, eventID, surveyTextLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer textRows.Close()
+
+	for textRows.Next() {
+		var questionID uuid.UUID
+		var text string
+		if err := textRows.Scan(&questionID, &text); err != nil {
+			return nil, err
+		}
+		if q, ok := byID[questionID]; ok {
+			q.Texts = append(q.Texts, text)
+		}
+	}
+	if err := textRows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Text answers are counted separately since only the latest are listed
+	countRows, err := r.db.Query(ctx, `
+		SELECT a.question_id, COUNT(*)
+		FROM event_survey_answers a
+		JOIN event_survey_questions q ON q.id = a.question_id
+		WHERE q.event_id = $1 AND a.text IS NOT NULL
+		GROUP BY a.question_id
+	`, eventID)
+	if err != nil {
+		return nil, err
+	}
+	defer countRows.Close()
+
+	for countRows.Next() {
+		var questionID uuid.UUID
+		var count int
+		if err := countRows.Scan(&questionID, &count); err != nil {
+			return nil, err
+		}
+		if q, ok := byID[questionID]; ok {
+			q.Answers += count
+		}
+	}
+
+	return results, countRows.Err()
+}
@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/sdyn/backend/internal/models"
+)
+
+type ExportJobRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewExportJobRepository(db *pgxpool.Pool) *ExportJobRepository {
+	return &ExportJobRepository{db: db}
+}
+
+const exportJobColumns = `
+	id, requested_by, params, status, object_key, file_name, file_size,
+	error_message, attempts, created_at, started_at, completed_at
+`
+
+func scanExportJob(row pgx.Row) (*models.ExportJob, error) {
+	var job models.ExportJob
+	var params []byte
+	err := row.Scan(
+		&job.ID, &job.RequestedBy, &params, &job.Status, &job.ObjectKey, &job.FileName, &job.FileSize,
+		&job.ErrorMessage, &job.Attempts, &job.CreatedAt, &job.StartedAt, &job.CompletedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(params, &job.Params); err != nil {
+		return nil, err
+	}
+
+	return &job, nil
+}
+
+func (r *ExportJobRepository) Create(ctx context.Context, requestedBy string, params *models.ExportParams) (*models.ExportJob, error) {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		INSERT INTO export_jobs (requested_by, type, format, params)
+		VALUES ($1, $2, $3, $4)
+		RETURNING ` + exportJobColumns
+
+	return scanExportJob(r.db.QueryRow(ctx, query, requestedBy, params.Type, params.Format, data))
+}
+
+func (r *ExportJobRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.ExportJob, error) {
+	query := `SELECT ` + exportJobColumns + ` FROM export_jobs WHERE id = $1`
+	return scanExportJob(r.db.QueryRow(ctx, query, id))
+}
+
+// ClaimNext marks the oldest pending job as processing and returns it. It
+// returns pgx.ErrNoRows when the queue is empty. SKIP LOCKED lets several
+// API instances run workers against the same table.
+func (r *ExportJobRepository) ClaimNext(ctx context.Context) (*models.ExportJob, error) {
+	query := `
+		UPDATE export_jobs
+		SET status = 'processing', started_at = NOW(), attempts = attempts + 1
+		WHERE id = (
+			SELECT id FROM export_jobs
+			WHERE status = 'pending'
+			ORDER BY created_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + exportJobColumns
+
+	return scanExportJob(r.db.QueryRow(ctx, query))
+}
+
+func (r *ExportJobRepository) MarkCompleted(ctx context.Context, id uuid.UUID, objectKey, fileName string, fileSize int64) error {
+	query := `
+		UPDATE export_jobs
+		SET status = 'completed', object_key = $2, file_name = $3, file_size = $4,
+			error_message = NULL, completed_at = NOW()
+		WHERE id = $1
+	`
+	_, err := r.db.Exec(ctx, query, id, objectKey, fileName, fileSize)
+	return err
+}
+
+func (r *ExportJobRepository) MarkFailed(ctx context.Context, id uuid.UUID, message string) error {
+	query := `
+		UPDATE export_jobs
+		SET status = 'failed', error_message = $2, completed_at = NOW()
+		WHERE id = $1
+	`
+	_, err := r.db.Exec(ctx, query, id, message)
+	return err
+}
+
+// RequeueStale puts jobs that have been processing for longer than the given
+// interval back in the queue, e.g. after the server was restarted mid-export.
+// Jobs that already used up maxAttempts are failed instead.
+func (r *ExportJobRepository) RequeueStale(ctx context.Context, olderThan string, maxAttempts int) (int64, error) {
+	query := `
+		UPDATE export_jobs
+		SET status = CASE WHEN attempts >= $2 THEN 'failed' ELSE 'pending' END,
+			error_message = CASE WHEN attempts >= $2 THEN 'Export was interrupted too many times' ELSE error_message END,
+			completed_at = CASE WHEN attempts >= $2 THEN NOW() ELSE completed_at END
+		WHERE status = 'processing' AND started_at < NOW() - $1::interval
+	`
+	tag, err := r.db.Exec(ctx, query, olderThan, maxAttempts)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
@@ -2,8 +2,11 @@ package repository
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/sdyn/backend/internal/models"
@@ -17,38 +20,151 @@ func NewFeeRepository(db *pgxpool.Pool) *FeeRepository {
 	return &FeeRepository{db: db}
 }
 
-func (r *FeeRepository) List(ctx context.Context, params *models.FeeListParams) ([]models.MembershipFee, error) {
-	query := `
-		SELECT f.*, (m.first_name || ' ' || m.last_name) as member_name, m.member_id as member_mid,
-			   m.email as member_email, m.phone as member_phone, o.name as organization
+// feeOrders are the sort_by options of the fee list. "period" is the
+// billing period, newest first by default.
+var feeOrders = map[string][]keysetColumn{
+	"period":     {{"f.year", "int"}, {"COALESCE(f.month, 0)", "int"}, {"f.created_at", "timestamptz"}},
+	"paid_at":    {{"COALESCE(f.paid_at, '-infinity')", "timestamptz"}},
+	"created_at": {{"f.created_at", "timestamptz"}},
+	"amount":     {{"f.amount", "numeric"}},
+}
+
+func (r *FeeRepository) List(ctx context.Context, params *models.FeeListParams) (*models.FeeListResponse, error) {
+	from := `
 		FROM membership_fees f
 		JOIN members m ON f.member_id = m.id
 		LEFT JOIN organizations o ON m.organization_id = o.id
-		ORDER BY f.year DESC, f.month DESC, f.created_at DESC
+		WHERE 1=1
 	`
+	where := ""
+	args := []interface{}{}
+	argCount := 0
+
+	if params.MemberID != nil {
+		argCount++
+		where += fmt.Sprintf(" AND f.member_id = $%d", argCount)
+		args = append(args, *params.MemberID)
+	}
+
+	if params.Year != nil {
+		argCount++
+		where += fmt.Sprintf(" AND f.year = $%d", argCount)
+		args = append(args, *params.Year)
+	}
+
+	if params.Month != nil {
+		argCount++
+		where += fmt.Sprintf(" AND f.month = $%d", argCount)
+		args = append(args, *params.Month)
+	}
+
+	if params.Status != nil {
+		argCount++
+		where += fmt.Sprintf(" AND f.status = $%d", argCount)
+		args = append(args, *params.Status)
+	}
+
+	if params.OrganizationID != nil {
+		argCount++
+		where += fmt.Sprintf(" AND m.organization_id = $%d", argCount)
+		args = append(args, *params.OrganizationID)
+	}
+
+	if params.ProvinceID != nil {
+		argCount++
+		where += fmt.Sprintf(" AND m.province_id = $%d", argCount)
+		args = append(args, *params.ProvinceID)
+	}
+
+	if params.DistrictID != nil {
+		argCount++
+		where += fmt.Sprintf(" AND m.district_id = $%d", argCount)
+		args = append(args, *params.DistrictID)
+	}
+
+	if params.PaidFrom != nil {
+		argCount++
+		where += fmt.Sprintf(" AND f.paid_at >= $%d", argCount)
+		args = append(args, *params.PaidFrom)
+	}
+
+	if params.PaidTo != nil {
+		argCount++
+		where += fmt.Sprintf(" AND f.paid_at < ($%d::date + interval '1 day')", argCount)
+		args = append(args, *params.PaidTo)
+	}
+
+	// Counting is opt-in here; these lists never returned a total
+	totalMode := params.Total
+	if totalMode == "" {
+		totalMode = models.TotalNone
+	}
+	total, err := countRows(ctx, r.db, totalMode, from+where, args)
+	if err != nil {
+		return nil, err
+	}
+
+	order := newKeysetOrder(feeOrders, params.SortBy, "period", params.SortOrder, "f.id", true)
+
+	var cursor *models.Cursor
+	offset := 0
+	if params.Cursor != "" {
+		if cursor, err = models.DecodeCursor(params.Cursor); err != nil {
+			return nil, err
+		}
+		condition, err := order.condition(cursor, &argCount, &args)
+		if err != nil {
+			return nil, err
+		}
+		where += condition
+	} else if params.Page > 1 {
+		offset = (params.Page - 1) * params.Limit
+	}
+
+	query := `
+		SELECT f.*, (m.first_name || ' ' || m.last_name) as member_name, m.member_id as member_mid,
+			   m.email as member_email, m.phone as member_phone, o.name as organization, ` +
+		order.selectKey() + from + where + order.orderBy(cursor != nil && cursor.Prev) +
+		fmt.Sprintf(" LIMIT $%d OFFSET $%d", argCount+1, argCount+2)
+	args = append(args, params.Limit+1, offset)
 
-	rows, err := r.db.Query(ctx, query)
+	rows, err := r.db.Query(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
 	fees := []models.MembershipFee{}
+	keys := []keysetKey{}
 	for rows.Next() {
 		var f models.MembershipFee
+		var key keysetKey
 		err := rows.Scan(
 			&f.ID, &f.MemberID, &f.Year, &f.Month, &f.Amount, &f.Status,
 			&f.PaidAt, &f.PaymentMethod, &f.ReceiptNumber, &f.Notes,
 			&f.CreatedAt, &f.UpdatedAt,
 			&f.MemberName, &f.MemberMID, &f.MemberEmail, &f.MemberPhone, &f.Organization,
+			&key.Values,
 		)
 		if err != nil {
 			return nil, err
 		}
+		key.ID = f.ID
 		fees = append(fees, f)
+		keys = append(keys, key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
 	}
 
-	return fees, nil
+	result := &models.FeeListResponse{
+		Total:            total,
+		TotalApproximate: totalMode == models.TotalApprox,
+		Limit:            params.Limit,
+	}
+	result.Fees, result.NextCursor, result.PrevCursor = keysetPage(order, fees, keys, params.Limit, cursor, offset)
+
+	return result, nil
 }
 
 func (r *FeeRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.MembershipFee, error) {
@@ -75,46 +191,171 @@ func (r *FeeRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Memb
 	return &f, nil
 }
 
-func (r *FeeRepository) Create(ctx context.Context, fee *models.MembershipFee) (*models.MembershipFee, error) {
-	query := `
-		INSERT INTO membership_fees (member_id, year, month, amount, status, payment_method, receipt_number, notes)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-		RETURNING id, created_at, updated_at
-	`
+const feeInsertQuery = `
+	INSERT INTO membership_fees (member_id, year, month, amount, status, paid_at, payment_method, receipt_number, notes)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	RETURNING id, created_at, updated_at
+`
 
-	err := r.db.QueryRow(ctx, query,
-		fee.MemberID, fee.Year, fee.Month, fee.Amount, fee.Status,
+func insertFee(ctx context.Context, q rowQuerier, fee *models.MembershipFee) error {
+	return q.QueryRow(ctx, feeInsertQuery,
+		fee.MemberID, fee.Year, fee.Month, fee.Amount, fee.Status, fee.PaidAt,
 		fee.PaymentMethod, fee.ReceiptNumber, fee.Notes,
 	).Scan(&fee.ID, &fee.CreatedAt, &fee.UpdatedAt)
+}
 
-	if err != nil {
+func (r *FeeRepository) Create(ctx context.Context, fee *models.MembershipFee) (*models.MembershipFee, error) {
+	if err := insertFee(ctx, r.db, fee); err != nil {
 		return nil, err
 	}
 
 	return fee, nil
 }
 
-func (r *FeeRepository) Update(ctx context.Context, fee *models.MembershipFee) (*models.MembershipFee, error) {
-	query := `
-		UPDATE membership_fees SET
-			amount = $2, status = $3, paid_at = $4,
-			payment_method = $5, receipt_number = $6, notes = $7
-		WHERE id = $1
-		RETURNING updated_at
-	`
+const feeUpdateQuery = `
+	UPDATE membership_fees SET
+		amount = $2, status = $3, paid_at = $4,
+		payment_method = $5, receipt_number = $6, notes = $7
+	WHERE id = $1
+	RETURNING updated_at
+`
 
-	err := r.db.QueryRow(ctx, query,
+func updateFee(ctx context.Context, q rowQuerier, fee *models.MembershipFee) error {
+	return q.QueryRow(ctx, feeUpdateQuery,
 		fee.ID, fee.Amount, fee.Status, fee.PaidAt,
 		fee.PaymentMethod, fee.ReceiptNumber, fee.Notes,
 	).Scan(&fee.UpdatedAt)
+}
 
+func (r *FeeRepository) Update(ctx context.Context, fee *models.MembershipFee) (*models.MembershipFee, error) {
+	if err := updateFee(ctx, r.db, fee); err != nil {
+		return nil, err
+	}
+
+	return fee, nil
+}
+
+// CreatePaid inserts a paid fee and renews the member's membership by
+// termMonths in one transaction
+func (r *FeeRepository) CreatePaid(ctx context.Context, fee *models.MembershipFee, termMonths int) (*models.MembershipFee, error) {
+	tx, err := r.db.Begin(ctx)
 	if err != nil {
 		return nil, err
 	}
+	defer tx.Rollback(ctx)
+
+	if err := insertFee(ctx, tx, fee); err != nil {
+		return nil, err
+	}
+	if err := renewMembership(ctx, tx, fee, termMonths); err != nil {
+		return nil, err
+	}
 
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
 	return fee, nil
 }
 
+// UpdatePaid saves a fee that is marked paid. The membership is renewed by
+// termMonths only if the fee was not already paid, so repeated updates do
+// not extend it twice.
+func (r *FeeRepository) UpdatePaid(ctx context.Context, fee *models.MembershipFee, termMonths int) (*models.MembershipFee, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var previous models.PaymentStatus
+	err = tx.QueryRow(ctx, "SELECT status FROM membership_fees WHERE id = $1 FOR UPDATE", fee.ID).Scan(&previous)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := updateFee(ctx, tx, fee); err != nil {
+		return nil, err
+	}
+	if previous != models.PaymentStatusPaid {
+		if err := renewMembership(ctx, tx, fee, termMonths); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return fee, nil
+}
+
+// renewMembership extends the member's membership by termMonths, counted
+// from the current expiry or from the payment date if membership has
+// already lapsed. A member made inactive by expiry is reactivated. Both
+// changes are written to the member's history.
+func renewMembership(ctx context.Context, tx pgx.Tx, fee *models.MembershipFee, termMonths int) error {
+	var oldExpiry *time.Time
+	var newExpiry time.Time
+	var oldStatus, newStatus models.MemberStatus
+	err := tx.QueryRow(ctx, `
+		WITH old AS (
+			SELECT id, membership_expires_at, status FROM members WHERE id = $1 FOR UPDATE
+		)
+		UPDATE members m
+		SET membership_expires_at = GREATEST(COALESCE(old.membership_expires_at, $2), $2) + make_interval(months => $3),
+			status = CASE
+				WHEN old.status = 'inactive' AND (
+					SELECT h.action FROM member_history h
+					WHERE h.member_id = old.id AND h.action IN ('status_change', 'approved', 'rejected', 'membership_expired')
+					ORDER BY h.created_at DESC LIMIT 1
+				) = 'membership_expired' THEN 'active'
+				ELSE old.status
+			END
+		FROM old
+		WHERE m.id = old.id
+		RETURNING old.membership_expires_at, m.membership_expires_at, old.status, m.status
+	`, fee.MemberID, *fee.PaidAt, termMonths).Scan(&oldExpiry, &newExpiry, &oldStatus, &newStatus)
+	if err != nil {
+		return err
+	}
+
+	period := fmt.Sprintf("%d", fee.Year)
+	if fee.Month != nil {
+		period = fmt.Sprintf("%d-%02d", fee.Year, *fee.Month)
+	}
+	reason := "Fee paid for " + period
+
+	newValue := newExpiry.Format("2006-01-02")
+	history := &models.MemberHistory{
+		MemberID: fee.MemberID,
+		Action:   "membership_renewed",
+		NewValue: &newValue,
+		Reason:   &reason,
+	}
+	if oldExpiry != nil {
+		oldValue := oldExpiry.Format("2006-01-02")
+		history.OldValue = &oldValue
+	}
+	if err := insertHistory(ctx, tx, history); err != nil {
+		return err
+	}
+
+	if oldStatus != newStatus {
+		oldValue, newValue := string(oldStatus), string(newStatus)
+		err := insertHistory(ctx, tx, &models.MemberHistory{
+			MemberID: fee.MemberID,
+			Action:   "status_change",
+			OldValue: &oldValue,
+			NewValue: &newValue,
+			Reason:   &reason,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (r *FeeRepository) GetByMember(ctx context.Context, memberID uuid.UUID) ([]models.MembershipFee, error) {
 	query := `
 		SELECT id, member_id, year, month, amount, status, paid_at, payment_method, receipt_number, notes, created_at, updated_at
@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type ImageRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewImageRepository(db *pgxpool.Pool) *ImageRepository {
+	return &ImageRepository{db: db}
+}
+
+// Referenced returns which of urls are still used as a member avatar or an
+// event cover
+func (r *ImageRepository) Referenced(ctx context.Context, urls []string) (map[string]bool, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT avatar_url FROM members WHERE avatar_url = ANY($1)
+		UNION
+		SELECT cover_image_url FROM events WHERE cover_image_url = ANY($1)
+	`, urls)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	referenced := make(map[string]bool)
+	for rows.Next() {
+		var url string
+		if err := rows.Scan(&url); err != nil {
+			return nil, err
+		}
+		referenced[url] = true
+	}
+
+	return referenced, rows.Err()
+}
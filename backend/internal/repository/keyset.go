@@ -0,0 +1,191 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/sdyn/backend/internal/models"
+)
+
+// keysetColumn is one sort expression of a keyset order. Expr must never be
+// NULL (wrap nullable columns in COALESCE) and Type is its Postgres type,
+// used to cast cursor values back.
+type keysetColumn struct {
+	Expr string
+	Type string
+}
+
+// keysetOrder is a stable list order: the sort columns followed by the row
+// id as a tie breaker, all in the same direction, so a page boundary can be
+// expressed as a single row comparison.
+type keysetOrder struct {
+	Name    string
+	Columns []keysetColumn
+	IDExpr  string
+	Desc    bool
+}
+
+// newKeysetOrder looks up sort in orders, falling back to def, and applies
+// the requested direction. Unknown sort names are rejected by request
+// validation before they get here.
+func newKeysetOrder(orders map[string][]keysetColumn, sort, def, sortOrder string, idExpr string, defaultDesc bool) *keysetOrder {
+	columns, ok := orders[sort]
+	if !ok {
+		sort = def
+		columns = orders[def]
+	}
+	desc := defaultDesc
+	switch sortOrder {
+	case "asc":
+		desc = false
+	case "desc":
+		desc = true
+	}
+	return &keysetOrder{Name: sort, Columns: columns, IDExpr: idExpr, Desc: desc}
+}
+
+// cursorName identifies the order a cursor was issued for
+func (k *keysetOrder) cursorName() string {
+	if k.Desc {
+		return k.Name + ":desc"
+	}
+	return k.Name + ":asc"
+}
+
+// selectKey returns the select-list expression for the row's sort key
+func (k *keysetOrder) selectKey() string {
+	exprs := make([]string, len(k.Columns))
+	for i, col := range k.Columns {
+		exprs[i] = fmt.Sprintf("(%s)::text", col.Expr)
+	}
+	return "ARRAY[" + strings.Join(exprs, ", ") + "]::text[]"
+}
+
+// condition returns the WHERE condition selecting the rows after the cursor
+// row (or before it for a Prev cursor) and appends its arguments
+func (k *keysetOrder) condition(c *models.Cursor, argCount *int, args *[]interface{}) (string, error) {
+	if c.Sort != k.cursorName() || len(c.Values) != len(k.Columns) {
+		return "", models.ErrInvalidCursor
+	}
+
+	left := make([]string, 0, len(k.Columns)+1)
+	right := make([]string, 0, len(k.Columns)+1)
+	for i, col := range k.Columns {
+		*argCount++
+		left = append(left, col.Expr)
+		right = append(right, fmt.Sprintf("$%d::text::%s", *argCount, col.Type))
+		*args = append(*args, c.Values[i])
+	}
+	*argCount++
+	left = append(left, k.IDExpr)
+	right = append(right, fmt.Sprintf("$%d", *argCount))
+	*args = append(*args, c.ID)
+
+	op := ">"
+	if k.Desc != c.Prev {
+		op = "<"
+	}
+	return fmt.Sprintf(" AND (%s) %s (%s)", strings.Join(left, ", "), op, strings.Join(right, ", ")), nil
+}
+
+// orderBy returns the ORDER BY clause, reversed when reading a page
+// backwards
+func (k *keysetOrder) orderBy(reverse bool) string {
+	dir := "ASC"
+	if k.Desc != reverse {
+		dir = "DESC"
+	}
+	parts := make([]string, 0, len(k.Columns)+1)
+	for _, col := range k.Columns {
+		parts = append(parts, col.Expr+" "+dir)
+	}
+	parts = append(parts, k.IDExpr+" "+dir)
+	return " ORDER BY " + strings.Join(parts, ", ")
+}
+
+// keysetKey is the sort key and id of a fetched row
+type keysetKey struct {
+	Values []string
+	ID     uuid.UUID
+}
+
+// keysetPage trims the extra row fetched to detect another page, restores
+// the display order of a backwards page, and returns the cursors of the
+// neighbouring pages. c is the cursor the page was read from (nil for the
+// first page) and offset is the legacy page offset.
+func keysetPage[T any](k *keysetOrder, items []T, keys []keysetKey, limit int, c *models.Cursor, offset int) ([]T, *string, *string) {
+	backwards := c != nil && c.Prev
+	more := len(items) > limit
+	if more {
+		items, keys = items[:limit], keys[:limit]
+	}
+	if backwards {
+		for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+			items[i], items[j] = items[j], items[i]
+			keys[i], keys[j] = keys[j], keys[i]
+		}
+	}
+	if len(items) == 0 {
+		return items, nil, nil
+	}
+
+	cursorAt := func(key keysetKey, prev bool) *string {
+		s := (&models.Cursor{Sort: k.cursorName(), Values: key.Values, ID: key.ID, Prev: prev}).Encode()
+		return &s
+	}
+
+	var next, prev *string
+	hasNext := more
+	hasPrev := c != nil || offset > 0
+	if backwards {
+		// Reading backwards, the extra row means there are earlier pages;
+		// the page we came from is always after this one
+		hasNext, hasPrev = true, more
+	}
+	if hasNext {
+		next = cursorAt(keys[len(keys)-1], false)
+	}
+	if hasPrev {
+		prev = cursorAt(keys[0], true)
+	}
+	return items, next, prev
+}
+
+// countRows counts the rows of fromWhere (a "FROM ... WHERE ..." clause)
+// according to mode. It returns nil for models.TotalNone.
+func countRows(ctx context.Context, db *pgxpool.Pool, mode models.TotalMode, fromWhere string, args []interface{}) (*int, error) {
+	switch mode {
+	case models.TotalNone:
+		return nil, nil
+	case models.TotalApprox:
+		// The planner's estimate of the scan, without running it
+		var plan []struct {
+			Plan struct {
+				Rows float64 `json:"Plan Rows"`
+			} `json:"Plan"`
+		}
+		var raw []byte
+		if err := db.QueryRow(ctx, "EXPLAIN (FORMAT JSON) SELECT 1 "+fromWhere, args...).Scan(&raw); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(raw, &plan); err != nil {
+			return nil, err
+		}
+		if len(plan) == 0 {
+			return nil, fmt.Errorf("empty EXPLAIN output")
+		}
+		total := int(plan[0].Plan.Rows)
+		return &total, nil
+	default:
+		var total int
+		if err := db.QueryRow(ctx, "SELECT COUNT(*) "+fromWhere, args...).Scan(&total); err != nil {
+			return nil, err
+		}
+		return &total, nil
+	}
+}
@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sdyn/backend/internal/models"
+)
+
+var testOrders = map[string][]keysetColumn{
+	"created_at": {{"m.created_at", "timestamptz"}},
+	"status":     {{"m.status::text", "text"}, {"m.created_at", "timestamptz"}},
+}
+
+func TestKeysetOrderCondition(t *testing.T) {
+	order := newKeysetOrder(testOrders, "status", "created_at", "", "m.id", true)
+	id := uuid.New()
+
+	argCount := 2
+	args := []interface{}{"a", "b"}
+	condition, err := order.condition(&models.Cursor{Sort: "status:desc", Values: []string{"active", "2024-01-01"}, ID: id}, &argCount, &args)
+	require.NoError(t, err)
+	assert.Equal(t, " AND (m.status::text, m.created_at, m.id) < ($3::text::text, $4::text::timestamptz, $5)", condition)
+	assert.Equal(t, []interface{}{"a", "b", "active", "2024-01-01", id}, args)
+	assert.Equal(t, 5, argCount)
+
+	// Paging backwards flips the comparison and the order
+	argCount, args = 0, nil
+	condition, err = order.condition(&models.Cursor{Sort: "status:desc", Values: []string{"active", "2024-01-01"}, ID: id, Prev: true}, &argCount, &args)
+	require.NoError(t, err)
+	assert.Contains(t, condition, ") > (")
+	assert.Equal(t, " ORDER BY m.status::text ASC, m.created_at ASC, m.id ASC", order.orderBy(true))
+	assert.Equal(t, " ORDER BY m.status::text DESC, m.created_at DESC, m.id DESC", order.orderBy(false))
+}
+
+func TestKeysetOrderRejectsForeignCursor(t *testing.T) {
+	order := newKeysetOrder(testOrders, "created_at", "created_at", "asc", "m.id", true)
+	argCount := 0
+	args := []interface{}{}
+
+	_, err := order.condition(&models.Cursor{Sort: "created_at:desc", Values: []string{"x"}, ID: uuid.New()}, &argCount, &args)
+	assert.ErrorIs(t, err, models.ErrInvalidCursor)
+
+	_, err = order.condition(&models.Cursor{Sort: "created_at:asc", Values: []string{"x", "y"}, ID: uuid.New()}, &argCount, &args)
+	assert.ErrorIs(t, err, models.ErrInvalidCursor)
+}
+
+func TestNewKeysetOrderFallsBackToDefault(t *testing.T) {
+	order := newKeysetOrder(testOrders, "nope", "created_at", "", "m.id", true)
+	assert.Equal(t, "created_at", order.Name)
+	assert.True(t, order.Desc)
+}
+
+func TestKeysetPage(t *testing.T) {
+	order := newKeysetOrder(testOrders, "created_at", "created_at", "", "m.id", true)
+	keys := make([]keysetKey, 4)
+	for i := range keys {
+		keys[i] = keysetKey{Values: []string{string(rune('a' + i))}, ID: uuid.New()}
+	}
+	items := []int{1, 2, 3, 4}
+
+	t.Run("first page with more", func(t *testing.T) {
+		page, next, prev := keysetPage(order, append([]int{}, items...), append([]keysetKey{}, keys...), 3, nil, 0)
+		assert.Equal(t, []int{1, 2, 3}, page)
+		require.NotNil(t, next)
+		assert.Nil(t, prev)
+
+		c, err := models.DecodeCursor(*next)
+		require.NoError(t, err)
+		assert.Equal(t, keys[2].ID, c.ID)
+		assert.Equal(t, "created_at:desc", c.Sort)
+		assert.False(t, c.Prev)
+	})
+
+	t.Run("last page from a cursor", func(t *testing.T) {
+		page, next, prev := keysetPage(order, items[:2], keys[:2], 3, &models.Cursor{}, 0)
+		assert.Equal(t, []int{1, 2}, page)
+		assert.Nil(t, next)
+		require.NotNil(t, prev)
+
+		c, err := models.DecodeCursor(*prev)
+		require.NoError(t, err)
+		assert.Equal(t, keys[0].ID, c.ID)
+		assert.True(t, c.Prev)
+	})
+
+	t.Run("backwards page is reversed", func(t *testing.T) {
+		page, next, prev := keysetPage(order, []int{3, 2, 1}, []keysetKey{keys[2], keys[1], keys[0]}, 3, &models.Cursor{Prev: true}, 0)
+		assert.Equal(t, []int{1, 2, 3}, page)
+		assert.NotNil(t, next)
+		assert.Nil(t, prev)
+	})
+
+	t.Run("empty page", func(t *testing.T) {
+		page, next, prev := keysetPage(order, []int{}, []keysetKey{}, 3, &models.Cursor{}, 0)
+		assert.Empty(t, page)
+		assert.Nil(t, next)
+		assert.Nil(t, prev)
+	})
+}
+
+func TestDecodeCursorRejectsGarbage(t *testing.T) {
+	for _, s := range []string{"", "not base64!", "e30"} {
+		_, err := models.DecodeCursor(s)
+		assert.ErrorIs(t, err, models.ErrInvalidCursor, s)
+	}
+}
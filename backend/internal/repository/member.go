@@ -2,9 +2,12 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/sdyn/backend/internal/models"
@@ -18,83 +21,163 @@ func NewMemberRepository(db *pgxpool.Pool) *MemberRepository {
 	return &MemberRepository{db: db}
 }
 
-func (r *MemberRepository) List(ctx context.Context, params *models.MemberListParams) (*models.MemberListResponse, error) {
-	offset := (params.Page - 1) * params.Limit
+// memberSearchKey and memberSearchVector match the expressions of the
+// member search indexes (migration 007), so they must not be changed alone
+const (
+	memberSearchKey    = "member_search_key(m.first_name, m.last_name, m.member_id, m.phone, m.email, m.workplace)"
+	memberSearchVector = "to_tsvector('simple', " + memberSearchKey + ")"
+)
 
-	baseQuery := `
-		SELECT m.*, p.name as province_name, d.name as district_name, o.name as organization_name
+// memberOrders are the sort_by options of the member list. Nullable
+// columns are coalesced so the order stays total.
+var memberOrders = map[string][]keysetColumn{
+	"created_at":            {{"m.created_at", "timestamptz"}},
+	"updated_at":            {{"m.updated_at", "timestamptz"}},
+	"joined_at":             {{"COALESCE(m.joined_at, '-infinity')", "timestamptz"}},
+	"membership_expires_at": {{"COALESCE(m.membership_expires_at, 'infinity')", "timestamptz"}},
+	"first_name":            {{"m.first_name", "text"}},
+	"last_name":             {{"m.last_name", "text"}},
+	"member_id":             {{"m.member_id", "text"}},
+	"status":                {{"m.status::text", "text"}, {"m.created_at", "timestamptz"}},
+}
+
+func (r *MemberRepository) List(ctx context.Context, params *models.MemberListParams) (*models.MemberListResponse, error) {
+	from := `
 		FROM members m
 		LEFT JOIN provinces p ON m.province_id = p.id
 		LEFT JOIN districts d ON m.district_id = d.id
 		LEFT JOIN organizations o ON m.organization_id = o.id
 		WHERE 1=1
 	`
-	countQuery := "SELECT COUNT(*) FROM members m WHERE 1=1"
+	where := ""
 	args := []interface{}{}
 	argCount := 0
 
 	// Add filters
+	rankExpr := ""
 	if params.Search != "" {
 		argCount++
-		filter := fmt.Sprintf(" AND (m.first_name ILIKE $%d OR m.last_name ILIKE $%d OR m.email ILIKE $%d OR m.member_id ILIKE $%d)", argCount, argCount, argCount, argCount)
-		baseQuery += filter
-		countQuery += filter
-		args = append(args, "%"+params.Search+"%")
+		switch params.SearchMode {
+		case models.SearchModeFullText:
+			where += fmt.Sprintf(" AND %s @@ plainto_tsquery('simple', member_search_translit($%d))", memberSearchVector, argCount)
+			rankExpr = fmt.Sprintf("ts_rank(%s, plainto_tsquery('simple', member_search_translit($%d)))::float8", memberSearchVector, argCount)
+			args = append(args, params.Search)
+		case models.SearchModeFuzzy:
+			where += fmt.Sprintf(" AND (%s @@ plainto_tsquery('simple', member_search_translit($%d)) OR member_search_translit($%d) <%% %s)",
+				memberSearchVector, argCount, argCount, memberSearchKey)
+			// Whole-word hits outrank fuzzy ones; word_similarity is 0-1
+			rankExpr = fmt.Sprintf("(ts_rank(%s, plainto_tsquery('simple', member_search_translit($%d))) + word_similarity(member_search_translit($%d), %s))::float8",
+				memberSearchVector, argCount, argCount, memberSearchKey)
+			args = append(args, params.Search)
+		default:
+			where += fmt.Sprintf(" AND (m.first_name ILIKE $%d OR m.last_name ILIKE $%d OR m.email ILIKE $%d OR m.member_id ILIKE $%d)", argCount, argCount, argCount, argCount)
+			args = append(args, "%"+params.Search+"%")
+		}
 	}
 
 	if params.Status != nil {
 		argCount++
-		filter := fmt.Sprintf(" AND m.status = $%d", argCount)
-		baseQuery += filter
-		countQuery += filter
+		where += fmt.Sprintf(" AND m.status = $%d", argCount)
 		args = append(args, *params.Status)
 	}
 
 	if params.OrganizationID != nil {
 		argCount++
-		filter := fmt.Sprintf(" AND m.organization_id = $%d", argCount)
-		baseQuery += filter
-		countQuery += filter
+		where += fmt.Sprintf(" AND m.organization_id = $%d", argCount)
 		args = append(args, *params.OrganizationID)
 	}
 
 	if params.ProvinceID != nil {
 		argCount++
-		filter := fmt.Sprintf(" AND m.province_id = $%d", argCount)
-		baseQuery += filter
-		countQuery += filter
+		where += fmt.Sprintf(" AND m.province_id = $%d", argCount)
 		args = append(args, *params.ProvinceID)
 	}
 
-	// Get total count
-	var total int
-	err := r.db.QueryRow(ctx, countQuery, args...).Scan(&total)
+	if params.DistrictID != nil {
+		argCount++
+		where += fmt.Sprintf(" AND m.district_id = $%d", argCount)
+		args = append(args, *params.DistrictID)
+	}
+
+	if params.CreatedFrom != nil {
+		argCount++
+		where += fmt.Sprintf(" AND m.created_at >= $%d", argCount)
+		args = append(args, *params.CreatedFrom)
+	}
+
+	if params.CreatedTo != nil {
+		argCount++
+		where += fmt.Sprintf(" AND m.created_at < ($%d::date + interval '1 day')", argCount)
+		args = append(args, *params.CreatedTo)
+	}
+
+	if params.ExpiresFrom != nil {
+		argCount++
+		where += fmt.Sprintf(" AND m.membership_expires_at >= $%d", argCount)
+		args = append(args, *params.ExpiresFrom)
+	}
+
+	if params.ExpiresTo != nil {
+		argCount++
+		where += fmt.Sprintf(" AND m.membership_expires_at < $%d", argCount)
+		args = append(args, *params.ExpiresTo)
+	}
+
+	// Get total count (members only, the joins don't change it)
+	totalMode := params.Total
+	if totalMode == "" {
+		totalMode = models.TotalExact
+	}
+	total, err := countRows(ctx, r.db, totalMode, "FROM members m WHERE 1=1"+where, args)
 	if err != nil {
 		return nil, err
 	}
 
-	// Add sorting and pagination
-	sortBy := "m.created_at"
-	if params.SortBy != "" {
-		sortBy = "m." + params.SortBy
+	// Ranked searches show the best matches first unless a sort is requested
+	order := newKeysetOrder(memberOrders, params.SortBy, "created_at", params.SortOrder, "m.id", true)
+	if rankExpr != "" && params.SortBy == "" {
+		order = &keysetOrder{
+			Name:    "relevance",
+			Columns: []keysetColumn{{rankExpr, "float8"}, {"m.created_at", "timestamptz"}},
+			IDExpr:  "m.id",
+			Desc:    true,
+		}
 	}
-	sortOrder := "DESC"
-	if params.SortOrder == "asc" {
-		sortOrder = "ASC"
+
+	// Keyset pagination from a cursor; page is still honoured with OFFSET
+	// for clients that have not moved to cursors
+	var cursor *models.Cursor
+	offset := 0
+	if params.Cursor != "" {
+		if cursor, err = models.DecodeCursor(params.Cursor); err != nil {
+			return nil, err
+		}
+		condition, err := order.condition(cursor, &argCount, &args)
+		if err != nil {
+			return nil, err
+		}
+		where += condition
+	} else if params.Page > 1 {
+		offset = (params.Page - 1) * params.Limit
 	}
 
-	baseQuery += fmt.Sprintf(" ORDER BY %s %s LIMIT $%d OFFSET $%d", sortBy, sortOrder, argCount+1, argCount+2)
-	args = append(args, params.Limit, offset)
+	query := `
+		SELECT m.*, p.name as province_name, d.name as district_name, o.name as organization_name, ` +
+		order.selectKey() + from + where + order.orderBy(cursor != nil && cursor.Prev) +
+		fmt.Sprintf(" LIMIT $%d OFFSET $%d", argCount+1, argCount+2)
+	args = append(args, params.Limit+1, offset)
 
-	rows, err := r.db.Query(ctx, baseQuery, args...)
+	rows, err := r.db.Query(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
 	members := []models.Member{}
+	keys := []keysetKey{}
 	for rows.Next() {
 		var m models.Member
+		var key keysetKey
 		err := rows.Scan(
 			&m.ID, &m.MemberID, &m.KeycloakID, &m.OrganizationID,
 			&m.FirstName, &m.LastName, &m.Gender, &m.BirthDate, &m.NationalID,
@@ -104,22 +187,31 @@ func (r *MemberRepository) List(ctx context.Context, params *models.MemberListPa
 			&m.AvatarURL, &m.Bio, &m.ReferredBy, &m.Notes,
 			&m.CreatedAt, &m.UpdatedAt,
 			&m.ProvinceName, &m.DistrictName, &m.OrganizationName,
+			&key.Values,
 		)
 		if err != nil {
 			return nil, err
 		}
+		key.ID = m.ID
 		members = append(members, m)
+		keys = append(keys, key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
 	}
 
-	totalPages := (total + params.Limit - 1) / params.Limit
+	result := &models.MemberListResponse{
+		Page:  params.Page,
+		Limit: params.Limit,
+	}
+	result.Members, result.NextCursor, result.PrevCursor = keysetPage(order, members, keys, params.Limit, cursor, offset)
+	if total != nil {
+		result.Total = *total
+		result.TotalPages = (*total + params.Limit - 1) / params.Limit
+		result.TotalApproximate = totalMode == models.TotalApprox
+	}
 
-	return &models.MemberListResponse{
-		Members:    members,
-		Total:      total,
-		Page:       params.Page,
-		Limit:      params.Limit,
-		TotalPages: totalPages,
-	}, nil
+	return result, nil
 }
 
 func (r *MemberRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Member, error) {
@@ -170,30 +262,153 @@ func (r *MemberRepository) GetByEmail(ctx context.Context, email string) (*model
 	return &m, nil
 }
 
-func (r *MemberRepository) Create(ctx context.Context, member *models.Member) (*models.Member, error) {
-	query := `
-		INSERT INTO members (
-			first_name, last_name, gender, birth_date, national_id,
-			email, phone, address, province_id, district_id, organization_id,
-			education, occupation, workplace, status, referred_by, notes
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
-		RETURNING id, member_id, created_at, updated_at
-	`
+const memberInsertQuery = `
+	INSERT INTO members (
+		first_name, last_name, gender, birth_date, national_id,
+		email, phone, address, province_id, district_id, organization_id,
+		education, occupation, workplace, status, referred_by, notes
+	) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+	RETURNING id, member_id, created_at, updated_at
+`
 
-	err := r.db.QueryRow(ctx, query,
+// rowQuerier is implemented by both *pgxpool.Pool and pgx.Tx
+type rowQuerier interface {
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+}
+
+func insertMember(ctx context.Context, q rowQuerier, member *models.Member) error {
+	return q.QueryRow(ctx, memberInsertQuery,
 		member.FirstName, member.LastName, member.Gender, member.BirthDate, member.NationalID,
 		member.Email, member.Phone, member.Address, member.ProvinceID, member.DistrictID, member.OrganizationID,
 		member.Education, member.Occupation, member.Workplace, member.Status, member.ReferredBy, member.Notes,
 	).Scan(&member.ID, &member.MemberID, &member.CreatedAt, &member.UpdatedAt)
+}
 
-	if err != nil {
+func (r *MemberRepository) Create(ctx context.Context, member *models.Member) (*models.Member, error) {
+	if err := insertMember(ctx, r.db, member); err != nil {
 		return nil, err
 	}
 
 	return member, nil
 }
 
-func (r *MemberRepository) Update(ctx context.Context, member *models.Member) (*models.Member, error) {
+// CreateBatch inserts all members in a single transaction. Either every
+// member is created or none are.
+func (r *MemberRepository) CreateBatch(ctx context.Context, members []*models.Member) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	for i, member := range members {
+		if err := insertMember(ctx, tx, member); err != nil {
+			return fmt.Errorf("member %d: %w", i+1, err)
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// FindExistingIdentifiers returns which of the given emails, phones and
+// national IDs already belong to a member. Emails and national IDs are
+// compared case-insensitively and must be passed in lower and upper case
+// respectively.
+func (r *MemberRepository) FindExistingIdentifiers(ctx context.Context, emails, phones, nationalIDs []string) (existingEmails, existingPhones, existingNationalIDs map[string]bool, err error) {
+	existingEmails = map[string]bool{}
+	existingPhones = map[string]bool{}
+	existingNationalIDs = map[string]bool{}
+
+	if len(emails) == 0 && len(phones) == 0 && len(nationalIDs) == 0 {
+		return existingEmails, existingPhones, existingNationalIDs, nil
+	}
+
+	query := `
+		SELECT lower(email), phone, upper(national_id)
+		FROM members
+		WHERE lower(email) = ANY($1) OR phone = ANY($2) OR upper(national_id) = ANY($3)
+	`
+
+	rows, err := r.db.Query(ctx, query, emails, phones, nationalIDs)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var email, phone, nationalID *string
+		if err := rows.Scan(&email, &phone, &nationalID); err != nil {
+			return nil, nil, nil, err
+		}
+		if email != nil {
+			existingEmails[*email] = true
+		}
+		if phone != nil {
+			existingPhones[*phone] = true
+		}
+		if nationalID != nil {
+			existingNationalIDs[*nationalID] = true
+		}
+	}
+
+	return existingEmails, existingPhones, existingNationalIDs, rows.Err()
+}
+
+// FindPlacements returns the province of each of the districts and the
+// province and district of each of the organizations. Unknown IDs are
+// missing from the maps.
+func (r *MemberRepository) FindPlacements(ctx context.Context, districtIDs, organizationIDs []uuid.UUID) (districtProvinces map[uuid.UUID]uuid.UUID, organizations map[uuid.UUID]models.Organization, err error) {
+	districtProvinces = map[uuid.UUID]uuid.UUID{}
+	organizations = map[uuid.UUID]models.Organization{}
+
+	if len(districtIDs) > 0 {
+		rows, err := r.db.Query(ctx, "SELECT id, province_id FROM districts WHERE id = ANY($1)", districtIDs)
+		if err != nil {
+			return nil, nil, err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var id, provinceID uuid.UUID
+			if err := rows.Scan(&id, &provinceID); err != nil {
+				return nil, nil, err
+			}
+			districtProvinces[id] = provinceID
+		}
+		if err := rows.Err(); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if len(organizationIDs) > 0 {
+		rows, err := r.db.Query(ctx, "SELECT id, province_id, district_id FROM organizations WHERE id = ANY($1)", organizationIDs)
+		if err != nil {
+			return nil, nil, err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var org models.Organization
+			if err := rows.Scan(&org.ID, &org.ProvinceID, &org.DistrictID); err != nil {
+				return nil, nil, err
+			}
+			organizations[org.ID] = org
+		}
+		if err := rows.Err(); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return districtProvinces, organizations, nil
+}
+
+// Update saves member and, when history is not nil, writes its history row
+// and drops the verifications of the changed fields in the same transaction
+func (r *MemberRepository) Update(ctx context.Context, member *models.Member, history *models.MemberHistory) (*models.Member, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
 	query := `
 		UPDATE members SET
 			first_name = $2, last_name = $3, gender = $4, birth_date = $5, national_id = $6,
@@ -204,7 +419,7 @@ func (r *MemberRepository) Update(ctx context.Context, member *models.Member) (*
 		RETURNING updated_at
 	`
 
-	err := r.db.QueryRow(ctx, query,
+	err = tx.QueryRow(ctx, query,
 		member.ID,
 		member.FirstName, member.LastName, member.Gender, member.BirthDate, member.NationalID,
 		member.Email, member.Phone, member.Address, member.ProvinceID, member.DistrictID, member.OrganizationID,
@@ -216,29 +431,185 @@ func (r *MemberRepository) Update(ctx context.Context, member *models.Member) (*
 		return nil, err
 	}
 
+	if history != nil {
+		history.MemberID = member.ID
+		if err := insertHistory(ctx, tx, history); err != nil {
+			return nil, err
+		}
+		if err := clearVerifications(ctx, tx, member.ID, history.Changes); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
 	return member, nil
 }
 
+// UpdateStatus moves a member from status from to status to and writes the
+// history row in one transaction. Any open suspension is lifted, and a new
+// one ending at suspendedUntil (nil for indefinite) is opened when
+// suspending. Leaving pending closes the member's open application. It
+// returns pgx.ErrNoRows if the member is no longer in status from.
+func (r *MemberRepository) UpdateStatus(ctx context.Context, id uuid.UUID, from, to models.MemberStatus, suspendedUntil *time.Time, history *models.MemberHistory) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `
+		UPDATE members
+		SET status = $3,
+			joined_at = CASE WHEN $4 THEN COALESCE(joined_at, NOW()) ELSE joined_at END
+		WHERE id = $1 AND status = $2
+	`, id, from, to, to == models.MemberStatusActive)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE member_suspensions SET lifted_at = NOW(), lifted_by = $2
+		WHERE member_id = $1 AND lifted_at IS NULL
+	`, id, history.ChangedBy)
+	if err != nil {
+		return err
+	}
+
+	if to == models.MemberStatusSuspended {
+		_, err = tx.Exec(ctx, `
+			INSERT INTO member_suspensions (member_id, reason, suspended_by, ends_at)
+			VALUES ($1, $2, $3, $4)
+		`, id, history.Reason, history.ChangedBy, suspendedUntil)
+		if err != nil {
+			return err
+		}
+	}
+
+	if from == models.MemberStatusPending {
+		decision := models.ApprovalStatusRejected
+		if to == models.MemberStatusActive {
+			decision = models.ApprovalStatusApproved
+		}
+		_, err = tx.Exec(ctx, `
+			UPDATE membership_applications
+			SET status = $2, decided_at = NOW(), decided_by = $3, decision_reason = $4, updated_at = NOW()
+			WHERE member_id = $1 AND status = 'pending'
+		`, id, decision, history.ChangedBy, history.Reason)
+		if err != nil {
+			return err
+		}
+	}
+
+	history.MemberID = id
+	if err := insertHistory(ctx, tx, history); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// ReinstateSuspended returns members whose suspension has ended to active
+// and records the change in their history. It returns the number of
+// members reinstated.
+func (r *MemberRepository) ReinstateSuspended(ctx context.Context) (int64, error) {
+	// Lifting the suspension row first makes concurrent runs skip it
+	tag, err := r.db.Exec(ctx, `
+		WITH ended AS (
+			UPDATE member_suspensions SET lifted_at = NOW()
+			WHERE lifted_at IS NULL AND ends_at <= NOW()
+			RETURNING member_id
+		), reinstated AS (
+			UPDATE members m SET status = 'active'
+			FROM ended
+			WHERE m.id = ended.member_id AND m.status = 'suspended'
+			RETURNING m.id
+		)
+		INSERT INTO member_history (member_id, action, old_value, new_value, reason)
+		SELECT id, 'status_change', 'suspended', 'active', 'Suspension ended'
+		FROM reinstated
+	`)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// ExpireMemberships moves active members whose membership has expired to
+// inactive and records the change in their history. It returns the number
+// of members expired.
+func (r *MemberRepository) ExpireMemberships(ctx context.Context) (int64, error) {
+	tag, err := r.db.Exec(ctx, `
+		WITH expired AS (
+			UPDATE members SET status = 'inactive'
+			WHERE status = 'active' AND membership_expires_at < NOW()
+			RETURNING id, membership_expires_at
+		)
+		INSERT INTO member_history (member_id, action, old_value, new_value, reason)
+		SELECT id, 'membership_expired', 'active', 'inactive',
+			'Membership expired on ' || to_char(membership_expires_at, 'YYYY-MM-DD')
+		FROM expired
+	`)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
 func (r *MemberRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	_, err := r.db.Exec(ctx, "DELETE FROM members WHERE id = $1", id)
 	return err
 }
 
 func (r *MemberRepository) CreateHistory(ctx context.Context, history *models.MemberHistory) error {
+	changes, err := historyChanges(history)
+	if err != nil {
+		return err
+	}
+
 	query := `
-		INSERT INTO member_history (member_id, action, old_value, new_value, changed_by, reason)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO member_history (member_id, action, old_value, new_value, changed_by, reason, changes)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
 	`
-	_, err := r.db.Exec(ctx, query, history.MemberID, history.Action, history.OldValue, history.NewValue, history.ChangedBy, history.Reason)
+	_, err = r.db.Exec(ctx, query, history.MemberID, history.Action, history.OldValue, history.NewValue, history.ChangedBy, history.Reason, changes)
 	return err
 }
 
+// insertHistory writes a member_history row as part of a transaction
+func insertHistory(ctx context.Context, tx pgx.Tx, history *models.MemberHistory) error {
+	changes, err := historyChanges(history)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO member_history (member_id, action, old_value, new_value, changed_by, reason, changes)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`, history.MemberID, history.Action, history.OldValue, history.NewValue, history.ChangedBy, history.Reason, changes)
+	return err
+}
+
+// historyChanges encodes the field diff of a history row, or nil (SQL NULL)
+// when there is none
+func historyChanges(history *models.MemberHistory) ([]byte, error) {
+	if len(history.Changes) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(history.Changes)
+}
+
+// GetHistory returns the member's history, newest first
 func (r *MemberRepository) GetHistory(ctx context.Context, memberID uuid.UUID) ([]models.MemberHistory, error) {
 	query := `
-		SELECT id, member_id, action, old_value, new_value, changed_by, reason, created_at
+		SELECT id, member_id, action, old_value, new_value, changed_by, reason, created_at, changes
 		FROM member_history
 		WHERE member_id = $1
-		ORDER BY created_at DESC
+		ORDER BY created_at DESC, id DESC
 	`
 
 	rows, err := r.db.Query(ctx, query, memberID)
@@ -250,14 +621,20 @@ func (r *MemberRepository) GetHistory(ctx context.Context, memberID uuid.UUID) (
 	history := []models.MemberHistory{}
 	for rows.Next() {
 		var h models.MemberHistory
-		err := rows.Scan(&h.ID, &h.MemberID, &h.Action, &h.OldValue, &h.NewValue, &h.ChangedBy, &h.Reason, &h.CreatedAt)
+		var changes []byte
+		err := rows.Scan(&h.ID, &h.MemberID, &h.Action, &h.OldValue, &h.NewValue, &h.ChangedBy, &h.Reason, &h.CreatedAt, &changes)
 		if err != nil {
 			return nil, err
 		}
+		if changes != nil {
+			if err := json.Unmarshal(changes, &h.Changes); err != nil {
+				return nil, err
+			}
+		}
 		history = append(history, h)
 	}
 
-	return history, nil
+	return history, rows.Err()
 }
 
 func (r *MemberRepository) GetReport(ctx context.Context, orgID string) (map[string]interface{}, error) {
@@ -411,3 +788,293 @@ func (r *MemberRepository) GetReport(ctx context.Context, orgID string) (map[str
 
 	return report, nil
 }
+
+// FindDuplicateCandidates returns pairs of members that share an email,
+// phone number, national ID or full name, normalized the same way the
+// duplicate scoring in MemberService does. Scoring the pairs is left to the
+// caller.
+func (r *MemberRepository) FindDuplicateCandidates(ctx context.Context, params *models.DuplicateListParams, limit int) ([][2]models.Member, error) {
+	scoped := `SELECT m.id, m.member_id, m.organization_id, m.first_name, m.last_name, m.birth_date,
+			m.national_id, m.email, m.phone, m.status, m.created_at, o.name as organization_name,
+			right(regexp_replace(m.phone, '[^0-9]', '', 'g'), 8) as phone_key,
+			regexp_replace(lower(m.first_name), '[^[:alpha:]]', '', 'g') as first_key,
+			regexp_replace(lower(m.last_name), '[^[:alpha:]]', '', 'g') as last_key
+		FROM members m
+		LEFT JOIN organizations o ON m.organization_id = o.id
+		WHERE 1=1`
+	args := []interface{}{}
+	argCount := 0
+
+	if params.OrganizationID != nil {
+		argCount++
+		scoped += fmt.Sprintf(" AND m.organization_id = $%d", argCount)
+		args = append(args, *params.OrganizationID)
+	}
+
+	if params.ProvinceID != nil {
+		argCount++
+		scoped += fmt.Sprintf(" AND m.province_id = $%d", argCount)
+		args = append(args, *params.ProvinceID)
+	}
+
+	if params.DistrictID != nil {
+		argCount++
+		scoped += fmt.Sprintf(" AND m.district_id = $%d", argCount)
+		args = append(args, *params.DistrictID)
+	}
+
+	query := `
+		WITH scoped AS (` + scoped + `)
+		SELECT a.id, a.member_id, a.organization_id, a.first_name, a.last_name, a.birth_date,
+			a.national_id, a.email, a.phone, a.status, a.created_at, a.organization_name,
+			b.id, b.member_id, b.organization_id, b.first_name, b.last_name, b.birth_date,
+			b.national_id, b.email, b.phone, b.status, b.created_at, b.organization_name
+		FROM scoped a
+		JOIN scoped b ON a.id < b.id AND (
+			lower(a.email) = lower(b.email)
+			OR upper(a.national_id) = upper(b.national_id)
+			OR (a.phone_key = b.phone_key AND a.phone_key != '')
+			OR (a.first_key != '' AND a.last_key != '' AND (
+				(a.first_key = b.first_key AND a.last_key = b.last_key)
+				OR (a.first_key = b.last_key AND a.last_key = b.first_key)
+			))
+		)
+		ORDER BY a.created_at DESC
+	` + fmt.Sprintf("LIMIT $%d", argCount+1)
+	args = append(args, limit)
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	pairs := [][2]models.Member{}
+	for rows.Next() {
+		var a, b models.Member
+		err := rows.Scan(
+			&a.ID, &a.MemberID, &a.OrganizationID, &a.FirstName, &a.LastName, &a.BirthDate,
+			&a.NationalID, &a.Email, &a.Phone, &a.Status, &a.CreatedAt, &a.OrganizationName,
+			&b.ID, &b.MemberID, &b.OrganizationID, &b.FirstName, &b.LastName, &b.BirthDate,
+			&b.NationalID, &b.Email, &b.Phone, &b.Status, &b.CreatedAt, &b.OrganizationName,
+		)
+		if err != nil {
+			return nil, err
+		}
+		pairs = append(pairs, [2]models.Member{a, b})
+	}
+
+	return pairs, rows.Err()
+}
+
+// memberMergeStep says what Merge does with the duplicate's rows in a
+// column holding a member ID
+type memberMergeStep struct {
+	table  string
+	column string
+	// drop leaves the duplicate's rows to be deleted with it instead of
+	// moving them to the survivor
+	drop bool
+	// prepare runs before the rows are moved, e.g. to resolve rows that
+	// would conflict with the survivor's
+	prepare []string
+}
+
+// statements returns the SQL of the step, with the survivor in $1 and the
+// duplicate in $2
+func (s memberMergeStep) statements() []string {
+	if s.drop {
+		return s.prepare
+	}
+	move := fmt.Sprintf("UPDATE %s SET %s = $1 WHERE %s = $2", s.table, s.column, s.column)
+	return append(append([]string{}, s.prepare...), move)
+}
+
+// memberMergeSteps lists every column holding a member ID, in the order
+// Merge handles them. A column missing here keeps pointing at the deleted
+// duplicate or, with ON DELETE CASCADE, loses its rows.
+var memberMergeSteps = []memberMergeStep{
+	{table: "event_participants", column: "member_id", prepare: []string{
+		// Keep one row per event with the stronger status, preferring
+		// attendance
+		`UPDATE event_participants s
+		SET status = CASE
+				WHEN 'attended' IN (s.status, d.status) THEN 'attended'
+				WHEN 'registered' IN (s.status, d.status) THEN 'registered'
+				WHEN 'no_show' IN (s.status, d.status) THEN 'no_show'
+				WHEN 'waitlisted' IN (s.status, d.status) THEN 'waitlisted'
+				ELSE 'cancelled'
+			END,
+			attended_at = COALESCE(s.attended_at, d.attended_at),
+			checked_out_at = COALESCE(s.checked_out_at, d.checked_out_at),
+			hours = GREATEST(s.hours, d.hours),
+			hours_entered_by = CASE WHEN s.hours IS NULL OR d.hours > s.hours THEN d.hours_entered_by ELSE s.hours_entered_by END,
+			registered_at = LEAST(s.registered_at, d.registered_at),
+			cancelled_at = CASE WHEN s.status = 'cancelled' AND d.status = 'cancelled' THEN s.cancelled_at END,
+			cancelled_by = CASE WHEN s.status = 'cancelled' AND d.status = 'cancelled' THEN s.cancelled_by END,
+			cancellation_reason = CASE WHEN s.status = 'cancelled' AND d.status = 'cancelled' THEN s.cancellation_reason END
+		FROM event_participants d
+		WHERE s.member_id = $1 AND d.member_id = $2 AND s.event_id = d.event_id`,
+		`DELETE FROM event_participants d
+		USING event_participants s
+		WHERE d.member_id = $2 AND s.member_id = $1 AND d.event_id = s.event_id`,
+	}},
+	{table: "event_participants", column: "cancelled_by"},
+	{table: "event_participants", column: "hours_entered_by"},
+	{table: "membership_fees", column: "member_id"},
+	{table: "member_positions", column: "member_id"},
+	{table: "member_history", column: "member_id"},
+	// Documents keep their object keys under the duplicate's prefix
+	{table: "member_documents", column: "member_id"},
+	{table: "member_documents", column: "uploaded_by"},
+	{table: "member_transfers", column: "member_id", prepare: []string{
+		// The duplicate's open transfer is closed since the survivor may
+		// have one of its own
+		`UPDATE member_transfers
+		SET status = 'cancelled', decided_at = NOW(),
+			decision_reason = 'Merged into another member', updated_at = NOW()
+		WHERE member_id = $2 AND status = 'pending'`,
+	}},
+	{table: "member_transfers", column: "requested_by"},
+	{table: "member_transfers", column: "decided_by"},
+	{table: "member_suspensions", column: "member_id", prepare: []string{
+		// Only one suspension can stay open, the survivor's
+		`UPDATE member_suspensions d SET lifted_at = NOW()
+		WHERE d.member_id = $2 AND d.lifted_at IS NULL
+		  AND EXISTS (SELECT 1 FROM member_suspensions s WHERE s.member_id = $1 AND s.lifted_at IS NULL)`,
+	}},
+	{table: "member_suspensions", column: "suspended_by"},
+	{table: "member_suspensions", column: "lifted_by"},
+	{table: "membership_applications", column: "member_id", prepare: []string{
+		// The duplicate's pending application is closed so it does not come
+		// back next to the survivor's
+		`UPDATE membership_applications
+		SET status = 'rejected', decided_at = NOW(), decision_reason = 'merged', updated_at = NOW()
+		WHERE member_id = $2 AND status = 'pending'`,
+	}},
+	{table: "membership_applications", column: "escalated_by"},
+	{table: "membership_applications", column: "decided_by"},
+	{table: "member_field_verifications", column: "verified_by"},
+	{table: "member_field_verifications", column: "member_id", drop: true, prepare: []string{
+		// Verified values the survivor inherits stay verified. Profile
+		// fields are filled with COALESCE, so an inherited field is one the
+		// survivor does not have.
+		`INSERT INTO member_field_verifications (member_id, field, verified_by, verified_at, note)
+		SELECT s.id, v.field, v.verified_by, v.verified_at, v.note
+		FROM member_field_verifications v
+		JOIN members d ON d.id = v.member_id
+		JOIN members s ON s.id = $1
+		WHERE v.member_id = $2 AND ` + inheritedVerificationExpr() + `
+		ON CONFLICT (member_id, field) DO NOTHING`,
+	}},
+	{table: "events", column: "organizer_id"},
+	{table: "event_series", column: "organizer_id"},
+	{table: "member_notifications", column: "member_id"},
+	{table: "calendar_tokens", column: "member_id", prepare: []string{
+		// A subscribed calendar feed keeps working unless the survivor has
+		// its own token; then the duplicate's is revoked
+		`DELETE FROM calendar_tokens
+		WHERE member_id = $2 AND EXISTS (SELECT 1 FROM calendar_tokens WHERE member_id = $1)`,
+	}},
+	{table: "event_survey_responses", column: "member_id", prepare: []string{
+		// One response per event, keeping the survivor's
+		`DELETE FROM event_survey_responses d
+		USING event_survey_responses s
+		WHERE d.member_id = $2 AND s.member_id = $1 AND d.event_id = s.event_id`,
+	}},
+	{table: "members", column: "referred_by", prepare: []string{
+		`UPDATE members SET referred_by = NULL WHERE id = $1 AND referred_by = $2`,
+	}},
+}
+
+// Merge moves everything that belongs to the duplicate member onto the
+// survivor, fills the survivor's empty profile fields from the duplicate,
+// deletes the duplicate and records the merge in member_history, all in one
+// transaction. It returns pgx.ErrNoRows if either member does not exist.
+func (r *MemberRepository) Merge(ctx context.Context, survivorID, duplicateID uuid.UUID, history *models.MemberHistory) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var locked int
+	err = tx.QueryRow(ctx, `
+		SELECT COUNT(*) FROM (
+			SELECT id FROM members WHERE id = ANY($1) FOR UPDATE
+		) locked
+	`, []uuid.UUID{survivorID, duplicateID}).Scan(&locked)
+	if err != nil {
+		return err
+	}
+	if locked != 2 {
+		return pgx.ErrNoRows
+	}
+
+	for _, step := range memberMergeSteps {
+		for _, stmt := range step.statements() {
+			if _, err := tx.Exec(ctx, stmt, survivorID, duplicateID); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Delete the duplicate first so unique columns can move to the survivor
+	var d models.Member
+	err = tx.QueryRow(ctx, `
+		DELETE FROM members WHERE id = $1
+		RETURNING member_id, keycloak_id, organization_id, gender, birth_date, national_id,
+			email, phone, address, province_id, district_id, education, occupation, workplace,
+			joined_at, membership_expires_at, avatar_url, bio, referred_by
+	`, duplicateID).Scan(
+		&d.MemberID, &d.KeycloakID, &d.OrganizationID, &d.Gender, &d.BirthDate, &d.NationalID,
+		&d.Email, &d.Phone, &d.Address, &d.ProvinceID, &d.DistrictID, &d.Education, &d.Occupation, &d.Workplace,
+		&d.JoinedAt, &d.MembershipExpiresAt, &d.AvatarURL, &d.Bio, &d.ReferredBy,
+	)
+	if err != nil {
+		return err
+	}
+
+	if d.ReferredBy != nil && *d.ReferredBy == survivorID {
+		d.ReferredBy = nil
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE members SET
+			keycloak_id = COALESCE(keycloak_id, $2),
+			organization_id = COALESCE(organization_id, $3),
+			gender = COALESCE(gender, $4),
+			birth_date = COALESCE(birth_date, $5),
+			national_id = COALESCE(national_id, $6),
+			email = COALESCE(email, $7),
+			phone = COALESCE(phone, $8),
+			address = COALESCE(address, $9),
+			province_id = COALESCE(province_id, $10),
+			district_id = COALESCE(district_id, $11),
+			education = COALESCE(education, $12),
+			occupation = COALESCE(occupation, $13),
+			workplace = COALESCE(workplace, $14),
+			joined_at = LEAST(joined_at, $15),
+			membership_expires_at = GREATEST(membership_expires_at, $16),
+			avatar_url = COALESCE(avatar_url, $17),
+			bio = COALESCE(bio, $18),
+			referred_by = COALESCE(referred_by, $19),
+			updated_at = NOW()
+		WHERE id = $1
+	`, survivorID,
+		d.KeycloakID, d.OrganizationID, d.Gender, d.BirthDate, d.NationalID,
+		d.Email, d.Phone, d.Address, d.ProvinceID, d.DistrictID, d.Education, d.Occupation, d.Workplace,
+		d.JoinedAt, d.MembershipExpiresAt, d.AvatarURL, d.Bio, d.ReferredBy,
+	)
+	if err != nil {
+		return err
+	}
+
+	history.MemberID = survivorID
+	history.OldValue = &d.MemberID
+	if err := insertHistory(ctx, tx, history); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
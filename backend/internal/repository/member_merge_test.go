@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMemberMergeSteps pins what Merge does with each column holding a
+// member ID. A migration that adds such a column must add a row here and a
+// step to memberMergeSteps.
+func TestMemberMergeSteps(t *testing.T) {
+	tests := []struct {
+		table    string
+		column   string
+		drop     bool
+		prepared bool
+	}{
+		// Rows for the same event are combined first
+		{"event_participants", "member_id", false, true},
+		{"event_participants", "cancelled_by", false, false},
+		{"event_participants", "hours_entered_by", false, false},
+		{"membership_fees", "member_id", false, false},
+		{"member_positions", "member_id", false, false},
+		{"member_history", "member_id", false, false},
+		{"member_documents", "member_id", false, false},
+		{"member_documents", "uploaded_by", false, false},
+		// A pending transfer is cancelled first
+		{"member_transfers", "member_id", false, true},
+		{"member_transfers", "requested_by", false, false},
+		{"member_transfers", "decided_by", false, false},
+		// An open suspension is lifted if the survivor has one
+		{"member_suspensions", "member_id", false, true},
+		{"member_suspensions", "suspended_by", false, false},
+		{"member_suspensions", "lifted_by", false, false},
+		// A pending application is rejected first
+		{"membership_applications", "member_id", false, true},
+		{"membership_applications", "escalated_by", false, false},
+		{"membership_applications", "decided_by", false, false},
+		{"member_field_verifications", "verified_by", false, false},
+		// Only verifications of inherited fields are copied
+		{"member_field_verifications", "member_id", true, true},
+		{"events", "organizer_id", false, false},
+		{"event_series", "organizer_id", false, false},
+		{"member_notifications", "member_id", false, false},
+		// The token is revoked if the survivor has one
+		{"calendar_tokens", "member_id", false, true},
+		// Responses to an event the survivor answered are deleted
+		{"event_survey_responses", "member_id", false, true},
+		// A referral by the duplicate is cleared on the survivor
+		{"members", "referred_by", false, true},
+	}
+
+	if !assert.Len(t, memberMergeSteps, len(tests)) {
+		return
+	}
+	for i, tt := range tests {
+		step := memberMergeSteps[i]
+		name := tt.table + "." + tt.column
+		assert.Equal(t, tt.table, step.table, name)
+		assert.Equal(t, tt.column, step.column, name)
+		assert.Equal(t, tt.drop, step.drop, name)
+		assert.Equal(t, tt.prepared, len(step.prepare) > 0, name)
+	}
+}
+
+func TestMemberMergeStepStatements(t *testing.T) {
+	move := memberMergeStep{table: "member_documents", column: "uploaded_by"}
+	assert.Equal(t, []string{"UPDATE member_documents SET uploaded_by = $1 WHERE uploaded_by = $2"}, move.statements())
+
+	prepared := memberMergeStep{table: "calendar_tokens", column: "member_id", prepare: []string{"DELETE 1"}}
+	assert.Equal(t, []string{"DELETE 1", "UPDATE calendar_tokens SET member_id = $1 WHERE member_id = $2"}, prepared.statements())
+
+	dropped := memberMergeStep{table: "member_field_verifications", column: "member_id", drop: true, prepare: []string{"INSERT 1"}}
+	assert.Equal(t, []string{"INSERT 1"}, dropped.statements())
+}
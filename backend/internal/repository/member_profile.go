@@ -0,0 +1,227 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/sdyn/backend/internal/models"
+)
+
+// profileFilled is the SQL condition for a non-empty profile field. Profile
+// field names are members columns.
+func profileFilled(field string) string {
+	return fmt.Sprintf("COALESCE(m.%s::text, '') <> ''", field)
+}
+
+// profileScoreExpr computes the completeness score of models.ProfileFields
+func profileScoreExpr() string {
+	parts := make([]string, len(models.ProfileFields))
+	for i, f := range models.ProfileFields {
+		parts[i] = fmt.Sprintf("CASE WHEN %s THEN %d ELSE 0 END", profileFilled(f.Name), f.Weight)
+	}
+	return "(" + strings.Join(parts, " + ") + ")"
+}
+
+// profileMissingExpr lists the empty profile fields
+func profileMissingExpr() string {
+	parts := make([]string, len(models.ProfileFields))
+	for i, f := range models.ProfileFields {
+		parts[i] = fmt.Sprintf("CASE WHEN NOT (%s) THEN '%s' END", profileFilled(f.Name), f.Name)
+	}
+	return "ARRAY_REMOVE(ARRAY[" + strings.Join(parts, ", ") + "]::text[], NULL)"
+}
+
+// inheritedVerificationExpr is the condition for a verification v of
+// duplicate d whose field the survivor s takes over on merge, i.e. the
+// survivor's value is empty and the duplicate's is not
+func inheritedVerificationExpr() string {
+	parts := []string{}
+	for _, f := range models.ProfileFields {
+		if f.Verifiable {
+			parts = append(parts, fmt.Sprintf("(v.field = '%s' AND s.%s IS NULL AND d.%s IS NOT NULL)", f.Name, f.Name, f.Name))
+		}
+	}
+	return "(" + strings.Join(parts, " OR ") + ")"
+}
+
+func (r *MemberRepository) GetVerifications(ctx context.Context, memberID uuid.UUID) ([]models.FieldVerification, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT member_id, field, verified_by, verified_at, note
+		FROM member_field_verifications
+		WHERE member_id = $1
+	`, memberID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	verifications := []models.FieldVerification{}
+	for rows.Next() {
+		var v models.FieldVerification
+		if err := rows.Scan(&v.MemberID, &v.Field, &v.VerifiedBy, &v.VerifiedAt, &v.Note); err != nil {
+			return nil, err
+		}
+		verifications = append(verifications, v)
+	}
+
+	return verifications, rows.Err()
+}
+
+// VerifyField marks the field verified, replacing an earlier verification
+func (r *MemberRepository) VerifyField(ctx context.Context, v *models.FieldVerification) error {
+	return r.db.QueryRow(ctx, `
+		INSERT INTO member_field_verifications (member_id, field, verified_by, note)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (member_id, field) DO UPDATE
+		SET verified_by = EXCLUDED.verified_by, verified_at = NOW(), note = EXCLUDED.note
+		RETURNING verified_at
+	`, v.MemberID, v.Field, v.VerifiedBy, v.Note).Scan(&v.VerifiedAt)
+}
+
+// UnverifyField removes a verification. It returns pgx.ErrNoRows if the
+// field was not verified.
+func (r *MemberRepository) UnverifyField(ctx context.Context, memberID uuid.UUID, field string) error {
+	tag, err := r.db.Exec(ctx, `
+		DELETE FROM member_field_verifications WHERE member_id = $1 AND field = $2
+	`, memberID, field)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+// clearVerifications removes the verifications of fields whose value changed
+func clearVerifications(ctx context.Context, tx pgx.Tx, memberID uuid.UUID, changes []models.FieldChange) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	fields := make([]string, len(changes))
+	for i, change := range changes {
+		fields[i] = change.Field
+	}
+	_, err := tx.Exec(ctx, `
+		DELETE FROM member_field_verifications WHERE member_id = $1 AND field = ANY($2)
+	`, memberID, fields)
+	return err
+}
+
+// IncompleteProfiles lists members whose completeness score is at most
+// params.MaxScore, lowest first, with a per-organization summary over all
+// members matching the other filters
+func (r *MemberRepository) IncompleteProfiles(ctx context.Context, params *models.IncompleteProfileParams) (*models.IncompleteProfileReport, error) {
+	offset := (params.Page - 1) * params.Limit
+	score := profileScoreExpr()
+
+	where := " WHERE 1=1"
+	args := []interface{}{}
+	argCount := 0
+
+	if params.Status != nil {
+		argCount++
+		where += fmt.Sprintf(" AND m.status = $%d", argCount)
+		args = append(args, *params.Status)
+	}
+
+	if params.OrganizationID != nil {
+		argCount++
+		where += fmt.Sprintf(" AND m.organization_id = $%d", argCount)
+		args = append(args, *params.OrganizationID)
+	}
+
+	if params.ProvinceID != nil {
+		argCount++
+		where += fmt.Sprintf(" AND m.province_id = $%d", argCount)
+		args = append(args, *params.ProvinceID)
+	}
+
+	if params.DistrictID != nil {
+		argCount++
+		where += fmt.Sprintf(" AND m.district_id = $%d", argCount)
+		args = append(args, *params.DistrictID)
+	}
+
+	maxScore := 99
+	if params.MaxScore != nil {
+		maxScore = *params.MaxScore
+	}
+	argCount++
+	maxScoreArg := argCount
+	args = append(args, maxScore)
+
+	report := &models.IncompleteProfileReport{
+		Organizations: []models.OrganizationCompleteness{},
+		Members:       []models.IncompleteProfile{},
+		Page:          params.Page,
+		Limit:         params.Limit,
+	}
+
+	summaryQuery := fmt.Sprintf(`
+		SELECT m.organization_id, o.name, COUNT(*)::int,
+			COUNT(*) FILTER (WHERE %[1]s <= $%[2]d)::int,
+			COALESCE(AVG(%[1]s), 0)::float8
+		FROM members m
+		LEFT JOIN organizations o ON m.organization_id = o.id
+		%[3]s
+		GROUP BY m.organization_id, o.name
+		ORDER BY o.name NULLS LAST
+	`, score, maxScoreArg, where)
+
+	rows, err := r.db.Query(ctx, summaryQuery, args...)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var s models.OrganizationCompleteness
+		if err := rows.Scan(&s.OrganizationID, &s.OrganizationName, &s.Members, &s.Incomplete, &s.AverageScore); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		report.Organizations = append(report.Organizations, s)
+		report.Total += s.Incomplete
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	where += fmt.Sprintf(" AND %s <= $%d", score, maxScoreArg)
+	query := fmt.Sprintf(`
+		SELECT m.id, m.member_id, m.first_name, m.last_name, m.status, m.phone,
+			m.organization_id, o.name, %s AS score, %s AS missing
+		FROM members m
+		LEFT JOIN organizations o ON m.organization_id = o.id
+		%s
+		ORDER BY score ASC, m.id
+		LIMIT $%d OFFSET $%d
+	`, score, profileMissingExpr(), where, argCount+1, argCount+2)
+	args = append(args, params.Limit, offset)
+
+	rows, err = r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var p models.IncompleteProfile
+		err := rows.Scan(&p.ID, &p.MemberID, &p.FirstName, &p.LastName, &p.Status, &p.Phone,
+			&p.OrganizationID, &p.OrganizationName, &p.Score, &p.Missing)
+		if err != nil {
+			return nil, err
+		}
+		report.Members = append(report.Members, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	report.TotalPages = (report.Total + params.Limit - 1) / params.Limit
+	return report, nil
+}
@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInheritedVerificationExpr(t *testing.T) {
+	expr := inheritedVerificationExpr()
+	assert.Contains(t, expr, "(v.field = 'national_id' AND s.national_id IS NULL AND d.national_id IS NOT NULL)")
+	assert.Contains(t, expr, "(v.field = 'education' AND s.education IS NULL AND d.education IS NOT NULL)")
+	// Only verifiable fields can have a verification
+	assert.NotContains(t, expr, "gender")
+}
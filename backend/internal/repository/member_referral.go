@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/sdyn/backend/internal/models"
+)
+
+// memberActivatedAt is when a member first reached active status. joined_at
+// is set on activation; older active members without it fall back to
+// created_at. NULL means the member never became active.
+const memberActivatedAt = `CASE
+	WHEN m.joined_at IS NOT NULL THEN m.joined_at
+	WHEN m.status = 'active' THEN m.created_at
+END`
+
+// GetReferrals returns the members referred by rootID down to depth levels,
+// ordered by level and creation time. The referral chain is cycle-safe.
+func (r *MemberRepository) GetReferrals(ctx context.Context, rootID uuid.UUID, depth int) ([]models.ReferralNode, error) {
+	query := `
+		WITH RECURSIVE tree AS (
+			SELECT m.id, m.referred_by, 1 AS level, ARRAY[$1::uuid, m.id] AS path
+			FROM members m
+			WHERE m.referred_by = $1 AND m.id <> $1
+			UNION ALL
+			SELECT m.id, m.referred_by, t.level + 1, t.path || m.id
+			FROM members m
+			JOIN tree t ON m.referred_by = t.id
+			WHERE t.level < $2 AND NOT m.id = ANY(t.path)
+		)
+		SELECT m.id, m.member_id, m.first_name, m.last_name, m.status, t.referred_by, t.level,
+			m.joined_at, m.created_at, (` + memberActivatedAt + `) IS NOT NULL AS activated
+		FROM tree t
+		JOIN members m ON m.id = t.id
+		ORDER BY t.level, m.created_at, m.id
+	`
+
+	rows, err := r.db.Query(ctx, query, rootID, depth)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	nodes := []models.ReferralNode{}
+	for rows.Next() {
+		var n models.ReferralNode
+		err := rows.Scan(&n.ID, &n.MemberID, &n.FirstName, &n.LastName, &n.Status, &n.ReferredBy, &n.Level,
+			&n.JoinedAt, &n.CreatedAt, &n.Activated)
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, n)
+	}
+
+	return nodes, rows.Err()
+}
+
+// GetReferralLeaderboard ranks referrers by the number of members they
+// referred who became active within the period. Scope and organization
+// filters apply to the referrer.
+func (r *MemberRepository) GetReferralLeaderboard(ctx context.Context, params *models.ReferralLeaderboardParams) ([]models.ReferralLeader, error) {
+	where := " WHERE (" + memberActivatedAt + ") IS NOT NULL"
+	args := []interface{}{}
+	argCount := 0
+
+	if params.From != nil {
+		argCount++
+		where += fmt.Sprintf(" AND (%s) >= $%d::date", memberActivatedAt, argCount)
+		args = append(args, *params.From)
+	}
+
+	if params.To != nil {
+		argCount++
+		where += fmt.Sprintf(" AND (%s) < ($%d::date + interval '1 day')", memberActivatedAt, argCount)
+		args = append(args, *params.To)
+	}
+
+	if params.OrganizationID != nil {
+		argCount++
+		where += fmt.Sprintf(" AND ref.organization_id = $%d", argCount)
+		args = append(args, *params.OrganizationID)
+	}
+
+	if params.ProvinceID != nil {
+		argCount++
+		where += fmt.Sprintf(" AND ref.province_id = $%d", argCount)
+		args = append(args, *params.ProvinceID)
+	}
+
+	if params.DistrictID != nil {
+		argCount++
+		where += fmt.Sprintf(" AND ref.district_id = $%d", argCount)
+		args = append(args, *params.DistrictID)
+	}
+
+	argCount++
+	query := `
+		SELECT RANK() OVER (ORDER BY COUNT(*) DESC)::int AS rank,
+			ref.id, ref.member_id, ref.first_name, ref.last_name, ref.organization_id, o.name,
+			COUNT(*)::int AS active_referrals
+		FROM members m
+		JOIN members ref ON m.referred_by = ref.id
+		LEFT JOIN organizations o ON ref.organization_id = o.id
+	` + where + fmt.Sprintf(`
+		GROUP BY ref.id, o.name
+		ORDER BY active_referrals DESC, ref.member_id
+		LIMIT $%d
+	`, argCount)
+	args = append(args, params.Limit)
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	leaders := []models.ReferralLeader{}
+	for rows.Next() {
+		var l models.ReferralLeader
+		err := rows.Scan(&l.Rank, &l.ID, &l.MemberID, &l.FirstName, &l.LastName, &l.OrganizationID, &l.OrganizationName, &l.ActiveReferrals)
+		if err != nil {
+			return nil, err
+		}
+		leaders = append(leaders, l)
+	}
+
+	return leaders, rows.Err()
+}
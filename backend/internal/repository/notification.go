@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/sdyn/backend/internal/models"
+)
+
+type NotificationRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewNotificationRepository(db *pgxpool.Pool) *NotificationRepository {
+	return &NotificationRepository{db: db}
+}
+
+// ListByMember returns the member's latest notifications, newest first
+func (r *NotificationRepository) ListByMember(ctx context.Context, memberID uuid.UUID, unreadOnly bool, limit int) ([]models.Notification, error) {
+	query := `
+		SELECT id, member_id, type, title, body, event_id, read_at, created_at
+		FROM member_notifications
+		WHERE member_id = $1
+	`
+	if unreadOnly {
+		query += " AND read_at IS NULL"
+	}
+	query += " ORDER BY created_at DESC LIMIT $2"
+
+	rows, err := r.db.Query(ctx, query, memberID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	notifications := []models.Notification{}
+	for rows.Next() {
+		var n models.Notification
+		if err := rows.Scan(&n.ID, &n.MemberID, &n.Type, &n.Title, &n.Body, &n.EventID, &n.ReadAt, &n.CreatedAt); err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, n)
+	}
+	return notifications, rows.Err()
+}
+
+// MarkRead marks the member's notification read. It returns pgx.ErrNoRows
+// if the member has no such notification.
+func (r *NotificationRepository) MarkRead(ctx context.Context, id, memberID uuid.UUID) error {
+	tag, err := r.db.Exec(ctx, `
+		UPDATE member_notifications SET read_at = COALESCE(read_at, NOW())
+		WHERE id = $1 AND member_id = $2
+	`, id, memberID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -25,11 +26,49 @@ func (r *OrganizationRepository) List(ctx context.Context, params *models.Organi
 		FROM organizations o
 		LEFT JOIN provinces p ON o.province_id = p.id
 		LEFT JOIN districts d ON o.district_id = d.id
-		WHERE o.is_active = true
-		ORDER BY o.level, o.name
 	`
+	// Only active organizations unless explicitly requested otherwise
+	isActive := true
+	if params.IsActive != nil {
+		isActive = *params.IsActive
+	}
+	args := []interface{}{isActive}
+	argCount := 1
+	query += " WHERE o.is_active = $1"
+
+	if params.Search != "" {
+		argCount++
+		query += fmt.Sprintf(" AND (o.name ILIKE $%d OR o.code ILIKE $%d)", argCount, argCount)
+		args = append(args, "%"+params.Search+"%")
+	}
 
-	rows, err := r.db.Query(ctx, query)
+	if params.Level != nil {
+		argCount++
+		query += fmt.Sprintf(" AND o.level = $%d", argCount)
+		args = append(args, *params.Level)
+	}
+
+	if params.ProvinceID != nil {
+		argCount++
+		query += fmt.Sprintf(" AND o.province_id = $%d", argCount)
+		args = append(args, *params.ProvinceID)
+	}
+
+	if params.DistrictID != nil {
+		argCount++
+		query += fmt.Sprintf(" AND o.district_id = $%d", argCount)
+		args = append(args, *params.DistrictID)
+	}
+
+	if params.ParentID != nil {
+		argCount++
+		query += fmt.Sprintf(" AND o.parent_id = $%d", argCount)
+		args = append(args, *params.ParentID)
+	}
+
+	query += " ORDER BY o.level, o.name"
+
+	rows, err := r.db.Query(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/sdyn/backend/internal/models"
+)
+
+// volunteerHoursSum sums the hours of the ep participations credited for
+// volunteer and campaign events e
+const volunteerHoursSum = `COALESCE(SUM(ep.hours) FILTER (WHERE e.type IN ('volunteer', 'campaign')), 0)`
+
+// participationPoints computes the points of the grouped participations
+// from the per-event and per-hour points in the given arguments
+func participationPoints(perEventArg, perHourArg int) string {
+	return fmt.Sprintf("(COUNT(*) * $%d + FLOOR(%s * $%d))::int", perEventArg, volunteerHoursSum, perHourArg)
+}
+
+// CheckOut records when an attended participant left and, unless an
+// organizer entered them, credits the given hours. It reports whether the
+// check-out happened; a participant who had already checked out is
+// (false, nil) and anyone who did not attend is pgx.ErrNoRows.
+func (r *EventRepository) CheckOut(ctx context.Context, eventID, memberID uuid.UUID, checkedOutAt time.Time, hours float64) (bool, error) {
+	tag, err := r.db.Exec(ctx, `
+		UPDATE event_participants
+		SET checked_out_at = $3,
+			hours = CASE WHEN hours_entered_by IS NULL THEN $4 ELSE hours END
+		WHERE event_id = $1 AND member_id = $2 AND status = 'attended' AND checked_out_at IS NULL
+	`, eventID, memberID, checkedOutAt, hours)
+	if err != nil {
+		return false, err
+	}
+	if tag.RowsAffected() == 1 {
+		return true, nil
+	}
+
+	var attended bool
+	err = r.db.QueryRow(ctx, `
+		SELECT EXISTS(SELECT 1 FROM event_participants WHERE event_id = $1 AND member_id = $2 AND status = 'attended')
+	`, eventID, memberID).Scan(&attended)
+	if err != nil {
+		return false, err
+	}
+	if !attended {
+		return false, pgx.ErrNoRows
+	}
+	return false, nil
+}
+
+// SetHours credits hours entered by an organizer to an attended
+// participant. It returns pgx.ErrNoRows if the member did not attend.
+func (r *EventRepository) SetHours(ctx context.Context, eventID, memberID uuid.UUID, hours float64, enteredBy uuid.UUID) error {
+	tag, err := r.db.Exec(ctx, `
+		UPDATE event_participants SET hours = $3, hours_entered_by = $4
+		WHERE event_id = $1 AND member_id = $2 AND status = 'attended'
+	`, eventID, memberID, hours, enteredBy)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+// ParticipationSummary totals the member's attended events, volunteer
+// hours and points
+func (r *EventRepository) ParticipationSummary(ctx context.Context, memberID uuid.UUID, pointsPerEvent, pointsPerHour int) (*models.ParticipationSummary, error) {
+	summary := &models.ParticipationSummary{MemberID: memberID}
+	err := r.db.QueryRow(ctx, `
+		SELECT COUNT(*)::int, `+volunteerHoursSum+`::float8, `+participationPoints(2, 3)+`, MAX(e.start_date)
+		FROM event_participants ep
+		JOIN events e ON e.id = ep.event_id
+		WHERE ep.member_id = $1 AND ep.status = 'attended'
+	`, memberID, pointsPerEvent, pointsPerHour).Scan(
+		&summary.EventsAttended, &summary.VolunteerHours, &summary.Points, &summary.LastAttendedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return summary, nil
+}
+
+// GetParticipationLeaderboard ranks members by the points of events that
+// started within the period. Scope and organization filters apply to the
+// member.
+func (r *EventRepository) GetParticipationLeaderboard(ctx context.Context, params *models.ParticipationLeaderboardParams, pointsPerEvent, pointsPerHour int) ([]models.ParticipationLeader, error) {
+	where := " WHERE ep.status = 'attended'"
+	args := []interface{}{}
+	argCount := 0
+
+	if params.From != nil {
+		argCount++
+		where += fmt.Sprintf(" AND e.start_date >= $%d::date", argCount)
+		args = append(args, *params.From)
+	}
+
+	if params.To != nil {
+		argCount++
+		where += fmt.Sprintf(" AND e.start_date < ($%d::date + interval '1 day')", argCount)
+		args = append(args, *params.To)
+	}
+
+	if params.OrganizationID != nil {
+		argCount++
+		where += fmt.Sprintf(" AND m.organization_id = $%d", argCount)
+		args = append(args, *params.OrganizationID)
+	}
+
+	if params.ProvinceID != nil {
+		argCount++
+		where += fmt.Sprintf(" AND m.province_id = $%d", argCount)
+		args = append(args, *params.ProvinceID)
+	}
+
+	if params.DistrictID != nil {
+		argCount++
+		where += fmt.Sprintf(" AND m.district_id = $%d", argCount)
+		args = append(args, *params.DistrictID)
+	}
+
+	points := participationPoints(argCount+1, argCount+2)
+	query := `
+		SELECT RANK() OVER (ORDER BY ` + points + ` DESC)::int AS rank,
+			m.id, m.member_id, m.first_name, m.last_name, m.organization_id, o.name,
+			COUNT(*)::int AS events_attended,
+			` + volunteerHoursSum + `::float8 AS volunteer_hours,
+			` + points + ` AS points
+		FROM event_participants ep
+		JOIN events e ON e.id = ep.event_id
+		JOIN members m ON m.id = ep.member_id
+		LEFT JOIN organizations o ON m.organization_id = o.id
+	` + where + fmt.Sprintf(`
+		GROUP BY m.id, o.name
+		ORDER BY points DESC, volunteer_hours DESC, m.member_id
+		LIMIT $%d
+	`, argCount+3)
+	args = append(args, pointsPerEvent, pointsPerHour, params.Limit)
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	leaders := []models.ParticipationLeader{}
+	for rows.Next() {
+		var l models.ParticipationLeader
+		err := rows.Scan(
+			&l.Rank, &l.ID, &l.MemberID, &l.FirstName, &l.LastName, &l.OrganizationID, &l.OrganizationName,
+			&l.EventsAttended, &l.VolunteerHours, &l.Points,
+		)
+		if err != nil {
+			return nil, err
+		}
+		leaders = append(leaders, l)
+	}
+
+	return leaders, rows.Err()
+}
@@ -0,0 +1,13 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParticipationPoints(t *testing.T) {
+	assert.Equal(t,
+		"(COUNT(*) * $4 + FLOOR(COALESCE(SUM(ep.hours) FILTER (WHERE e.type IN ('volunteer', 'campaign')), 0) * $5))::int",
+		participationPoints(4, 5))
+}
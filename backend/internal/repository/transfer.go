@@ -0,0 +1,198 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/sdyn/backend/internal/models"
+)
+
+type TransferRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewTransferRepository(db *pgxpool.Pool) *TransferRepository {
+	return &TransferRepository{db: db}
+}
+
+const transferSelect = `
+	SELECT t.id, t.member_id, t.from_organization_id, t.to_organization_id, t.status, t.reason,
+		t.requested_by, t.requested_at, t.decided_at, t.decided_by, t.decision_reason,
+		t.created_at, t.updated_at,
+		m.member_id as member_code, (m.first_name || ' ' || m.last_name) as member_name,
+		fo.name as from_organization_name, tor.name as to_organization_name
+	FROM member_transfers t
+	JOIN members m ON t.member_id = m.id
+	LEFT JOIN organizations fo ON t.from_organization_id = fo.id
+	JOIN organizations tor ON t.to_organization_id = tor.id
+`
+
+func scanTransfer(row pgx.Row) (*models.MemberTransfer, error) {
+	var t models.MemberTransfer
+	err := row.Scan(
+		&t.ID, &t.MemberID, &t.FromOrganizationID, &t.ToOrganizationID, &t.Status, &t.Reason,
+		&t.RequestedBy, &t.RequestedAt, &t.DecidedAt, &t.DecidedBy, &t.DecisionReason,
+		&t.CreatedAt, &t.UpdatedAt,
+		&t.MemberCode, &t.MemberName, &t.FromOrganizationName, &t.ToOrganizationName,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// List returns an organization's transfers, incoming, outgoing or both
+func (r *TransferRepository) List(ctx context.Context, params *models.TransferListParams) (*models.TransferListResponse, error) {
+	offset := (params.Page - 1) * params.Limit
+
+	where := " WHERE 1=1"
+	args := []interface{}{params.OrganizationID}
+	argCount := 1
+
+	switch params.Direction {
+	case models.TransferDirectionIncoming:
+		where += " AND t.to_organization_id = $1"
+	case models.TransferDirectionOutgoing:
+		where += " AND t.from_organization_id = $1"
+	default:
+		where += " AND (t.to_organization_id = $1 OR t.from_organization_id = $1)"
+	}
+
+	status := models.TransferStatusPending
+	if params.Status != nil {
+		status = *params.Status
+	}
+	argCount++
+	where += fmt.Sprintf(" AND t.status = $%d", argCount)
+	args = append(args, status)
+
+	var total int
+	countQuery := "SELECT COUNT(*) FROM member_transfers t" + where
+	if err := r.db.QueryRow(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, err
+	}
+
+	query := transferSelect + where + fmt.Sprintf(" ORDER BY t.requested_at ASC, t.id LIMIT $%d OFFSET $%d", argCount+1, argCount+2)
+	args = append(args, params.Limit, offset)
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	transfers := []models.MemberTransfer{}
+	for rows.Next() {
+		t, err := scanTransfer(rows)
+		if err != nil {
+			return nil, err
+		}
+		transfers = append(transfers, *t)
+	}
+
+	return &models.TransferListResponse{
+		Transfers:  transfers,
+		Total:      total,
+		Page:       params.Page,
+		Limit:      params.Limit,
+		TotalPages: (total + params.Limit - 1) / params.Limit,
+	}, nil
+}
+
+func (r *TransferRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.MemberTransfer, error) {
+	return scanTransfer(r.db.QueryRow(ctx, transferSelect+" WHERE t.id = $1", id))
+}
+
+// Create opens a transfer. It returns pgx.ErrNoRows if the member already
+// has a pending transfer.
+func (r *TransferRepository) Create(ctx context.Context, transfer *models.MemberTransfer) (*models.MemberTransfer, error) {
+	var id uuid.UUID
+	err := r.db.QueryRow(ctx, `
+		INSERT INTO member_transfers (member_id, from_organization_id, to_organization_id, reason, requested_by)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (member_id) WHERE status = 'pending' DO NOTHING
+		RETURNING id
+	`, transfer.MemberID, transfer.FromOrganizationID, transfer.ToOrganizationID, transfer.Reason, transfer.RequestedBy).Scan(&id)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.GetByID(ctx, id)
+}
+
+// Accept closes a pending transfer and, in the same transaction, moves the
+// member to the receiving organization with its province and district,
+// ends the member's current positions in the source organization and
+// writes the history row. It returns pgx.ErrNoRows if the transfer is no
+// longer pending or the member has left the source organization since it
+// was requested.
+func (r *TransferRepository) Accept(ctx context.Context, id uuid.UUID, decidedBy *uuid.UUID, reason *string, history *models.MemberHistory) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var memberID, toOrgID uuid.UUID
+	var fromOrgID *uuid.UUID
+	err = tx.QueryRow(ctx, `
+		UPDATE member_transfers
+		SET status = 'accepted', decided_at = NOW(), decided_by = $2, decision_reason = $3, updated_at = NOW()
+		WHERE id = $1 AND status = 'pending'
+		RETURNING member_id, from_organization_id, to_organization_id
+	`, id, decidedBy, reason).Scan(&memberID, &fromOrgID, &toOrgID)
+	if err != nil {
+		return err
+	}
+
+	tag, err := tx.Exec(ctx, `
+		UPDATE members m
+		SET organization_id = o.id, province_id = o.province_id, district_id = o.district_id
+		FROM organizations o
+		WHERE m.id = $1 AND o.id = $2 AND m.organization_id IS NOT DISTINCT FROM $3
+	`, memberID, toOrgID, fromOrgID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+
+	if fromOrgID != nil {
+		_, err = tx.Exec(ctx, `
+			UPDATE member_positions SET is_current = false, ended_at = NOW(), updated_at = NOW()
+			WHERE member_id = $1 AND organization_id = $2 AND is_current = true
+		`, memberID, *fromOrgID)
+		if err != nil {
+			return err
+		}
+	}
+
+	history.MemberID = memberID
+	if err := insertHistory(ctx, tx, history); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// Close declines or cancels a pending transfer. It returns pgx.ErrNoRows if
+// the transfer is no longer pending.
+func (r *TransferRepository) Close(ctx context.Context, id uuid.UUID, status models.TransferStatus, decidedBy *uuid.UUID, reason *string) error {
+	tag, err := r.db.Exec(ctx, `
+		UPDATE member_transfers
+		SET status = $2, decided_at = NOW(), decided_by = $3, decision_reason = $4, updated_at = NOW()
+		WHERE id = $1 AND status = 'pending'
+	`, id, status, decidedBy, reason)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
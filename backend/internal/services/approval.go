@@ -0,0 +1,174 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/sdyn/backend/internal/models"
+	"github.com/sdyn/backend/internal/repository"
+)
+
+// escalationSLA is the time a higher level gets to decide an escalated
+// application. New applications get 3 days at the district level, set by
+// the membership_applications trigger.
+const escalationSLA = 5 * 24 * time.Hour
+
+// ErrApplicationClosed is returned when deciding or escalating an
+// application that has already been approved or rejected
+var ErrApplicationClosed = errors.New("application is no longer pending")
+
+type ApprovalService struct {
+	repo *repository.ApprovalRepository
+}
+
+func NewApprovalService(repo *repository.ApprovalRepository) *ApprovalService {
+	return &ApprovalService{repo: repo}
+}
+
+// List returns the approval queue, oldest deadline first
+func (s *ApprovalService) List(ctx context.Context, params *models.ApprovalListParams) (*models.ApprovalListResponse, error) {
+	return s.repo.List(ctx, params)
+}
+
+// Approve activates the applicant's membership
+func (s *ApprovalService) Approve(ctx context.Context, id uuid.UUID, req *models.ApprovalDecisionRequest, filter *DataScopeFilter, changedBy string) (*models.MembershipApplication, error) {
+	return s.decide(ctx, id, models.ApprovalStatusApproved, models.MemberStatusActive, req.Reason, filter, changedBy)
+}
+
+// Reject rejects the application and marks the member inactive. A reason is
+// required so the applicant can be told why.
+func (s *ApprovalService) Reject(ctx context.Context, id uuid.UUID, req *models.ApprovalDecisionRequest, filter *DataScopeFilter, changedBy string) (*models.MembershipApplication, error) {
+	if req.Reason == nil || strings.TrimSpace(*req.Reason) == "" {
+		return nil, &ApprovalError{Message: "Reason is required to reject an application"}
+	}
+	return s.decide(ctx, id, models.ApprovalStatusRejected, models.MemberStatusInactive, req.Reason, filter, changedBy)
+}
+
+// Escalate hands the application to the next level up with a new deadline
+func (s *ApprovalService) Escalate(ctx context.Context, id uuid.UUID, req *models.ApprovalDecisionRequest, filter *DataScopeFilter, changedBy string) (*models.MembershipApplication, error) {
+	app, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !canReview(app, filter) {
+		return nil, &ApprovalError{Message: "You cannot review this application", Forbidden: true}
+	}
+	if app.Status != models.ApprovalStatusPending {
+		return nil, ErrApplicationClosed
+	}
+
+	level, ok := nextApprovalLevel(app)
+	if !ok {
+		return nil, &ApprovalError{Message: "Application is already at the national level"}
+	}
+
+	changedByUUID, _ := uuid.Parse(changedBy)
+	history := &models.MemberHistory{
+		Action:    "escalated",
+		OldValue:  stringPtr(string(app.Level)),
+		NewValue:  stringPtr(string(level)),
+		ChangedBy: &changedByUUID,
+		Reason:    req.Reason,
+	}
+
+	err = s.repo.Escalate(ctx, id, level, time.Now().Add(escalationSLA), &changedByUUID, req.Reason, history)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// Decided by someone else since it was loaded
+		return nil, ErrApplicationClosed
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *ApprovalService) decide(ctx context.Context, id uuid.UUID, status models.ApprovalStatus, memberStatus models.MemberStatus, reason *string, filter *DataScopeFilter, changedBy string) (*models.MembershipApplication, error) {
+	app, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !canReview(app, filter) {
+		return nil, &ApprovalError{Message: "You cannot review this application", Forbidden: true}
+	}
+	if app.Status != models.ApprovalStatusPending {
+		return nil, ErrApplicationClosed
+	}
+
+	changedByUUID, _ := uuid.Parse(changedBy)
+	history := &models.MemberHistory{
+		Action:    string(status),
+		OldValue:  stringPtr(string(app.MemberStatus)),
+		NewValue:  stringPtr(string(memberStatus)),
+		ChangedBy: &changedByUUID,
+		Reason:    reason,
+	}
+
+	err = s.repo.Decide(ctx, id, status, memberStatus, &changedByUUID, reason, history)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// Decided by someone else since it was loaded
+		return nil, ErrApplicationClosed
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetByID(ctx, id)
+}
+
+// canReview reports whether the caller's data scope covers the application
+// at its current level. District admins only see district-level
+// applications; province admins can also decide those escalated to them.
+func canReview(app *models.MembershipApplication, filter *DataScopeFilter) bool {
+	if filter == nil {
+		return false
+	}
+
+	switch filter.Scope {
+	case models.ScopeAll:
+		return true
+	case models.ScopeProvince:
+		return app.Level != models.ApprovalLevelNational &&
+			filter.ProvinceID != nil && app.ProvinceID != nil && *app.ProvinceID == *filter.ProvinceID
+	case models.ScopeDistrict:
+		if app.Level != models.ApprovalLevelDistrict {
+			return false
+		}
+		if filter.DistrictID != nil {
+			return app.DistrictID != nil && *app.DistrictID == *filter.DistrictID
+		}
+		return filter.OrganizationID != nil && app.MemberOrganizationID != nil &&
+			*app.MemberOrganizationID == *filter.OrganizationID
+	}
+
+	return false
+}
+
+// nextApprovalLevel returns the level an application escalates to.
+// Applications without a province skip straight to national review.
+func nextApprovalLevel(app *models.MembershipApplication) (models.ApprovalLevel, bool) {
+	switch app.Level {
+	case models.ApprovalLevelDistrict:
+		if app.ProvinceID != nil {
+			return models.ApprovalLevelProvince, true
+		}
+		return models.ApprovalLevelNational, true
+	case models.ApprovalLevelProvince:
+		return models.ApprovalLevelNational, true
+	}
+	return "", false
+}
+
+type ApprovalError struct {
+	Message   string
+	Forbidden bool
+}
+
+func (e *ApprovalError) Error() string {
+	return e.Message
+}
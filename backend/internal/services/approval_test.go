@@ -0,0 +1,82 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sdyn/backend/internal/models"
+)
+
+func TestCanReview(t *testing.T) {
+	provinceID := uuid.New()
+	districtID := uuid.New()
+	orgID := uuid.New()
+	other := uuid.New()
+
+	districtApp := &models.MembershipApplication{
+		Level:                models.ApprovalLevelDistrict,
+		ProvinceID:           &provinceID,
+		DistrictID:           &districtID,
+		MemberOrganizationID: &orgID,
+	}
+	provinceApp := &models.MembershipApplication{
+		Level:      models.ApprovalLevelProvince,
+		ProvinceID: &provinceID,
+		DistrictID: &districtID,
+	}
+	nationalApp := &models.MembershipApplication{Level: models.ApprovalLevelNational}
+
+	tests := []struct {
+		name   string
+		app    *models.MembershipApplication
+		filter *DataScopeFilter
+		want   bool
+	}{
+		{"national admin reviews anything", nationalApp, &DataScopeFilter{Scope: models.ScopeAll}, true},
+		{"district admin of the district", districtApp, &DataScopeFilter{Scope: models.ScopeDistrict, DistrictID: &districtID}, true},
+		{"district admin of another district", districtApp, &DataScopeFilter{Scope: models.ScopeDistrict, DistrictID: &other}, false},
+		{"district admin after escalation", provinceApp, &DataScopeFilter{Scope: models.ScopeDistrict, DistrictID: &districtID}, false},
+		{"district admin without district uses organization", districtApp, &DataScopeFilter{Scope: models.ScopeDistrict, OrganizationID: &orgID}, true},
+		{"province admin reviews district level", districtApp, &DataScopeFilter{Scope: models.ScopeProvince, ProvinceID: &provinceID}, true},
+		{"province admin reviews escalated", provinceApp, &DataScopeFilter{Scope: models.ScopeProvince, ProvinceID: &provinceID}, true},
+		{"province admin of another province", provinceApp, &DataScopeFilter{Scope: models.ScopeProvince, ProvinceID: &other}, false},
+		{"province admin after national escalation", nationalApp, &DataScopeFilter{Scope: models.ScopeProvince, ProvinceID: &provinceID}, false},
+		{"own scope", districtApp, &DataScopeFilter{Scope: models.ScopeOwn}, false},
+		{"no scope", districtApp, nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, canReview(tt.app, tt.filter))
+		})
+	}
+}
+
+func TestNextApprovalLevel(t *testing.T) {
+	provinceID := uuid.New()
+
+	level, ok := nextApprovalLevel(&models.MembershipApplication{Level: models.ApprovalLevelDistrict, ProvinceID: &provinceID})
+	assert.True(t, ok)
+	assert.Equal(t, models.ApprovalLevelProvince, level)
+
+	level, ok = nextApprovalLevel(&models.MembershipApplication{Level: models.ApprovalLevelDistrict})
+	assert.True(t, ok)
+	assert.Equal(t, models.ApprovalLevelNational, level)
+
+	_, ok = nextApprovalLevel(&models.MembershipApplication{Level: models.ApprovalLevelNational})
+	assert.False(t, ok)
+}
+
+func TestRejectRequiresReason(t *testing.T) {
+	s := NewApprovalService(nil)
+	blank := "  "
+
+	for _, req := range []*models.ApprovalDecisionRequest{{}, {Reason: &blank}} {
+		_, err := s.Reject(context.Background(), uuid.New(), req, &DataScopeFilter{Scope: models.ScopeAll}, "")
+		var approvalErr *ApprovalError
+		assert.ErrorAs(t, err, &approvalErr)
+	}
+}
@@ -0,0 +1,217 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/sdyn/backend/internal/models"
+	"github.com/sdyn/backend/internal/repository"
+)
+
+const (
+	// calendarFeedPast is how far back organization and public feeds go
+	calendarFeedPast = 90 * 24 * time.Hour
+	// calendarFeedLimit caps the events of an organization or public feed
+	calendarFeedLimit = 1000
+	// icsLineLimit is the longest content line in octets (RFC 5545 3.1)
+	icsLineLimit = 75
+	// icsTimeFormat is a UTC DATE-TIME
+	icsTimeFormat = "20060102T150405Z"
+)
+
+type CalendarService struct {
+	tokens    *repository.CalendarTokenRepository
+	eventRepo *repository.EventRepository
+	orgRepo   *repository.OrganizationRepository
+}
+
+func NewCalendarService(tokens *repository.CalendarTokenRepository, eventRepo *repository.EventRepository, orgRepo *repository.OrganizationRepository) *CalendarService {
+	return &CalendarService{
+		tokens:    tokens,
+		eventRepo: eventRepo,
+		orgRepo:   orgRepo,
+	}
+}
+
+// CreateToken issues a new feed token for the member. An earlier token
+// stops working. Only its hash is stored, so the token is shown once.
+func (s *CalendarService) CreateToken(ctx context.Context, memberID uuid.UUID) (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+
+	if err := s.tokens.Save(ctx, memberID, hashCalendarToken(token)); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// RevokeToken removes the member's feed token. It reports whether there
+// was one.
+func (s *CalendarService) RevokeToken(ctx context.Context, memberID uuid.UUID) (bool, error) {
+	return s.tokens.Delete(ctx, memberID)
+}
+
+// Authenticate returns the member the feed token belongs to, or
+// pgx.ErrNoRows if it is unknown or revoked
+func (s *CalendarService) Authenticate(ctx context.Context, token string) (uuid.UUID, error) {
+	if token == "" {
+		return uuid.Nil, pgx.ErrNoRows
+	}
+	return s.tokens.MemberID(ctx, hashCalendarToken(token))
+}
+
+func hashCalendarToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// MemberCalendar returns the events the member is registered for
+func (s *CalendarService) MemberCalendar(ctx context.Context, memberID uuid.UUID) ([]byte, error) {
+	events, err := s.eventRepo.GetMemberEvents(ctx, memberID)
+	if err != nil {
+		return nil, err
+	}
+	return renderCalendar("SDYN - My events", events, time.Now()), nil
+}
+
+// OrganizationCalendar returns the organization's events
+func (s *CalendarService) OrganizationCalendar(ctx context.Context, orgID uuid.UUID) ([]byte, error) {
+	org, err := s.orgRepo.GetByID(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	events, err := s.eventRepo.CalendarEvents(ctx, &orgID, false, now.Add(-calendarFeedPast), calendarFeedLimit)
+	if err != nil {
+		return nil, err
+	}
+	return renderCalendar(org.Name, events, now), nil
+}
+
+// PublicCalendar returns the public events of every organization
+func (s *CalendarService) PublicCalendar(ctx context.Context) ([]byte, error) {
+	now := time.Now()
+	events, err := s.eventRepo.CalendarEvents(ctx, nil, true, now.Add(-calendarFeedPast), calendarFeedLimit)
+	if err != nil {
+		return nil, err
+	}
+	return renderCalendar("SDYN - Public events", events, now), nil
+}
+
+// renderCalendar writes the events as an iCalendar (RFC 5545) document
+func renderCalendar(name string, events []models.Event, now time.Time) []byte {
+	var buf bytes.Buffer
+	line := func(name, value string) {
+		writeICSLine(&buf, name+":"+value)
+	}
+
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", "-//SDYN//Events//MN")
+	line("CALSCALE", "GREGORIAN")
+	line("METHOD", "PUBLISH")
+	line("X-WR-CALNAME", escapeICSText(name))
+	line("REFRESH-INTERVAL;VALUE=DURATION", "PT1H")
+	line("X-PUBLISHED-TTL", "PT1H")
+
+	for _, e := range events {
+		line("BEGIN", "VEVENT")
+		line("UID", e.ID.String()+"@sdyn")
+		line("DTSTAMP", now.UTC().Format(icsTimeFormat))
+		line("LAST-MODIFIED", e.UpdatedAt.UTC().Format(icsTimeFormat))
+		line("DTSTART", e.StartDate.UTC().Format(icsTimeFormat))
+		if e.EndDate != nil && e.EndDate.After(e.StartDate) {
+			line("DTEND", e.EndDate.UTC().Format(icsTimeFormat))
+		}
+		line("SUMMARY", escapeICSText(e.Title))
+
+		var description []string
+		if e.Description != nil && *e.Description != "" {
+			description = append(description, *e.Description)
+		}
+		if e.OnlineURL != nil && *e.OnlineURL != "" {
+			description = append(description, "Online: "+*e.OnlineURL)
+			line("URL", *e.OnlineURL)
+		}
+		if len(description) > 0 {
+			line("DESCRIPTION", escapeICSText(strings.Join(description, "\n\n")))
+		}
+
+		var location []string
+		for _, part := range []*string{e.Location, e.Address} {
+			if part != nil && *part != "" {
+				location = append(location, *part)
+			}
+		}
+		if len(location) == 0 && e.IsOnline && e.OnlineURL != nil && *e.OnlineURL != "" {
+			location = append(location, *e.OnlineURL)
+		}
+		if len(location) > 0 {
+			line("LOCATION", escapeICSText(strings.Join(location, ", ")))
+		}
+
+		line("STATUS", icsStatus(e.Status))
+		line("END", "VEVENT")
+	}
+
+	line("END", "VCALENDAR")
+	return buf.Bytes()
+}
+
+func icsStatus(status models.EventStatus) string {
+	switch status {
+	case models.EventStatusCancelled:
+		return "CANCELLED"
+	case models.EventStatusDraft:
+		return "TENTATIVE"
+	default:
+		return "CONFIRMED"
+	}
+}
+
+var icsTextEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	";", `\;`,
+	",", `\,`,
+	"\r\n", `\n`,
+	"\n", `\n`,
+	"\r", `\n`,
+)
+
+// escapeICSText escapes a TEXT value (RFC 5545 3.3.11)
+func escapeICSText(s string) string {
+	return icsTextEscaper.Replace(s)
+}
+
+// writeICSLine writes a content line ending in CRLF, folding it so no line
+// is longer than 75 octets without splitting a UTF-8 sequence
+func writeICSLine(buf *bytes.Buffer, s string) {
+	limit := icsLineLimit
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		buf.WriteString(s[:cut])
+		buf.WriteString("\r\n ")
+		s = s[cut:]
+		// Continuation lines start with a space
+		limit = icsLineLimit - 1
+	}
+	buf.WriteString(s)
+	buf.WriteString("\r\n")
+}
@@ -0,0 +1,85 @@
+package services
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sdyn/backend/internal/models"
+)
+
+func TestRenderCalendar(t *testing.T) {
+	now := time.Date(2026, 5, 1, 8, 0, 0, 0, time.UTC)
+	start := time.Date(2026, 5, 10, 18, 30, 0, 0, ulaanbaatar)
+	end := start.Add(2 * time.Hour)
+	location := "Залуучуудын ордон, 2 давхар"
+	url := "https://meet.example.com/abc"
+
+	event := models.Event{
+		ID:        uuid.MustParse("6f1c0a44-3d1e-4b7a-9a55-0c2f3e8b9d10"),
+		Title:     "Сургалт; удирдагчид",
+		Status:    models.EventStatusCancelled,
+		StartDate: start,
+		EndDate:   &end,
+		Location:  &location,
+		OnlineURL: &url,
+		UpdatedAt: now,
+	}
+
+	ics := string(renderCalendar("SDYN - My events", []models.Event{event}, now))
+
+	assert.True(t, strings.HasPrefix(ics, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n"))
+	assert.True(t, strings.HasSuffix(ics, "END:VEVENT\r\nEND:VCALENDAR\r\n"))
+	for _, line := range []string{
+		"UID:6f1c0a44-3d1e-4b7a-9a55-0c2f3e8b9d10@sdyn",
+		"DTSTAMP:20260501T080000Z",
+		"DTSTART:20260510T103000Z",
+		"DTEND:20260510T123000Z",
+		`SUMMARY:Сургалт\; удирдагчид`,
+		"URL:https://meet.example.com/abc",
+		"DESCRIPTION:Online: https://meet.example.com/abc",
+		`LOCATION:Залуучуудын ордон\, 2 давхар`,
+		"STATUS:CANCELLED",
+	} {
+		assert.Contains(t, ics, line+"\r\n")
+	}
+}
+
+func TestRenderCalendarStatus(t *testing.T) {
+	for status, want := range map[models.EventStatus]string{
+		models.EventStatusPlanned:   "STATUS:CONFIRMED",
+		models.EventStatusOngoing:   "STATUS:CONFIRMED",
+		models.EventStatusCompleted: "STATUS:CONFIRMED",
+		models.EventStatusDraft:     "STATUS:TENTATIVE",
+	} {
+		ics := string(renderCalendar("Events", []models.Event{{Title: "x", Status: status}}, time.Now()))
+		assert.Contains(t, ics, want+"\r\n", status)
+		assert.NotContains(t, ics, "DTEND", status)
+	}
+}
+
+func TestEscapeICSText(t *testing.T) {
+	assert.Equal(t, `a\\b\;c\,d\ne\nf`, escapeICSText("a\\b;c,d\r\ne\nf"))
+}
+
+func TestWriteICSLineFolds(t *testing.T) {
+	var buf bytes.Buffer
+	value := strings.Repeat("Монгол ", 40)
+	writeICSLine(&buf, "DESCRIPTION:"+value)
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\r\n"), "\r\n")
+	assert.Greater(t, len(lines), 1)
+	for i, line := range lines {
+		assert.LessOrEqual(t, len(line), icsLineLimit)
+		assert.True(t, utf8.ValidString(line))
+		if i > 0 {
+			assert.True(t, strings.HasPrefix(line, " "))
+		}
+	}
+	assert.Equal(t, "DESCRIPTION:"+value, strings.ReplaceAll(strings.Join(lines, "\r\n"), "\r\n ", ""))
+}
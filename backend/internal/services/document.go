@@ -0,0 +1,226 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+
+	"github.com/sdyn/backend/internal/models"
+	"github.com/sdyn/backend/internal/repository"
+	"github.com/sdyn/backend/pkg/storage"
+)
+
+const (
+	// documentDownloadExpiry is the lifetime of presigned document URLs. It is
+	// kept short because the files are identity documents.
+	documentDownloadExpiry = 5 * time.Minute
+	// documentSniffLen is how much of a file is read to detect its type
+	documentSniffLen = 512
+)
+
+// documentTypes maps the accepted content types, as detected from the file
+// contents, to the extension used for the stored object
+var documentTypes = map[string]string{
+	"application/pdf": ".pdf",
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"image/webp":      ".webp",
+}
+
+type DocumentService struct {
+	repo       *repository.DocumentRepository
+	memberRepo *repository.MemberRepository
+	storage    *storage.Storage
+	maxSize    int64
+}
+
+// NewDocumentService creates the document service. maxSize is the largest
+// accepted file in bytes.
+func NewDocumentService(repo *repository.DocumentRepository, memberRepo *repository.MemberRepository, storage *storage.Storage, maxSize int64) *DocumentService {
+	return &DocumentService{
+		repo:       repo,
+		memberRepo: memberRepo,
+		storage:    storage,
+		maxSize:    maxSize,
+	}
+}
+
+// List returns the member's documents without download URLs
+func (s *DocumentService) List(ctx context.Context, memberID uuid.UUID) ([]models.MemberDocument, error) {
+	if _, err := s.memberRepo.GetByID(ctx, memberID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListByMember(ctx, memberID)
+}
+
+// Get returns a document with a short-lived download URL
+func (s *DocumentService) Get(ctx context.Context, memberID, id uuid.UUID) (*models.MemberDocument, error) {
+	doc, err := s.repo.GetByID(ctx, memberID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	url, err := s.storage.PresignedGetURL(ctx, doc.ObjectKey, doc.FileName, documentDownloadExpiry)
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt := time.Now().Add(documentDownloadExpiry)
+	doc.DownloadURL = &url
+	doc.DownloadExpiresAt = &expiresAt
+	return doc, nil
+}
+
+// Upload stores a document against the member. The content type is detected
+// from the file itself; the one sent by the client is ignored. size is the
+// size reported by the client and is checked again while streaming.
+func (s *DocumentService) Upload(ctx context.Context, memberID uuid.UUID, req *models.UploadDocumentRequest, fileName string, r io.Reader, size int64, uploadedBy string) (*models.MemberDocument, error) {
+	if size > s.maxSize {
+		return nil, s.tooLarge()
+	}
+	if size <= 0 {
+		return nil, &DocumentError{Message: "File is empty"}
+	}
+
+	if _, err := s.memberRepo.GetByID(ctx, memberID); err != nil {
+		return nil, err
+	}
+
+	head := make([]byte, documentSniffLen)
+	n, err := io.ReadFull(r, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, err
+	}
+	head = head[:n]
+
+	contentType, ext, err := sniffDocument(head)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := &models.MemberDocument{
+		ID:          uuid.New(),
+		MemberID:    memberID,
+		Type:        req.Type,
+		FileName:    documentFileName(fileName, ext),
+		ContentType: contentType,
+		Size:        size,
+		Description: req.Description,
+	}
+	doc.ObjectKey = fmt.Sprintf("documents/%s/%s%s", memberID, doc.ID, ext)
+	if uploadedByUUID, err := uuid.Parse(uploadedBy); err == nil {
+		doc.UploadedBy = &uploadedByUUID
+	}
+
+	// Hash while uploading, and read one byte past the limit so a body larger
+	// than the declared size is caught
+	hash := sha256.New()
+	body := &countingReader{r: io.LimitReader(io.MultiReader(bytes.NewReader(head), r), s.maxSize+1)}
+	if err := s.storage.Put(ctx, doc.ObjectKey, io.TeeReader(body, hash), -1, contentType); err != nil {
+		return nil, err
+	}
+	if body.n > s.maxSize || body.n != size {
+		s.removeObject(ctx, doc.ObjectKey)
+		if body.n > s.maxSize {
+			return nil, s.tooLarge()
+		}
+		return nil, &DocumentError{Message: "File size does not match the upload"}
+	}
+	doc.SHA256 = hex.EncodeToString(hash.Sum(nil))
+
+	if err := s.repo.Create(ctx, doc); err != nil {
+		s.removeObject(ctx, doc.ObjectKey)
+		return nil, err
+	}
+
+	return doc, nil
+}
+
+// Delete removes a document and its file
+func (s *DocumentService) Delete(ctx context.Context, memberID, id uuid.UUID) error {
+	doc, err := s.repo.Delete(ctx, memberID, id)
+	if err != nil {
+		return err
+	}
+	s.removeObject(ctx, doc.ObjectKey)
+	return nil
+}
+
+// removeObject deletes a file whose metadata is gone or was never saved. A
+// failure only leaves an orphaned object behind, so it is logged.
+func (s *DocumentService) removeObject(ctx context.Context, key string) {
+	if err := s.storage.Remove(ctx, key); err != nil {
+		log.Error().Err(err).Str("object_key", key).Msg("Failed to remove document file")
+	}
+}
+
+func (s *DocumentService) tooLarge() error {
+	return &DocumentError{Message: fmt.Sprintf("File is larger than %d MB", s.maxSize>>20)}
+}
+
+// sniffDocument detects the content type of a file from its first bytes and
+// returns it with the stored file extension
+func sniffDocument(head []byte) (string, string, error) {
+	contentType := http.DetectContentType(head)
+	if i := strings.IndexByte(contentType, ';'); i >= 0 {
+		contentType = contentType[:i]
+	}
+
+	ext, ok := documentTypes[contentType]
+	if !ok {
+		return "", "", &DocumentError{Message: "Only PDF, JPEG, PNG and WebP files are accepted"}
+	}
+	return contentType, ext, nil
+}
+
+// documentFileName cleans the client's file name for use in the download
+// Content-Disposition header. The extension always matches the detected type.
+func documentFileName(name, ext string) string {
+	name = filepath.Base(strings.ReplaceAll(name, `\`, "/"))
+	name = strings.TrimSuffix(name, filepath.Ext(name))
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == '"' || r == 0x7f {
+			return -1
+		}
+		return r
+	}, name)
+	name = strings.TrimSpace(name)
+	if name == "" || name == "." || name == "/" {
+		name = "document"
+	}
+	if len(name) > 200 {
+		name = name[:200]
+	}
+	return name + ext
+}
+
+// countingReader counts the bytes read through it
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+type DocumentError struct {
+	Message string
+}
+
+func (e *DocumentError) Error() string {
+	return e.Message
+}
@@ -0,0 +1,74 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sdyn/backend/internal/models"
+)
+
+func TestSniffDocument(t *testing.T) {
+	tests := []struct {
+		name        string
+		head        []byte
+		contentType string
+		ext         string
+	}{
+		{"pdf", []byte("%PDF-1.7\n%\xe2\xe3\xcf\xd3\n"), "application/pdf", ".pdf"},
+		{"jpeg", []byte("\xff\xd8\xff\xe0\x00\x10JFIF\x00"), "image/jpeg", ".jpg"},
+		{"png", []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"), "image/png", ".png"},
+		{"webp", []byte("RIFF\x00\x00\x00\x00WEBPVP8 "), "image/webp", ".webp"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			contentType, ext, err := sniffDocument(tt.head)
+			require.NoError(t, err)
+			assert.Equal(t, tt.contentType, contentType)
+			assert.Equal(t, tt.ext, ext)
+		})
+	}
+}
+
+func TestSniffDocumentRejectsOtherTypes(t *testing.T) {
+	for name, head := range map[string][]byte{
+		"html":       []byte("<!DOCTYPE html><html><script>alert(1)</script>"),
+		"plain text": []byte("national id scan"),
+		"zip":        []byte("PK\x03\x04\x14\x00\x00\x00"),
+		"empty":      {},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, _, err := sniffDocument(head)
+			var docErr *DocumentError
+			assert.ErrorAs(t, err, &docErr)
+		})
+	}
+}
+
+func TestDocumentFileName(t *testing.T) {
+	assert.Equal(t, "passport.pdf", documentFileName("passport.pdf", ".pdf"))
+	assert.Equal(t, "scan.jpg", documentFileName("scan.png", ".jpg"))
+	assert.Equal(t, "evil.pdf", documentFileName("../../etc/evil.pdf", ".pdf"))
+	assert.Equal(t, "evil.pdf", documentFileName(`C:\Users\me\evil.pdf`, ".pdf"))
+	assert.Equal(t, "ab.pdf", documentFileName("a\"b\r\n.pdf", ".pdf"))
+	assert.Equal(t, "document.png", documentFileName("", ".png"))
+	assert.Equal(t, "Иргэний үнэмлэх.pdf", documentFileName("Иргэний үнэмлэх.pdf", ".pdf"))
+}
+
+func TestUploadRejectsBySize(t *testing.T) {
+	s := NewDocumentService(nil, nil, nil, 1<<20)
+	req := &models.UploadDocumentRequest{Type: models.DocumentTypeIDScan}
+
+	_, err := s.Upload(context.Background(), uuid.New(), req, "scan.pdf", strings.NewReader(""), 2<<20, "")
+	var docErr *DocumentError
+	require.ErrorAs(t, err, &docErr)
+	assert.Contains(t, docErr.Message, "1 MB")
+
+	_, err = s.Upload(context.Background(), uuid.New(), req, "scan.pdf", strings.NewReader(""), 0, "")
+	require.ErrorAs(t, err, &docErr)
+}
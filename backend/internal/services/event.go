@@ -2,14 +2,43 @@ package services
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog/log"
 
 	"github.com/sdyn/backend/internal/models"
 	"github.com/sdyn/backend/internal/repository"
 )
 
+const (
+	// eventLifecycleInterval is how often events are started and completed
+	eventLifecycleInterval = time.Minute
+	// eventDefaultDuration is how long an event without an end date lasts
+	// before it is completed
+	eventDefaultDuration = 24 * time.Hour
+)
+
+// eventStatusTransitions lists the statuses each status can change to.
+// Completed and cancelled events are final.
+var eventStatusTransitions = map[models.EventStatus][]models.EventStatus{
+	models.EventStatusDraft:   {models.EventStatusPlanned, models.EventStatusCancelled},
+	models.EventStatusPlanned: {models.EventStatusDraft, models.EventStatusOngoing, models.EventStatusCancelled},
+	models.EventStatusOngoing: {models.EventStatusCompleted, models.EventStatusCancelled},
+}
+
+func checkStatusTransition(from, to models.EventStatus) error {
+	for _, allowed := range eventStatusTransitions[from] {
+		if allowed == to {
+			return nil
+		}
+	}
+	return &EventError{Message: fmt.Sprintf("Event status cannot change from %s to %s", from, to)}
+}
+
 type EventService struct {
 	repo *repository.EventRepository
 }
@@ -18,7 +47,7 @@ func NewEventService(repo *repository.EventRepository) *EventService {
 	return &EventService{repo: repo}
 }
 
-func (s *EventService) List(ctx context.Context, params *models.EventListParams) ([]models.Event, error) {
+func (s *EventService) List(ctx context.Context, params *models.EventListParams) (*models.EventListResponse, error) {
 	return s.repo.List(ctx, params)
 }
 
@@ -104,8 +133,11 @@ func (s *EventService) Update(ctx context.Context, id uuid.UUID, req *models.Upd
 	if req.Type != nil {
 		event.Type = *req.Type
 	}
-	if req.Status != nil {
-		event.Status = *req.Status
+	from := event.Status
+	if req.Status != nil && *req.Status != from {
+		if err := checkStatusTransition(from, *req.Status); err != nil {
+			return nil, err
+		}
 	}
 	if req.StartDate != nil {
 		startDate, err := time.Parse(time.RFC3339, *req.StartDate)
@@ -147,37 +179,136 @@ func (s *EventService) Update(ctx context.Context, id uuid.UUID, req *models.Upd
 		event.CoverImageURL = req.CoverImageURL
 	}
 
-	return s.repo.Update(ctx, event)
+	if req.Status == nil || *req.Status == from {
+		return s.repo.Update(ctx, event)
+	}
+
+	// The fields are only saved if the status change succeeds
+	if err := s.repo.UpdateWithStatus(ctx, event, from, *req.Status); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, &EventError{Message: "Event status has changed, please reload the event"}
+		}
+		return nil, err
+	}
+	return s.repo.GetByID(ctx, id)
 }
 
 func (s *EventService) Delete(ctx context.Context, id uuid.UUID) error {
 	return s.repo.Delete(ctx, id)
 }
 
-func (s *EventService) RegisterParticipant(ctx context.Context, eventID, memberID uuid.UUID) error {
+// RegisterParticipant registers the member for the event. Once the event is
+// full the member is put on the waitlist instead and moves up as seats free
+// up; the returned participant has the status and waitlist position.
+func (s *EventService) RegisterParticipant(ctx context.Context, eventID, memberID uuid.UUID) (*models.EventParticipant, error) {
 	// Check if event exists and registration is open
 	event, err := s.repo.GetByID(ctx, eventID)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	switch event.Status {
+	case models.EventStatusCancelled:
+		return nil, &EventError{Message: "Event has been cancelled"}
+	case models.EventStatusCompleted:
+		return nil, &EventError{Message: "Event has already ended"}
 	}
 
 	// Check deadline
 	if event.RegistrationDeadline != nil && time.Now().After(*event.RegistrationDeadline) {
-		return &EventError{Message: "Registration deadline has passed"}
+		return nil, &EventError{Message: "Registration deadline has passed"}
 	}
 
-	// Check max participants
-	if event.MaxParticipants != nil {
-		count, err := s.repo.CountParticipants(ctx, eventID)
-		if err != nil {
-			return err
+	// Capacity and the status are enforced by the repository under a lock on
+	// the event
+	if err := s.repo.RegisterParticipant(ctx, eventID, memberID); err != nil {
+		if errors.Is(err, models.ErrEventClosed) {
+			return nil, &EventError{Message: "Registration is closed for this event"}
 		}
-		if count >= *event.MaxParticipants {
-			return &EventError{Message: "Event is full"}
+		return nil, err
+	}
+
+	participant, err := s.repo.GetParticipant(ctx, eventID, memberID)
+	if err != nil {
+		return nil, err
+	}
+	if participant.Status == models.ParticipantStatusCancelled {
+		return nil, &EventError{Message: "You were removed from this event"}
+	}
+	return participant, nil
+}
+
+// CancelRegistration cancels the member's own registration or waitlist
+// place. A seat can be given up until the event's cancellation cutoff, a
+// waitlist place until the start.
+func (s *EventService) CancelRegistration(ctx context.Context, eventID, memberID uuid.UUID) (*models.EventParticipant, error) {
+	event, err := s.repo.GetByID(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	participant, err := s.repo.GetParticipant(ctx, eventID, memberID)
+	if err != nil {
+		return nil, err
+	}
+
+	settings, err := s.repo.GetSettings(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cancellationAllowed(event, participant, settings, time.Now()); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.CancelParticipant(ctx, eventID, memberID, memberID, nil); err != nil {
+		return nil, err
+	}
+	return s.repo.GetParticipant(ctx, eventID, memberID)
+}
+
+// RemoveParticipant cancels a participation on behalf of an admin. The
+// cutoff does not apply and the member cannot register again.
+func (s *EventService) RemoveParticipant(ctx context.Context, eventID, memberID uuid.UUID, req *models.RemoveParticipantRequest, removedBy string) (*models.EventParticipant, error) {
+	removedByUUID, err := uuid.Parse(removedBy)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.CancelParticipant(ctx, eventID, memberID, removedByUUID, &req.Reason); err != nil {
+		return nil, err
+	}
+	return s.repo.GetParticipant(ctx, eventID, memberID)
+}
+
+// cancellationAllowed checks a member's own cancellation against the
+// participation status, the start of the event and the cutoff
+func cancellationAllowed(event *models.Event, participant *models.EventParticipant, settings *models.EventSettings, now time.Time) error {
+	switch participant.Status {
+	case models.ParticipantStatusRegistered, models.ParticipantStatusWaitlisted:
+	case models.ParticipantStatusCancelled:
+		return &EventError{Message: "Registration is already cancelled"}
+	default:
+		return &EventError{Message: "Registration can no longer be cancelled"}
+	}
+
+	if !now.Before(event.StartDate) {
+		return &EventError{Message: "Event has already started"}
+	}
+
+	// A waitlist place holds no seat, so the cutoff does not apply
+	if participant.Status == models.ParticipantStatusRegistered {
+		cutoff := event.StartDate.Add(-time.Duration(settings.CancellationCutoffHours) * time.Hour)
+		if !now.Before(cutoff) {
+			return &EventError{Message: fmt.Sprintf("Cancellation closed %d hours before the event", settings.CancellationCutoffHours)}
 		}
 	}
+	return nil
+}
 
-	return s.repo.RegisterParticipant(ctx, eventID, memberID)
+// GetRegistration returns the member's registration for the event
+func (s *EventService) GetRegistration(ctx context.Context, eventID, memberID uuid.UUID) (*models.EventParticipant, error) {
+	return s.repo.GetParticipant(ctx, eventID, memberID)
 }
 
 func (s *EventService) MarkAttendance(ctx context.Context, eventID uuid.UUID, req *models.MarkAttendanceRequest) error {
@@ -205,6 +336,51 @@ func (s *EventService) GetReport(ctx context.Context, orgID, startDate, endDate
 	return s.repo.GetReport(ctx, orgID, startDate, endDate)
 }
 
+// RunScheduler moves events through their lifecycle until ctx is
+// cancelled: planned events become ongoing at their start and ongoing
+// events completed after their end
+func (s *EventService) RunScheduler(ctx context.Context) {
+	ticker := time.NewTicker(eventLifecycleInterval)
+	defer ticker.Stop()
+
+	log.Info().Msg("Event scheduler started")
+
+	for {
+		s.advanceLifecycle(ctx, time.Now())
+
+		select {
+		case <-ctx.Done():
+			log.Info().Msg("Event scheduler stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *EventService) advanceLifecycle(ctx context.Context, now time.Time) {
+	started, err := s.repo.StartDue(ctx, now)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Error().Err(err).Msg("Failed to start events")
+		}
+		return
+	}
+	if started > 0 {
+		log.Info().Int64("count", started).Msg("Started events")
+	}
+
+	completed, err := s.repo.CompleteDue(ctx, now, eventDefaultDuration)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Error().Err(err).Msg("Failed to complete events")
+		}
+		return
+	}
+	if completed > 0 {
+		log.Info().Int("count", completed).Msg("Completed events")
+	}
+}
+
 type EventError struct {
 	Message string
 }
@@ -0,0 +1,269 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/skip2/go-qrcode"
+
+	"github.com/sdyn/backend/internal/config"
+	"github.com/sdyn/backend/internal/models"
+	"github.com/sdyn/backend/internal/repository"
+)
+
+// ticketTokenAudience keeps event tickets from being accepted anywhere else
+const ticketTokenAudience = "event-ticket"
+
+type ticketClaims struct {
+	EventID string `json:"eid"`
+	jwt.RegisteredClaims
+}
+
+type EventCheckinService struct {
+	repo       *repository.EventRepository
+	memberRepo *repository.MemberRepository
+	ticketKey  []byte
+	cardKey    []byte
+}
+
+func NewEventCheckinService(repo *repository.EventRepository, memberRepo *repository.MemberRepository, cfg *config.Config) *EventCheckinService {
+	return &EventCheckinService{
+		repo:       repo,
+		memberRepo: memberRepo,
+		ticketKey:  deriveSigningKey(cfg.JWTSecret, ticketTokenAudience),
+		cardKey:    cardSigningKey(cfg),
+	}
+}
+
+// GetSettings returns the event's settings
+func (s *EventCheckinService) GetSettings(ctx context.Context, eventID uuid.UUID) (*models.EventSettings, error) {
+	if _, err := s.repo.GetByID(ctx, eventID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetSettings(ctx, eventID)
+}
+
+// UpdateSettings applies the provided settings
+func (s *EventCheckinService) UpdateSettings(ctx context.Context, eventID uuid.UUID, req *models.UpdateEventSettingsRequest) (*models.EventSettings, error) {
+	settings, err := s.GetSettings(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.AllowWalkIns != nil {
+		settings.AllowWalkIns = *req.AllowWalkIns
+	}
+	if req.CancellationCutoffHours != nil {
+		settings.CancellationCutoffHours = *req.CancellationCutoffHours
+	}
+
+	if err := s.repo.UpdateSettings(ctx, settings); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+// Ticket returns the signed check-in ticket of a registered participant.
+// Waitlisted participants get their ticket once they are promoted.
+func (s *EventCheckinService) Ticket(ctx context.Context, eventID, memberID uuid.UUID) (*models.EventTicket, error) {
+	participant, err := s.repo.GetParticipant(ctx, eventID, memberID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotRegistered
+		}
+		return nil, err
+	}
+	switch participant.Status {
+	case models.ParticipantStatusWaitlisted:
+		return nil, &CheckinError{Message: "You are on the waitlist"}
+	case models.ParticipantStatusCancelled:
+		return nil, ErrNotRegistered
+	}
+
+	token, err := issueTicket(eventID, memberID, s.ticketKey, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	png, err := qrcode.Encode(token, qrcode.Medium, 256)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.EventTicket{
+		EventID:  eventID,
+		MemberID: memberID,
+		Token:    token,
+		QRCode:   "data:image/png;base64," + base64.StdEncoding.EncodeToString(png),
+	}, nil
+}
+
+// CheckIn checks in the holder of a scanned QR code. An event ticket checks
+// in its registered participant. A membership card checks in a registered
+// member too, and registers an active member at the door if the event
+// allows walk-ins.
+func (s *EventCheckinService) CheckIn(ctx context.Context, eventID uuid.UUID, req *models.CheckinRequest) (*models.CheckinResult, error) {
+	event, err := s.repo.GetByID(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+	if event.Status != models.EventStatusPlanned && event.Status != models.EventStatusOngoing {
+		return nil, &CheckinError{Message: "Check-in is not open for this event"}
+	}
+
+	memberID, card, err := s.readToken(req.Token, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	allowWalkIn := false
+	if card {
+		member, err := s.memberRepo.GetByID(ctx, memberID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil, &CheckinError{Message: "Invalid ticket"}
+			}
+			return nil, err
+		}
+		if !membershipActive(member, time.Now()) {
+			return nil, &CheckinError{Message: "Membership is not active"}
+		}
+
+		settings, err := s.repo.GetSettings(ctx, eventID)
+		if err != nil {
+			return nil, err
+		}
+		allowWalkIn = settings.AllowWalkIns
+	}
+
+	checkedIn, walkIn, err := s.repo.CheckIn(ctx, eventID, memberID, allowWalkIn)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotRegistered
+		}
+		return nil, err
+	}
+	if !checkedIn {
+		return nil, ErrAlreadyCheckedIn
+	}
+
+	participant, err := s.repo.GetParticipant(ctx, eventID, memberID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.CheckinResult{Participant: *participant, WalkIn: walkIn}, nil
+}
+
+// CheckOut checks out the holder of a scanned ticket or membership card
+// at a volunteer or campaign event, crediting the hours since check-in
+func (s *EventCheckinService) CheckOut(ctx context.Context, eventID uuid.UUID, req *models.CheckinRequest) (*models.EventParticipant, error) {
+	event, err := s.repo.GetByID(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+	if !event.Type.CreditsHours() {
+		return nil, &CheckinError{Message: "Check-out is only used for volunteer and campaign events"}
+	}
+	if event.Status == models.EventStatusDraft || event.Status == models.EventStatusCancelled {
+		return nil, &CheckinError{Message: "Check-out is not open for this event"}
+	}
+
+	memberID, _, err := s.readToken(req.Token, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	participant, err := s.repo.GetParticipant(ctx, eventID, memberID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotCheckedIn
+		}
+		return nil, err
+	}
+	if participant.AttendedAt == nil {
+		return nil, ErrNotCheckedIn
+	}
+
+	now := time.Now()
+	checkedOut, err := s.repo.CheckOut(ctx, eventID, memberID, now, checkoutHours(*participant.AttendedAt, now))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotCheckedIn
+		}
+		return nil, err
+	}
+	if !checkedOut {
+		return nil, ErrAlreadyCheckedOut
+	}
+
+	return s.repo.GetParticipant(ctx, eventID, memberID)
+}
+
+// readToken returns the member a QR code belongs to and whether it was a
+// membership card rather than an event ticket
+func (s *EventCheckinService) readToken(token string, eventID uuid.UUID) (uuid.UUID, bool, error) {
+	if claims, err := parseTicket(token, s.ticketKey); err == nil {
+		if claims.EventID != eventID.String() {
+			return uuid.Nil, false, &CheckinError{Message: "Ticket is for another event"}
+		}
+		memberID, err := uuid.Parse(claims.Subject)
+		if err != nil {
+			return uuid.Nil, false, &CheckinError{Message: "Invalid ticket"}
+		}
+		return memberID, false, nil
+	}
+
+	if claims, err := parseCardToken(token, s.cardKey); err == nil {
+		memberID, err := uuid.Parse(claims.Subject)
+		if err != nil {
+			return uuid.Nil, false, &CheckinError{Message: "Invalid ticket"}
+		}
+		return memberID, true, nil
+	}
+
+	return uuid.Nil, false, &CheckinError{Message: "Invalid ticket"}
+}
+
+func issueTicket(eventID, memberID uuid.UUID, key []byte, now time.Time) (string, error) {
+	claims := ticketClaims{
+		EventID: eventID.String(),
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:  memberID.String(),
+			Audience: jwt.ClaimStrings{ticketTokenAudience},
+			IssuedAt: jwt.NewNumericDate(now),
+		},
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(key)
+}
+
+func parseTicket(token string, key []byte) (*ticketClaims, error) {
+	claims := new(ticketClaims)
+	_, err := jwt.ParseWithClaims(token, claims, func(*jwt.Token) (interface{}, error) {
+		return key, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithAudience(ticketTokenAudience))
+	if err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+var (
+	ErrNotRegistered     = errors.New("member is not registered for the event")
+	ErrAlreadyCheckedIn  = errors.New("participant has already checked in")
+	ErrNotCheckedIn      = errors.New("participant has not checked in")
+	ErrAlreadyCheckedOut = errors.New("participant has already checked out")
+)
+
+type CheckinError struct {
+	Message string
+}
+
+func (e *CheckinError) Error() string {
+	return e.Message
+}
@@ -0,0 +1,81 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sdyn/backend/internal/config"
+	"github.com/sdyn/backend/internal/models"
+)
+
+func newTestCheckinService() *EventCheckinService {
+	cfg := &config.Config{JWTSecret: "secret"}
+	return &EventCheckinService{
+		ticketKey: deriveSigningKey(cfg.JWTSecret, ticketTokenAudience),
+		cardKey:   cardSigningKey(cfg),
+	}
+}
+
+func TestReadTokenTicket(t *testing.T) {
+	s := newTestCheckinService()
+	eventID, memberID := uuid.New(), uuid.New()
+
+	token, err := issueTicket(eventID, memberID, s.ticketKey, time.Now())
+	require.NoError(t, err)
+
+	got, card, err := s.readToken(token, eventID)
+	require.NoError(t, err)
+	assert.Equal(t, memberID, got)
+	assert.False(t, card)
+
+	var checkinErr *CheckinError
+	_, _, err = s.readToken(token, uuid.New())
+	require.ErrorAs(t, err, &checkinErr)
+	assert.Equal(t, "Ticket is for another event", checkinErr.Message)
+}
+
+func TestReadTokenMembershipCard(t *testing.T) {
+	s := newTestCheckinService()
+	member := &models.Member{ID: uuid.New(), MemberID: "SDYN-2026-00042"}
+
+	cards := &MemberCardService{key: s.cardKey}
+	token, err := cards.issueToken(member, time.Now())
+	require.NoError(t, err)
+
+	got, card, err := s.readToken(token, uuid.New())
+	require.NoError(t, err)
+	assert.Equal(t, member.ID, got)
+	assert.True(t, card)
+}
+
+func TestReadTokenRejectsForeignTokens(t *testing.T) {
+	s := newTestCheckinService()
+	eventID := uuid.New()
+
+	// Signed with another secret
+	other := deriveSigningKey("other", ticketTokenAudience)
+	forged, err := issueTicket(eventID, uuid.New(), other, time.Now())
+	require.NoError(t, err)
+
+	// A card token signed with the ticket key is not a card
+	cards := &MemberCardService{key: s.ticketKey}
+	swapped, err := cards.issueToken(&models.Member{ID: uuid.New()}, time.Now())
+	require.NoError(t, err)
+
+	for name, token := range map[string]string{
+		"forged":  forged,
+		"swapped": swapped,
+		"garbage": "not-a-token",
+	} {
+		t.Run(name, func(t *testing.T) {
+			var checkinErr *CheckinError
+			_, _, err := s.readToken(token, eventID)
+			require.ErrorAs(t, err, &checkinErr)
+			assert.Equal(t, "Invalid ticket", checkinErr.Message)
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sdyn/backend/internal/models"
+)
+
+func TestCancellationAllowed(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	event := &models.Event{StartDate: now.Add(10 * time.Hour)}
+
+	tests := []struct {
+		name        string
+		status      models.ParticipantStatus
+		cutoffHours int
+		start       time.Time
+		wantErr     string
+	}{
+		{"registered before cutoff", models.ParticipantStatusRegistered, 6, event.StartDate, ""},
+		{"registered after cutoff", models.ParticipantStatusRegistered, 24, event.StartDate, "Cancellation closed 24 hours before the event"},
+		{"waitlisted ignores cutoff", models.ParticipantStatusWaitlisted, 24, event.StartDate, ""},
+		{"no cutoff until start", models.ParticipantStatusRegistered, 0, event.StartDate, ""},
+		{"started", models.ParticipantStatusWaitlisted, 0, now, "Event has already started"},
+		{"attended", models.ParticipantStatusAttended, 0, event.StartDate, "Registration can no longer be cancelled"},
+		{"cancelled", models.ParticipantStatusCancelled, 0, event.StartDate, "Registration is already cancelled"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := cancellationAllowed(
+				&models.Event{StartDate: tt.start},
+				&models.EventParticipant{Status: tt.status},
+				&models.EventSettings{CancellationCutoffHours: tt.cutoffHours},
+				now,
+			)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			var eventErr *EventError
+			require.ErrorAs(t, err, &eventErr)
+			assert.Equal(t, tt.wantErr, eventErr.Message)
+		})
+	}
+}
@@ -0,0 +1,292 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog/log"
+
+	"github.com/sdyn/backend/internal/config"
+	"github.com/sdyn/backend/internal/models"
+	"github.com/sdyn/backend/internal/repository"
+)
+
+const (
+	// defaultSeriesTimezone is used when a series is created without one
+	defaultSeriesTimezone = "Asia/Ulaanbaatar"
+	// eventSeriesGenerateInterval is how often occurrences are created for
+	// the moving horizon
+	eventSeriesGenerateInterval = time.Hour
+)
+
+type EventSeriesService struct {
+	repo    *repository.EventSeriesRepository
+	events  *EventService
+	horizon time.Duration
+}
+
+func NewEventSeriesService(repo *repository.EventSeriesRepository, events *EventService, cfg *config.Config) *EventSeriesService {
+	return &EventSeriesService{
+		repo:    repo,
+		events:  events,
+		horizon: time.Duration(cfg.EventSeriesHorizonDays) * 24 * time.Hour,
+	}
+}
+
+// GetByID returns the series with its generated occurrences
+func (s *EventSeriesService) GetByID(ctx context.Context, id uuid.UUID) (*models.EventSeries, error) {
+	series, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	series.Occurrences, err = s.repo.Occurrences(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return series, nil
+}
+
+// Create saves the series and creates its occurrences up to the horizon
+func (s *EventSeriesService) Create(ctx context.Context, req *models.CreateEventSeriesRequest, organizerID string) (*models.EventSeries, error) {
+	startDate, err := time.Parse(time.RFC3339, req.StartDate)
+	if err != nil {
+		return nil, &EventError{Message: "Invalid start date"}
+	}
+	if _, err := parseRecurrence(req.Recurrence); err != nil {
+		return nil, &EventError{Message: "Invalid recurrence: " + err.Error()}
+	}
+
+	series := &models.EventSeries{
+		Title:           req.Title,
+		Description:     req.Description,
+		Type:            req.Type,
+		Status:          models.EventStatusDraft,
+		Location:        req.Location,
+		Address:         req.Address,
+		IsOnline:        req.IsOnline,
+		OnlineURL:       req.OnlineURL,
+		MaxParticipants: req.MaxParticipants,
+		IsPublic:        req.IsPublic,
+		CoverImageURL:   req.CoverImageURL,
+		Recurrence:      req.Recurrence,
+		StartDate:       startDate,
+		Timezone:        defaultSeriesTimezone,
+	}
+
+	if req.Status != "" {
+		series.Status = req.Status
+	}
+	if req.Timezone != nil {
+		if _, err := time.LoadLocation(*req.Timezone); err != nil || *req.Timezone == "" {
+			return nil, &EventError{Message: "Unknown time zone"}
+		}
+		series.Timezone = *req.Timezone
+	}
+	if req.EndDate != nil {
+		endDate, err := time.Parse(time.RFC3339, *req.EndDate)
+		if err != nil || !endDate.After(startDate) {
+			return nil, &EventError{Message: "End date must be after the start date"}
+		}
+		minutes := int(endDate.Sub(startDate) / time.Minute)
+		series.DurationMinutes = &minutes
+	}
+
+	// Parse organization ID
+	if req.OrganizationID != nil {
+		id, err := uuid.Parse(*req.OrganizationID)
+		if err == nil {
+			series.OrganizationID = &id
+		}
+	}
+
+	// Set organizer
+	orgID, err := uuid.Parse(organizerID)
+	if err == nil {
+		series.OrganizerID = &orgID
+	}
+
+	if err := s.repo.Create(ctx, series); err != nil {
+		return nil, err
+	}
+	if _, err := s.generate(ctx, series, time.Now()); err != nil {
+		return nil, err
+	}
+
+	return s.GetByID(ctx, series.ID)
+}
+
+// Update changes an event. With scope this only the event changes and, if
+// it belongs to a series, it is detached so later series edits leave it
+// alone. With scope future the change is saved to the series and applied to
+// the event and every later occurrence that is not detached.
+func (s *EventSeriesService) Update(ctx context.Context, eventID uuid.UUID, req *models.UpdateEventRequest, scope string) (*models.Event, error) {
+	if scope == models.EventUpdateScopeFuture {
+		return s.updateFuture(ctx, eventID, req)
+	}
+
+	event, err := s.events.Update(ctx, eventID, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.Detach(ctx, eventID); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+func (s *EventSeriesService) updateFuture(ctx context.Context, eventID uuid.UUID, req *models.UpdateEventRequest) (*models.Event, error) {
+	occurrence, err := s.repo.GetOccurrence(ctx, eventID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, &EventError{Message: "Event is not part of a series"}
+		}
+		return nil, err
+	}
+	if req.Status != nil || req.RegistrationDeadline != nil {
+		return nil, &EventError{Message: "Status and registration deadline can only be changed for a single occurrence"}
+	}
+
+	series, err := s.repo.GetByID(ctx, occurrence.SeriesID)
+	if err != nil {
+		return nil, err
+	}
+	loc, err := time.LoadLocation(series.Timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Title != nil {
+		series.Title = *req.Title
+	}
+	if req.Description != nil {
+		series.Description = req.Description
+	}
+	if req.Type != nil {
+		series.Type = *req.Type
+	}
+	if req.Location != nil {
+		series.Location = req.Location
+	}
+	if req.Address != nil {
+		series.Address = req.Address
+	}
+	if req.IsOnline != nil {
+		series.IsOnline = *req.IsOnline
+	}
+	if req.OnlineURL != nil {
+		series.OnlineURL = req.OnlineURL
+	}
+	if req.MaxParticipants != nil {
+		series.MaxParticipants = req.MaxParticipants
+	}
+	if req.IsPublic != nil {
+		series.IsPublic = *req.IsPublic
+	}
+	if req.CoverImageURL != nil {
+		series.CoverImageURL = req.CoverImageURL
+	}
+
+	// The occurrence may only move within its day, otherwise the following
+	// occurrences would fall on other days than the rule gives
+	start := occurrence.OriginalStart
+	if req.StartDate != nil {
+		start, err = time.Parse(time.RFC3339, *req.StartDate)
+		if err != nil {
+			return nil, &EventError{Message: "Invalid start date"}
+		}
+		if !sameDay(start.In(loc), occurrence.OriginalStart.In(loc)) {
+			return nil, &EventError{Message: "Following occurrences can only be moved within their day"}
+		}
+	}
+	if req.EndDate != nil {
+		endDate, err := time.Parse(time.RFC3339, *req.EndDate)
+		if err != nil || !endDate.After(start) {
+			return nil, &EventError{Message: "End date must be after the start date"}
+		}
+		minutes := int(endDate.Sub(start) / time.Minute)
+		series.DurationMinutes = &minutes
+	}
+
+	shift := start.Sub(occurrence.OriginalStart)
+	if err := s.repo.UpdateFuture(ctx, series, eventID, occurrence.OriginalStart, shift); err != nil {
+		return nil, err
+	}
+
+	return s.events.GetByID(ctx, eventID)
+}
+
+func sameDay(a, b time.Time) bool {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.Date()
+	return ay == by && am == bm && ad == bd
+}
+
+// generate creates the occurrences of the series that are not generated
+// yet, up to the horizon from now
+func (s *EventSeriesService) generate(ctx context.Context, series *models.EventSeries, now time.Time) (int, error) {
+	rule, err := parseRecurrence(series.Recurrence)
+	if err != nil {
+		return 0, err
+	}
+	loc, err := time.LoadLocation(series.Timezone)
+	if err != nil {
+		return 0, err
+	}
+
+	horizon := now.Add(s.horizon)
+	after := series.StartDate.Add(-time.Nanosecond)
+	if series.GeneratedUntil != nil {
+		after = *series.GeneratedUntil
+	}
+
+	starts := rule.between(series.StartDate.In(loc), after, horizon)
+	return s.repo.AddOccurrences(ctx, series, starts, horizon)
+}
+
+// RunGenerator keeps the occurrences of every series created up to the
+// horizon until ctx is cancelled
+func (s *EventSeriesService) RunGenerator(ctx context.Context) {
+	ticker := time.NewTicker(eventSeriesGenerateInterval)
+	defer ticker.Stop()
+
+	log.Info().Msg("Event series generator started")
+
+	for {
+		s.generateDue(ctx)
+
+		select {
+		case <-ctx.Done():
+			log.Info().Msg("Event series generator stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *EventSeriesService) generateDue(ctx context.Context) {
+	now := time.Now()
+	due, err := s.repo.ListDue(ctx, now.Add(s.horizon))
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Error().Err(err).Msg("Failed to list event series")
+		}
+		return
+	}
+
+	for i := range due {
+		n, err := s.generate(ctx, &due[i], now)
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Error().Err(err).Str("series_id", due[i].ID.String()).Msg("Failed to generate event occurrences")
+			}
+			continue
+		}
+		if n > 0 {
+			log.Info().Int("count", n).Str("series_id", due[i].ID.String()).Msg("Generated event occurrences")
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sdyn/backend/internal/models"
+)
+
+func TestCheckStatusTransition(t *testing.T) {
+	tests := []struct {
+		from, to models.EventStatus
+		allowed  bool
+	}{
+		{models.EventStatusDraft, models.EventStatusPlanned, true},
+		{models.EventStatusDraft, models.EventStatusCancelled, true},
+		{models.EventStatusDraft, models.EventStatusOngoing, false},
+		{models.EventStatusDraft, models.EventStatusCompleted, false},
+		{models.EventStatusPlanned, models.EventStatusDraft, true},
+		{models.EventStatusPlanned, models.EventStatusOngoing, true},
+		{models.EventStatusPlanned, models.EventStatusCancelled, true},
+		{models.EventStatusPlanned, models.EventStatusCompleted, false},
+		{models.EventStatusOngoing, models.EventStatusCompleted, true},
+		{models.EventStatusOngoing, models.EventStatusCancelled, true},
+		{models.EventStatusOngoing, models.EventStatusPlanned, false},
+		{models.EventStatusCompleted, models.EventStatusOngoing, false},
+		{models.EventStatusCancelled, models.EventStatusPlanned, false},
+	}
+
+	for _, tt := range tests {
+		err := checkStatusTransition(tt.from, tt.to)
+		if tt.allowed {
+			assert.NoError(t, err, "%s -> %s", tt.from, tt.to)
+			continue
+		}
+		var eventErr *EventError
+		assert.ErrorAs(t, err, &eventErr, "%s -> %s", tt.from, tt.to)
+	}
+}
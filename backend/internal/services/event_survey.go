@@ -0,0 +1,202 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/sdyn/backend/internal/models"
+	"github.com/sdyn/backend/internal/repository"
+)
+
+// ErrNotAttended is returned when a member who did not attend an event
+// submits its survey
+var ErrNotAttended = errors.New("member did not attend the event")
+
+type EventSurveyService struct {
+	repo *repository.EventRepository
+}
+
+func NewEventSurveyService(repo *repository.EventRepository) *EventSurveyService {
+	return &EventSurveyService{repo: repo}
+}
+
+// Get returns the event's survey. It returns pgx.ErrNoRows if the event
+// has no survey.
+func (s *EventSurveyService) Get(ctx context.Context, eventID uuid.UUID) (*models.EventSurvey, error) {
+	survey, err := s.repo.GetSurvey(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+	if len(survey.Questions) == 0 {
+		return nil, pgx.ErrNoRows
+	}
+	return survey, nil
+}
+
+// Update replaces the event's survey questions. A survey cannot change
+// once it has responses.
+func (s *EventSurveyService) Update(ctx context.Context, eventID uuid.UUID, req *models.UpdateEventSurveyRequest) (*models.EventSurvey, error) {
+	questions, err := buildSurveyQuestions(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.ReplaceSurvey(ctx, eventID, questions); err != nil {
+		return nil, err
+	}
+	return &models.EventSurvey{EventID: eventID, Questions: questions}, nil
+}
+
+// Submit saves the member's answers. Only participants marked as attended
+// can submit, and only once.
+func (s *EventSurveyService) Submit(ctx context.Context, eventID, memberID uuid.UUID, req *models.SubmitSurveyRequest) (*models.SurveyResponse, error) {
+	survey, err := s.Get(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	participant, err := s.repo.GetParticipant(ctx, eventID, memberID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotAttended
+		}
+		return nil, err
+	}
+	if participant.Status != models.ParticipantStatusAttended {
+		return nil, ErrNotAttended
+	}
+
+	answers, err := validateSurveyAnswers(survey.Questions, req.Answers)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := s.repo.SubmitSurvey(ctx, eventID, memberID, answers)
+	if err != nil {
+		// Attendance was changed in the meantime
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotAttended
+		}
+		return nil, err
+	}
+	return response, nil
+}
+
+// Results returns the aggregated answers to the event's survey
+func (s *EventSurveyService) Results(ctx context.Context, eventID uuid.UUID) (*models.SurveyResults, error) {
+	if _, err := s.Get(ctx, eventID); err != nil {
+		return nil, err
+	}
+	return s.repo.SurveyResults(ctx, eventID)
+}
+
+// buildSurveyQuestions checks the question options and numbers the
+// questions from 1
+func buildSurveyQuestions(req *models.UpdateEventSurveyRequest) ([]models.SurveyQuestion, error) {
+	questions := make([]models.SurveyQuestion, 0, len(req.Questions))
+	for i, in := range req.Questions {
+		prompt := strings.TrimSpace(in.Prompt)
+		if prompt == "" {
+			return nil, &EventError{Message: fmt.Sprintf("Question %d has no prompt", i+1)}
+		}
+
+		options := []string{}
+		switch in.Type {
+		case models.SurveyQuestionChoice:
+			seen := map[string]bool{}
+			for _, option := range in.Options {
+				option = strings.TrimSpace(option)
+				if option == "" || seen[option] {
+					return nil, &EventError{Message: fmt.Sprintf("Question %d has an empty or repeated option", i+1)}
+				}
+				seen[option] = true
+				options = append(options, option)
+			}
+			if len(options) < 2 {
+				return nil, &EventError{Message: fmt.Sprintf("Question %d needs at least two options", i+1)}
+			}
+		default:
+			if len(in.Options) > 0 {
+				return nil, &EventError{Message: fmt.Sprintf("Only choice questions have options (question %d)", i+1)}
+			}
+		}
+
+		questions = append(questions, models.SurveyQuestion{
+			Position: i + 1,
+			Type:     in.Type,
+			Prompt:   prompt,
+			Options:  options,
+			Required: in.Required,
+		})
+	}
+	return questions, nil
+}
+
+// validateSurveyAnswers checks the answers against the questions and
+// returns them with only the field of each question's type set. Empty text
+// answers are dropped.
+func validateSurveyAnswers(questions []models.SurveyQuestion, answers []models.SurveyAnswer) ([]models.SurveyAnswer, error) {
+	byID := make(map[uuid.UUID]models.SurveyQuestion, len(questions))
+	for _, q := range questions {
+		byID[q.ID] = q
+	}
+
+	answered := map[uuid.UUID]bool{}
+	valid := make([]models.SurveyAnswer, 0, len(answers))
+	for _, a := range answers {
+		q, ok := byID[a.QuestionID]
+		if !ok {
+			return nil, &EventError{Message: "Answer to an unknown question"}
+		}
+		if answered[q.ID] {
+			return nil, &EventError{Message: fmt.Sprintf("Question %d is answered twice", q.Position)}
+		}
+
+		answer := models.SurveyAnswer{QuestionID: q.ID}
+		switch q.Type {
+		case models.SurveyQuestionRating:
+			if a.Rating == nil || *a.Rating < models.MinSurveyRating || *a.Rating > models.MaxSurveyRating {
+				return nil, &EventError{Message: fmt.Sprintf("Question %d needs a rating from %d to %d", q.Position, models.MinSurveyRating, models.MaxSurveyRating)}
+			}
+			answer.Rating = a.Rating
+		case models.SurveyQuestionChoice:
+			if a.Choice == nil || !containsString(q.Options, *a.Choice) {
+				return nil, &EventError{Message: fmt.Sprintf("Question %d needs one of its options", q.Position)}
+			}
+			answer.Choice = a.Choice
+		case models.SurveyQuestionText:
+			if a.Text == nil {
+				return nil, &EventError{Message: fmt.Sprintf("Question %d needs a text answer", q.Position)}
+			}
+			text := strings.TrimSpace(*a.Text)
+			if text == "" {
+				continue
+			}
+			answer.Text = &text
+		}
+
+		answered[q.ID] = true
+		valid = append(valid, answer)
+	}
+
+	for _, q := range questions {
+		if q.Required && !answered[q.ID] {
+			return nil, &EventError{Message: fmt.Sprintf("Question %d is required", q.Position)}
+		}
+	}
+	return valid, nil
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
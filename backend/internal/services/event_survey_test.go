@@ -0,0 +1,79 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sdyn/backend/internal/models"
+)
+
+func TestBuildSurveyQuestions(t *testing.T) {
+	questions, err := buildSurveyQuestions(&models.UpdateEventSurveyRequest{
+		Questions: []models.SurveyQuestionInput{
+			{Type: models.SurveyQuestionRating, Prompt: " Overall ", Required: true},
+			{Type: models.SurveyQuestionChoice, Prompt: "Best part", Options: []string{"Talks ", "Workshops"}},
+			{Type: models.SurveyQuestionText, Prompt: "Comments"},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, questions, 3)
+	assert.Equal(t, 1, questions[0].Position)
+	assert.Equal(t, "Overall", questions[0].Prompt)
+	assert.Equal(t, []string{}, questions[0].Options)
+	assert.Equal(t, []string{"Talks", "Workshops"}, questions[1].Options)
+	assert.Equal(t, 3, questions[2].Position)
+
+	for name, in := range map[string]models.SurveyQuestionInput{
+		"one option":      {Type: models.SurveyQuestionChoice, Prompt: "Pick", Options: []string{"Yes"}},
+		"repeated option": {Type: models.SurveyQuestionChoice, Prompt: "Pick", Options: []string{"Yes", " Yes"}},
+		"rating options":  {Type: models.SurveyQuestionRating, Prompt: "Rate", Options: []string{"1"}},
+		"blank prompt":    {Type: models.SurveyQuestionText, Prompt: "  "},
+	} {
+		_, err := buildSurveyQuestions(&models.UpdateEventSurveyRequest{Questions: []models.SurveyQuestionInput{in}})
+		var eventErr *EventError
+		assert.ErrorAs(t, err, &eventErr, name)
+	}
+}
+
+func TestValidateSurveyAnswers(t *testing.T) {
+	rating := models.SurveyQuestion{ID: uuid.New(), Position: 1, Type: models.SurveyQuestionRating, Required: true}
+	choice := models.SurveyQuestion{ID: uuid.New(), Position: 2, Type: models.SurveyQuestionChoice, Options: []string{"Yes", "No"}}
+	text := models.SurveyQuestion{ID: uuid.New(), Position: 3, Type: models.SurveyQuestionText}
+	questions := []models.SurveyQuestion{rating, choice, text}
+
+	four, six := 4, 6
+	yes, maybe := "Yes", "Maybe"
+	comment, blank := " Great event ", "  "
+
+	answers, err := validateSurveyAnswers(questions, []models.SurveyAnswer{
+		{QuestionID: rating.ID, Rating: &four, Choice: &yes},
+		{QuestionID: choice.ID, Choice: &yes},
+		{QuestionID: text.ID, Text: &comment},
+	})
+	require.NoError(t, err)
+	require.Len(t, answers, 3)
+	assert.Nil(t, answers[0].Choice)
+	assert.Equal(t, "Great event", *answers[2].Text)
+
+	answers, err = validateSurveyAnswers(questions, []models.SurveyAnswer{
+		{QuestionID: rating.ID, Rating: &four},
+		{QuestionID: text.ID, Text: &blank},
+	})
+	require.NoError(t, err)
+	assert.Len(t, answers, 1)
+
+	for name, in := range map[string][]models.SurveyAnswer{
+		"required missing": {{QuestionID: choice.ID, Choice: &yes}},
+		"rating range":     {{QuestionID: rating.ID, Rating: &six}},
+		"unknown option":   {{QuestionID: rating.ID, Rating: &four}, {QuestionID: choice.ID, Choice: &maybe}},
+		"unknown question": {{QuestionID: rating.ID, Rating: &four}, {QuestionID: uuid.New(), Text: &comment}},
+		"answered twice":   {{QuestionID: rating.ID, Rating: &four}, {QuestionID: rating.ID, Rating: &four}},
+	} {
+		_, err := validateSurveyAnswers(questions, in)
+		var eventErr *EventError
+		assert.ErrorAs(t, err, &eventErr, name)
+	}
+}
@@ -0,0 +1,552 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+
+	"github.com/sdyn/backend/internal/models"
+	"github.com/sdyn/backend/internal/repository"
+)
+
+// exportBatchSize is the page size used when paging through members
+const exportBatchSize = 500
+
+type ExportService struct {
+	memberRepo *repository.MemberRepository
+	orgRepo    *repository.OrganizationRepository
+	eventRepo  *repository.EventRepository
+	feeRepo    *repository.FeeRepository
+}
+
+func NewExportService(
+	memberRepo *repository.MemberRepository,
+	orgRepo *repository.OrganizationRepository,
+	eventRepo *repository.EventRepository,
+	feeRepo *repository.FeeRepository,
+) *ExportService {
+	return &ExportService{
+		memberRepo: memberRepo,
+		orgRepo:    orgRepo,
+		eventRepo:  eventRepo,
+		feeRepo:    feeRepo,
+	}
+}
+
+// exportColumn describes a single exportable column of T
+type exportColumn[T any] struct {
+	key    string
+	header string
+	value  func(*T) string
+}
+
+var memberExportColumns = []exportColumn[models.Member]{
+	{"member_id", "Member ID", func(m *models.Member) string { return m.MemberID }},
+	{"last_name", "Last name", func(m *models.Member) string { return m.LastName }},
+	{"first_name", "First name", func(m *models.Member) string { return m.FirstName }},
+	{"gender", "Gender", func(m *models.Member) string { return formatEnum(m.Gender) }},
+	{"birth_date", "Birth date", func(m *models.Member) string { return formatDate(m.BirthDate) }},
+	{"national_id", "National ID", func(m *models.Member) string { return formatString(m.NationalID) }},
+	{"email", "Email", func(m *models.Member) string { return formatString(m.Email) }},
+	{"phone", "Phone", func(m *models.Member) string { return formatString(m.Phone) }},
+	{"address", "Address", func(m *models.Member) string { return formatString(m.Address) }},
+	{"province", "Province", func(m *models.Member) string { return formatString(m.ProvinceName) }},
+	{"district", "District", func(m *models.Member) string { return formatString(m.DistrictName) }},
+	{"organization", "Organization", func(m *models.Member) string { return formatString(m.OrganizationName) }},
+	{"education", "Education", func(m *models.Member) string { return formatEnum(m.Education) }},
+	{"occupation", "Occupation", func(m *models.Member) string { return formatString(m.Occupation) }},
+	{"workplace", "Workplace", func(m *models.Member) string { return formatString(m.Workplace) }},
+	{"status", "Status", func(m *models.Member) string { return string(m.Status) }},
+	{"joined_at", "Joined at", func(m *models.Member) string { return formatDate(m.JoinedAt) }},
+	{"membership_expires_at", "Membership expires at", func(m *models.Member) string { return formatDate(m.MembershipExpiresAt) }},
+	{"created_at", "Created at", func(m *models.Member) string { return formatTime(&m.CreatedAt) }},
+}
+
+var feeExportColumns = []exportColumn[models.MembershipFee]{
+	{"member_id", "Member ID", func(f *models.MembershipFee) string { return f.MemberMID }},
+	{"member_name", "Member name", func(f *models.MembershipFee) string { return f.MemberName }},
+	{"organization", "Organization", func(f *models.MembershipFee) string { return formatString(f.Organization) }},
+	{"year", "Year", func(f *models.MembershipFee) string { return strconv.Itoa(f.Year) }},
+	{"month", "Month", func(f *models.MembershipFee) string { return formatInt(f.Month) }},
+	{"amount", "Amount", func(f *models.MembershipFee) string { return strconv.FormatFloat(f.Amount, 'f', 2, 64) }},
+	{"status", "Status", func(f *models.MembershipFee) string { return string(f.Status) }},
+	{"paid_at", "Paid at", func(f *models.MembershipFee) string { return formatTime(f.PaidAt) }},
+	{"payment_method", "Payment method", func(f *models.MembershipFee) string { return formatString(f.PaymentMethod) }},
+	{"receipt_number", "Receipt number", func(f *models.MembershipFee) string { return formatString(f.ReceiptNumber) }},
+	{"member_email", "Email", func(f *models.MembershipFee) string { return formatString(f.MemberEmail) }},
+	{"member_phone", "Phone", func(f *models.MembershipFee) string { return formatString(f.MemberPhone) }},
+}
+
+var eventExportColumns = []exportColumn[models.Event]{
+	{"title", "Title", func(e *models.Event) string { return e.Title }},
+	{"type", "Type", func(e *models.Event) string { return string(e.Type) }},
+	{"status", "Status", func(e *models.Event) string { return string(e.Status) }},
+	{"organization", "Organization", func(e *models.Event) string { return formatString(e.OrganizationName) }},
+	{"start_date", "Start date", func(e *models.Event) string { return formatTime(&e.StartDate) }},
+	{"end_date", "End date", func(e *models.Event) string { return formatTime(e.EndDate) }},
+	{"location", "Location", func(e *models.Event) string { return formatString(e.Location) }},
+	{"is_online", "Online", func(e *models.Event) string { return strconv.FormatBool(e.IsOnline) }},
+	{"max_participants", "Max participants", func(e *models.Event) string { return formatInt(e.MaxParticipants) }},
+	{"total_registered", "Registered", func(e *models.Event) string { return strconv.Itoa(e.TotalRegistered) }},
+	{"total_attended", "Attended", func(e *models.Event) string { return strconv.Itoa(e.TotalAttended) }},
+	{"organizer", "Organizer", func(e *models.Event) string { return formatString(e.OrganizerName) }},
+}
+
+var organizationExportColumns = []exportColumn[models.Organization]{
+	{"name", "Name", func(o *models.Organization) string { return o.Name }},
+	{"code", "Code", func(o *models.Organization) string { return formatString(o.Code) }},
+	{"level", "Level", func(o *models.Organization) string { return string(o.Level) }},
+	{"province", "Province", func(o *models.Organization) string { return formatString(o.ProvinceName) }},
+	{"district", "District", func(o *models.Organization) string { return formatString(o.DistrictName) }},
+	{"address", "Address", func(o *models.Organization) string { return formatString(o.Address) }},
+	{"phone", "Phone", func(o *models.Organization) string { return formatString(o.Phone) }},
+	{"email", "Email", func(o *models.Organization) string { return formatString(o.Email) }},
+	{"established_at", "Established at", func(o *models.Organization) string { return formatDate(o.EstablishedAt) }},
+	{"total_members", "Total members", func(o *models.Organization) string { return strconv.Itoa(o.TotalMembers) }},
+	{"active_members", "Active members", func(o *models.Organization) string { return strconv.Itoa(o.ActiveMembers) }},
+}
+
+// ValidateExportParams normalizes and validates export params before any
+// output is written, so that errors can still be reported to the client.
+func ValidateExportParams(params *models.ExportParams) error {
+	if params.Format == "" {
+		params.Format = models.ExportFormatCSV
+	}
+	if params.Format != models.ExportFormatCSV && params.Format != models.ExportFormatXLSX {
+		return &ExportError{Message: "Invalid format. Valid formats: csv, xlsx"}
+	}
+
+	for _, date := range []*string{params.DateFrom, params.DateTo} {
+		if date == nil {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", *date); err != nil {
+			return &ExportError{Message: "Invalid date, expected YYYY-MM-DD"}
+		}
+	}
+
+	var err error
+	switch params.Type {
+	case models.ExportTypeMembers:
+		if params.Year != nil {
+			return &ExportError{Message: "Year is only supported for fees and events exports"}
+		}
+		if params.Status != nil {
+			switch models.MemberStatus(*params.Status) {
+			case models.MemberStatusPending, models.MemberStatusActive, models.MemberStatusInactive,
+				models.MemberStatusSuspended, models.MemberStatusExpelled:
+			default:
+				return &ExportError{Message: "Invalid member status"}
+			}
+		}
+		_, err = selectColumns(memberExportColumns, params.Columns)
+	case models.ExportTypeFees:
+		if params.Status != nil {
+			switch models.PaymentStatus(*params.Status) {
+			case models.PaymentStatusPending, models.PaymentStatusPaid, models.PaymentStatusOverdue, models.PaymentStatusWaived:
+			default:
+				return &ExportError{Message: "Invalid payment status"}
+			}
+		}
+		_, err = selectColumns(feeExportColumns, params.Columns)
+	case models.ExportTypeEvents:
+		if params.Status != nil {
+			switch models.EventStatus(*params.Status) {
+			case models.EventStatusDraft, models.EventStatusPlanned, models.EventStatusOngoing,
+				models.EventStatusCompleted, models.EventStatusCancelled:
+			default:
+				return &ExportError{Message: "Invalid event status"}
+			}
+		}
+		_, err = selectColumns(eventExportColumns, params.Columns)
+	case models.ExportTypeOrganizations:
+		if params.Year != nil {
+			return &ExportError{Message: "Year is only supported for fees and events exports"}
+		}
+		if params.Status != nil && *params.Status != "active" && *params.Status != "inactive" {
+			return &ExportError{Message: "Invalid organization status. Valid statuses: active, inactive"}
+		}
+		_, err = selectColumns(organizationExportColumns, params.Columns)
+	default:
+		return &ExportError{Message: "Invalid report type. Valid types: members, fees, events, organizations"}
+	}
+
+	return err
+}
+
+// ExportFileName returns the download file name for an export created at t
+func ExportFileName(params *models.ExportParams, t time.Time) string {
+	return fmt.Sprintf("%s-%s.%s", params.Type, t.Format("20060102-150405"), params.Format)
+}
+
+// Export writes the requested export file to w. Params must have been
+// validated with ValidateExportParams.
+func (s *ExportService) Export(ctx context.Context, w io.Writer, params *models.ExportParams) error {
+	tw, err := newTableWriter(w, params.Format, string(params.Type))
+	if err != nil {
+		return err
+	}
+
+	switch params.Type {
+	case models.ExportTypeMembers:
+		err = s.exportMembers(ctx, tw, params)
+	case models.ExportTypeFees:
+		err = s.exportFees(ctx, tw, params)
+	case models.ExportTypeEvents:
+		err = s.exportEvents(ctx, tw, params)
+	case models.ExportTypeOrganizations:
+		err = s.exportOrganizations(ctx, tw, params)
+	default:
+		err = &ExportError{Message: "Invalid report type"}
+	}
+	if err != nil {
+		return err
+	}
+
+	return tw.Close()
+}
+
+func (s *ExportService) exportMembers(ctx context.Context, tw tableWriter, params *models.ExportParams) error {
+	columns, err := selectColumns(memberExportColumns, params.Columns)
+	if err != nil {
+		return err
+	}
+	if err := writeHeader(tw, columns); err != nil {
+		return err
+	}
+
+	listParams := &models.MemberListParams{
+		Page:           1,
+		Limit:          exportBatchSize,
+		OrganizationID: params.OrganizationID,
+		ProvinceID:     params.ProvinceID,
+		DistrictID:     params.DistrictID,
+		CreatedFrom:    params.DateFrom,
+		CreatedTo:      params.DateTo,
+		SortBy:         "created_at",
+		SortOrder:      "asc",
+		Total:          models.TotalNone,
+	}
+	if params.Status != nil {
+		status := models.MemberStatus(*params.Status)
+		listParams.Status = &status
+	}
+
+	for {
+		result, err := s.memberRepo.List(ctx, listParams)
+		if err != nil {
+			return err
+		}
+		for i := range result.Members {
+			if err := writeRow(tw, columns, &result.Members[i]); err != nil {
+				return err
+			}
+		}
+		if result.NextCursor == nil {
+			return nil
+		}
+		listParams.Cursor = *result.NextCursor
+	}
+}
+
+func (s *ExportService) exportFees(ctx context.Context, tw tableWriter, params *models.ExportParams) error {
+	columns, err := selectColumns(feeExportColumns, params.Columns)
+	if err != nil {
+		return err
+	}
+	if err := writeHeader(tw, columns); err != nil {
+		return err
+	}
+
+	listParams := &models.FeeListParams{
+		Limit:          exportBatchSize,
+		Year:           params.Year,
+		OrganizationID: params.OrganizationID,
+		ProvinceID:     params.ProvinceID,
+		DistrictID:     params.DistrictID,
+		PaidFrom:       params.DateFrom,
+		PaidTo:         params.DateTo,
+	}
+	if params.Status != nil {
+		status := models.PaymentStatus(*params.Status)
+		listParams.Status = &status
+	}
+
+	for {
+		result, err := s.feeRepo.List(ctx, listParams)
+		if err != nil {
+			return err
+		}
+		for i := range result.Fees {
+			if err := writeRow(tw, columns, &result.Fees[i]); err != nil {
+				return err
+			}
+		}
+		if result.NextCursor == nil {
+			return nil
+		}
+		listParams.Cursor = *result.NextCursor
+	}
+}
+
+func (s *ExportService) exportEvents(ctx context.Context, tw tableWriter, params *models.ExportParams) error {
+	columns, err := selectColumns(eventExportColumns, params.Columns)
+	if err != nil {
+		return err
+	}
+	if err := writeHeader(tw, columns); err != nil {
+		return err
+	}
+
+	listParams := &models.EventListParams{
+		Limit:          exportBatchSize,
+		OrganizationID: params.OrganizationID,
+		ProvinceID:     params.ProvinceID,
+		DistrictID:     params.DistrictID,
+		StartDateFrom:  params.DateFrom,
+		StartDateTo:    params.DateTo,
+	}
+	if params.Status != nil {
+		status := models.EventStatus(*params.Status)
+		listParams.Status = &status
+	}
+	if params.Year != nil && params.DateFrom == nil && params.DateTo == nil {
+		from := fmt.Sprintf("%d-01-01", *params.Year)
+		to := fmt.Sprintf("%d-12-31", *params.Year)
+		listParams.StartDateFrom = &from
+		listParams.StartDateTo = &to
+	}
+
+	for {
+		result, err := s.eventRepo.List(ctx, listParams)
+		if err != nil {
+			return err
+		}
+		for i := range result.Events {
+			if err := writeRow(tw, columns, &result.Events[i]); err != nil {
+				return err
+			}
+		}
+		if result.NextCursor == nil {
+			return nil
+		}
+		listParams.Cursor = *result.NextCursor
+	}
+}
+
+func (s *ExportService) exportOrganizations(ctx context.Context, tw tableWriter, params *models.ExportParams) error {
+	columns, err := selectColumns(organizationExportColumns, params.Columns)
+	if err != nil {
+		return err
+	}
+	if err := writeHeader(tw, columns); err != nil {
+		return err
+	}
+
+	listParams := &models.OrganizationListParams{
+		ProvinceID: params.ProvinceID,
+		DistrictID: params.DistrictID,
+	}
+	if params.Status != nil {
+		isActive := *params.Status == "active"
+		listParams.IsActive = &isActive
+	}
+
+	orgs, err := s.orgRepo.List(ctx, listParams)
+	if err != nil {
+		return err
+	}
+	for i := range orgs {
+		// A scoped organization export only contains the organization and its children
+		if params.OrganizationID != nil && orgs[i].ID.String() != *params.OrganizationID &&
+			(orgs[i].ParentID == nil || orgs[i].ParentID.String() != *params.OrganizationID) {
+			continue
+		}
+		if err := writeRow(tw, columns, &orgs[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// selectColumns returns the requested columns in the requested order, or all
+// columns if none were requested
+func selectColumns[T any](all []exportColumn[T], keys []string) ([]exportColumn[T], error) {
+	if len(keys) == 0 {
+		return all, nil
+	}
+
+	selected := make([]exportColumn[T], 0, len(keys))
+	for _, key := range keys {
+		found := false
+		for _, col := range all {
+			if col.key == key {
+				selected = append(selected, col)
+				found = true
+				break
+			}
+		}
+		if !found {
+			valid := make([]string, 0, len(all))
+			for _, col := range all {
+				valid = append(valid, col.key)
+			}
+			return nil, &ExportError{Message: fmt.Sprintf("Unknown column %q. Valid columns: %s", key, strings.Join(valid, ", "))}
+		}
+	}
+	return selected, nil
+}
+
+func writeHeader[T any](tw tableWriter, columns []exportColumn[T]) error {
+	header := make([]string, len(columns))
+	for i, col := range columns {
+		header[i] = col.header
+	}
+	return tw.WriteRow(header)
+}
+
+func writeRow[T any](tw tableWriter, columns []exportColumn[T], item *T) error {
+	row := make([]string, len(columns))
+	for i, col := range columns {
+		row[i] = col.value(item)
+	}
+	return tw.WriteRow(row)
+}
+
+// tableWriter writes rows of an export file in a specific format
+type tableWriter interface {
+	WriteRow(row []string) error
+	Close() error
+}
+
+func newTableWriter(w io.Writer, format models.ExportFormat, sheet string) (tableWriter, error) {
+	switch format {
+	case models.ExportFormatXLSX:
+		return newXLSXWriter(w, sheet)
+	default:
+		return newCSVWriter(w)
+	}
+}
+
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func newCSVWriter(w io.Writer) (*csvWriter, error) {
+	// UTF-8 BOM so that spreadsheet applications detect Cyrillic text correctly
+	if _, err := w.Write([]byte{0xEF, 0xBB, 0xBF}); err != nil {
+		return nil, err
+	}
+	return &csvWriter{w: csv.NewWriter(w)}, nil
+}
+
+func (cw *csvWriter) WriteRow(row []string) error {
+	escaped := make([]string, len(row))
+	for i, v := range row {
+		escaped[i] = escapeCSVFormula(v)
+	}
+	return cw.w.Write(escaped)
+}
+
+// escapeCSVFormula prefixes values that spreadsheet applications would
+// evaluate as a formula with a quote so that they are shown as text
+func escapeCSVFormula(v string) string {
+	if v != "" && strings.ContainsRune("=+-@\t\r", rune(v[0])) {
+		return "'" + v
+	}
+	return v
+}
+
+func (cw *csvWriter) Close() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+type xlsxWriter struct {
+	out    io.Writer
+	file   *excelize.File
+	stream *excelize.StreamWriter
+	row    int
+}
+
+func newXLSXWriter(w io.Writer, sheet string) (*xlsxWriter, error) {
+	f := excelize.NewFile()
+	if err := f.SetSheetName("Sheet1", sheet); err != nil {
+		return nil, err
+	}
+	stream, err := f.NewStreamWriter(sheet)
+	if err != nil {
+		return nil, err
+	}
+	return &xlsxWriter{out: w, file: f, stream: stream}, nil
+}
+
+func (xw *xlsxWriter) WriteRow(row []string) error {
+	xw.row++
+	cell, err := excelize.CoordinatesToCellName(1, xw.row)
+	if err != nil {
+		return err
+	}
+	values := make([]interface{}, len(row))
+	for i, v := range row {
+		values[i] = v
+	}
+	return xw.stream.SetRow(cell, values)
+}
+
+func (xw *xlsxWriter) Close() error {
+	defer xw.file.Close()
+	if err := xw.stream.Flush(); err != nil {
+		return err
+	}
+	return xw.file.Write(xw.out)
+}
+
+func formatString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func formatEnum[T ~string](v *T) string {
+	if v == nil {
+		return ""
+	}
+	return string(*v)
+}
+
+func formatInt(i *int) string {
+	if i == nil {
+		return ""
+	}
+	return strconv.Itoa(*i)
+}
+
+func formatDate(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format("2006-01-02")
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+type ExportError struct {
+	Message string
+}
+
+func (e *ExportError) Error() string {
+	return e.Message
+}
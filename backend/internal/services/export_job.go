@@ -0,0 +1,188 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog/log"
+
+	"github.com/sdyn/backend/internal/models"
+	"github.com/sdyn/backend/internal/repository"
+	"github.com/sdyn/backend/pkg/storage"
+)
+
+const (
+	// exportJobPollInterval is how often the worker checks for pending jobs
+	exportJobPollInterval = 5 * time.Second
+	// exportJobStaleAfter is how long a job may stay processing before it is
+	// assumed to have been interrupted
+	exportJobStaleAfter = "30 minutes"
+	// exportJobMaxAttempts is how often an interrupted job is retried
+	exportJobMaxAttempts = 3
+	// exportDownloadExpiry is the lifetime of presigned download URLs
+	exportDownloadExpiry = 15 * time.Minute
+)
+
+type ExportJobService struct {
+	repo    *repository.ExportJobRepository
+	exports *ExportService
+	storage *storage.Storage
+}
+
+func NewExportJobService(repo *repository.ExportJobRepository, exports *ExportService, storage *storage.Storage) *ExportJobService {
+	return &ExportJobService{
+		repo:    repo,
+		exports: exports,
+		storage: storage,
+	}
+}
+
+// Create queues an export job. Params must have been validated with
+// ValidateExportParams and already carry the requester's data scope.
+func (s *ExportJobService) Create(ctx context.Context, requestedBy string, params *models.ExportParams) (*models.ExportJob, error) {
+	return s.repo.Create(ctx, requestedBy, params)
+}
+
+// GetByID returns a job. Only the user who requested the job may see it.
+func (s *ExportJobService) GetByID(ctx context.Context, id uuid.UUID, userID string) (*models.ExportJob, error) {
+	job, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrExportJobNotFound
+		}
+		return nil, err
+	}
+
+	if job.RequestedBy != userID {
+		return nil, ErrExportJobNotFound
+	}
+
+	return job, nil
+}
+
+// AttachDownloadURL sets a presigned download URL on a completed job
+func (s *ExportJobService) AttachDownloadURL(ctx context.Context, job *models.ExportJob) error {
+	if job.Status != models.ExportJobCompleted || job.ObjectKey == nil {
+		return nil
+	}
+
+	fileName := ""
+	if job.FileName != nil {
+		fileName = *job.FileName
+	}
+
+	url, err := s.storage.PresignedGetURL(ctx, *job.ObjectKey, fileName, exportDownloadExpiry)
+	if err != nil {
+		return err
+	}
+
+	expiresAt := time.Now().Add(exportDownloadExpiry)
+	job.DownloadURL = &url
+	job.DownloadExpiresAt = &expiresAt
+	return nil
+}
+
+// RunWorker processes queued export jobs until ctx is cancelled
+func (s *ExportJobService) RunWorker(ctx context.Context) {
+	ticker := time.NewTicker(exportJobPollInterval)
+	defer ticker.Stop()
+
+	log.Info().Msg("Export worker started")
+
+	for {
+		s.requeueStale(ctx)
+
+		// Drain the queue before waiting for the next tick
+		for ctx.Err() == nil {
+			job, err := s.repo.ClaimNext(ctx)
+			if err != nil {
+				if !errors.Is(err, pgx.ErrNoRows) && ctx.Err() == nil {
+					log.Error().Err(err).Msg("Failed to claim export job")
+				}
+				break
+			}
+			s.process(ctx, job)
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Info().Msg("Export worker stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *ExportJobService) requeueStale(ctx context.Context) {
+	n, err := s.repo.RequeueStale(ctx, exportJobStaleAfter, exportJobMaxAttempts)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Error().Err(err).Msg("Failed to requeue stale export jobs")
+		}
+		return
+	}
+	if n > 0 {
+		log.Warn().Int64("count", n).Msg("Requeued interrupted export jobs")
+	}
+}
+
+func (s *ExportJobService) process(ctx context.Context, job *models.ExportJob) {
+	logger := log.With().Str("job_id", job.ID.String()).Str("type", string(job.Params.Type)).Logger()
+
+	objectKey, fileName, size, err := s.build(ctx, job)
+	if err != nil {
+		if ctx.Err() != nil {
+			// Shutting down; the job is requeued once it goes stale
+			return
+		}
+		logger.Error().Err(err).Msg("Export job failed")
+		if err := s.repo.MarkFailed(ctx, job.ID, err.Error()); err != nil {
+			logger.Error().Err(err).Msg("Failed to mark export job as failed")
+		}
+		return
+	}
+
+	if err := s.repo.MarkCompleted(ctx, job.ID, objectKey, fileName, size); err != nil {
+		logger.Error().Err(err).Msg("Failed to mark export job as completed")
+		return
+	}
+
+	logger.Info().Int64("size", size).Msg("Export job completed")
+}
+
+// build writes the export to a temporary file and uploads it to storage
+func (s *ExportJobService) build(ctx context.Context, job *models.ExportJob) (objectKey, fileName string, size int64, err error) {
+	f, err := os.CreateTemp("", "export-*")
+	if err != nil {
+		return "", "", 0, err
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	if err := s.exports.Export(ctx, f, &job.Params); err != nil {
+		return "", "", 0, err
+	}
+
+	size, err = f.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return "", "", 0, err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return "", "", 0, err
+	}
+
+	objectKey = fmt.Sprintf("exports/%s/%s.%s", job.CreatedAt.Format("2006/01"), job.ID, job.Params.Format)
+	if err := s.storage.Put(ctx, objectKey, f, size, job.Params.Format.ContentType()); err != nil {
+		return "", "", 0, fmt.Errorf("upload failed: %w", err)
+	}
+
+	return objectKey, ExportFileName(&job.Params, job.CreatedAt), size, nil
+}
+
+var ErrExportJobNotFound = errors.New("export job not found")
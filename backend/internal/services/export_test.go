@@ -0,0 +1,116 @@
+package services
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xuri/excelize/v2"
+
+	"github.com/sdyn/backend/internal/models"
+)
+
+func TestValidateExportParams(t *testing.T) {
+	status := "active"
+	badStatus := "unknown"
+	badDate := "2024/01/01"
+	year := 2024
+
+	tests := []struct {
+		name    string
+		params  models.ExportParams
+		wantErr bool
+	}{
+		{"defaults to csv", models.ExportParams{Type: models.ExportTypeMembers}, false},
+		{"xlsx", models.ExportParams{Type: models.ExportTypeFees, Format: models.ExportFormatXLSX}, false},
+		{"invalid type", models.ExportParams{Type: "positions"}, true},
+		{"invalid format", models.ExportParams{Type: models.ExportTypeMembers, Format: "pdf"}, true},
+		{"member status", models.ExportParams{Type: models.ExportTypeMembers, Status: &status}, false},
+		{"invalid member status", models.ExportParams{Type: models.ExportTypeMembers, Status: &badStatus}, true},
+		{"invalid date", models.ExportParams{Type: models.ExportTypeEvents, DateFrom: &badDate}, true},
+		{"known columns", models.ExportParams{Type: models.ExportTypeMembers, Columns: []string{"member_id", "phone"}}, false},
+		{"unknown column", models.ExportParams{Type: models.ExportTypeMembers, Columns: []string{"password"}}, true},
+		{"fee year", models.ExportParams{Type: models.ExportTypeFees, Year: &year}, false},
+		{"member year", models.ExportParams{Type: models.ExportTypeMembers, Year: &year}, true},
+		{"organization year", models.ExportParams{Type: models.ExportTypeOrganizations, Year: &year}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := tt.params
+			err := ValidateExportParams(&params)
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.IsType(t, &ExportError{}, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.NotEmpty(t, params.Format)
+		})
+	}
+}
+
+func TestSelectColumns_PreservesRequestedOrder(t *testing.T) {
+	columns, err := selectColumns(memberExportColumns, []string{"phone", "member_id"})
+
+	assert.NoError(t, err)
+	assert.Len(t, columns, 2)
+	assert.Equal(t, "phone", columns[0].key)
+	assert.Equal(t, "member_id", columns[1].key)
+}
+
+func TestExportFileName(t *testing.T) {
+	params := &models.ExportParams{Type: models.ExportTypeFees, Format: models.ExportFormatXLSX}
+	createdAt := time.Date(2024, 3, 5, 14, 7, 9, 0, time.UTC)
+
+	assert.Equal(t, "fees-20240305-140709.xlsx", ExportFileName(params, createdAt))
+}
+
+func TestCSVWriter(t *testing.T) {
+	var buf bytes.Buffer
+	phone := "99001122"
+	member := &models.Member{MemberID: "SDYN-2024-00001", FirstName: "Бат", LastName: "Дорж", Phone: &phone}
+
+	tw, err := newTableWriter(&buf, models.ExportFormatCSV, "members")
+	assert.NoError(t, err)
+
+	columns, _ := selectColumns(memberExportColumns, []string{"member_id", "first_name", "email"})
+	assert.NoError(t, writeHeader(tw, columns))
+	assert.NoError(t, writeRow(tw, columns, member))
+	assert.NoError(t, tw.Close())
+
+	out := strings.TrimPrefix(buf.String(), "\xef\xbb\xbf")
+	assert.Equal(t, "Member ID,First name,Email\nSDYN-2024-00001,Бат,\n", out)
+}
+
+func TestCSVWriter_EscapesFormulas(t *testing.T) {
+	var buf bytes.Buffer
+
+	tw, err := newTableWriter(&buf, models.ExportFormatCSV, "members")
+	assert.NoError(t, err)
+	assert.NoError(t, tw.WriteRow([]string{"=HYPERLINK(\"http://x\")", "+976", "-1", "@SUM(A1)", "Бат", ""}))
+	assert.NoError(t, tw.Close())
+
+	out := strings.TrimPrefix(buf.String(), "\xef\xbb\xbf")
+	assert.Equal(t, "\"'=HYPERLINK(\"\"http://x\"\")\",'+976,'-1,'@SUM(A1),Бат,\n", out)
+}
+
+func TestXLSXWriter(t *testing.T) {
+	var buf bytes.Buffer
+
+	tw, err := newTableWriter(&buf, models.ExportFormatXLSX, "fees")
+	assert.NoError(t, err)
+	assert.NoError(t, tw.WriteRow([]string{"Year", "Amount"}))
+	assert.NoError(t, tw.WriteRow([]string{"2024", "50000.00"}))
+	assert.NoError(t, tw.Close())
+
+	f, err := excelize.OpenReader(&buf)
+	assert.NoError(t, err)
+	defer f.Close()
+
+	rows, err := f.GetRows("fees")
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{{"Year", "Amount"}, {"2024", "50000.00"}}, rows)
+}
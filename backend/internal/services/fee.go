@@ -2,6 +2,7 @@ package services
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 
@@ -9,15 +10,31 @@ import (
 	"github.com/sdyn/backend/internal/repository"
 )
 
+// MembershipPolicy sets how far a paid fee extends a member's membership
+type MembershipPolicy struct {
+	AnnualTermMonths  int
+	MonthlyTermMonths int
+}
+
+// TermMonths returns the months a paid fee extends membership by. Fees
+// without a month are annual.
+func (p MembershipPolicy) TermMonths(fee *models.MembershipFee) int {
+	if fee.Month != nil {
+		return p.MonthlyTermMonths
+	}
+	return p.AnnualTermMonths
+}
+
 type FeeService struct {
-	repo *repository.FeeRepository
+	repo   *repository.FeeRepository
+	policy MembershipPolicy
 }
 
-func NewFeeService(repo *repository.FeeRepository) *FeeService {
-	return &FeeService{repo: repo}
+func NewFeeService(repo *repository.FeeRepository, policy MembershipPolicy) *FeeService {
+	return &FeeService{repo: repo, policy: policy}
 }
 
-func (s *FeeService) List(ctx context.Context, params *models.FeeListParams) ([]models.MembershipFee, error) {
+func (s *FeeService) List(ctx context.Context, params *models.FeeListParams) (*models.FeeListResponse, error) {
 	return s.repo.List(ctx, params)
 }
 
@@ -52,6 +69,13 @@ func (s *FeeService) Create(ctx context.Context, req *models.CreateFeeRequest) (
 		fee.Notes = req.Notes
 	}
 
+	// Paying a fee renews the membership
+	if fee.Status == models.PaymentStatusPaid {
+		now := time.Now()
+		fee.PaidAt = &now
+		return s.repo.CreatePaid(ctx, fee, s.policy.TermMonths(fee))
+	}
+
 	return s.repo.Create(ctx, fee)
 }
 
@@ -77,6 +101,17 @@ func (s *FeeService) Update(ctx context.Context, id uuid.UUID, req *models.Updat
 		fee.Notes = req.Notes
 	}
 
+	// Paying a fee renews the membership
+	if fee.Status == models.PaymentStatusPaid {
+		if req.PaidAt != nil {
+			fee.PaidAt = req.PaidAt
+		} else if fee.PaidAt == nil {
+			now := time.Now()
+			fee.PaidAt = &now
+		}
+		return s.repo.UpdatePaid(ctx, fee, s.policy.TermMonths(fee))
+	}
+
 	return s.repo.Update(ctx, fee)
 }
 
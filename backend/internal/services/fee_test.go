@@ -286,3 +286,11 @@ func TestFeeService_DuplicateFee(t *testing.T) {
 
 	mockRepo.AssertExpectations(t)
 }
+
+func TestMembershipPolicy_TermMonths(t *testing.T) {
+	policy := MembershipPolicy{AnnualTermMonths: 12, MonthlyTermMonths: 1}
+	month := 5
+
+	assert.Equal(t, 12, policy.TermMonths(&models.MembershipFee{Year: 2024}))
+	assert.Equal(t, 1, policy.TermMonths(&models.MembershipFee{Year: 2024, Month: &month}))
+}
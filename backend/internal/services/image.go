@@ -0,0 +1,393 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	_ "image/png" // register decoder
+	"io"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // register decoder
+
+	"github.com/sdyn/backend/internal/models"
+	"github.com/sdyn/backend/internal/repository"
+	"github.com/sdyn/backend/pkg/storage"
+)
+
+// PublicImagePrefix is the storage prefix of uploaded images. Objects under
+// it are readable without a presigned URL.
+const PublicImagePrefix = "public/"
+
+const (
+	// maxImageSize is the largest accepted image upload in bytes
+	maxImageSize = 8 << 20
+	// maxImagePixels guards against images that are small on disk but huge
+	// once decoded
+	maxImagePixels = 40_000_000
+	// imageQuality is the JPEG quality of stored images
+	imageQuality = 85
+	// imageCollectInterval is how often unreferenced images are removed
+	imageCollectInterval = 6 * time.Hour
+	// imageCollectGrace keeps recent images, so an upload whose URL is not
+	// saved yet is not collected
+	imageCollectGrace = time.Hour
+	// thumbnailSuffix is appended to an image key to get its thumbnail
+	thumbnailSuffix = "_thumb.jpg"
+)
+
+// imageSpec describes the images accepted for one use. Images are cropped to
+// the aspect ratio of Width x Height and scaled down to fit, never up.
+type imageSpec struct {
+	Prefix                  string
+	MinWidth, MinHeight     int
+	Width, Height           int
+	ThumbWidth, ThumbHeight int
+}
+
+var (
+	avatarImage = imageSpec{
+		Prefix:   PublicImagePrefix + "avatars/",
+		MinWidth: 128, MinHeight: 128,
+		Width: 512, Height: 512,
+		ThumbWidth: 128, ThumbHeight: 128,
+	}
+	eventCoverImage = imageSpec{
+		Prefix:   PublicImagePrefix + "event-covers/",
+		MinWidth: 640, MinHeight: 360,
+		Width: 1600, Height: 900,
+		ThumbWidth: 400, ThumbHeight: 225,
+	}
+)
+
+type ImageService struct {
+	repo       *repository.ImageRepository
+	memberRepo *repository.MemberRepository
+	eventRepo  *repository.EventRepository
+	storage    *storage.Storage
+}
+
+func NewImageService(repo *repository.ImageRepository, memberRepo *repository.MemberRepository, eventRepo *repository.EventRepository, storage *storage.Storage) *ImageService {
+	return &ImageService{
+		repo:       repo,
+		memberRepo: memberRepo,
+		eventRepo:  eventRepo,
+		storage:    storage,
+	}
+}
+
+// UploadAvatar stores a new avatar for the member and sets avatar_url. The
+// previous avatar is removed if it was uploaded here.
+func (s *ImageService) UploadAvatar(ctx context.Context, memberID uuid.UUID, r io.Reader, changedBy string) (*models.ImageUpload, error) {
+	member, err := s.memberRepo.GetByID(ctx, memberID)
+	if err != nil {
+		return nil, err
+	}
+
+	upload, key, err := s.store(ctx, avatarImage, memberID, r)
+	if err != nil {
+		return nil, err
+	}
+
+	previous := member.AvatarURL
+	if err := s.setAvatar(ctx, member, &upload.URL, changedBy); err != nil {
+		s.remove(ctx, key)
+		return nil, err
+	}
+	s.collect(ctx, previous)
+
+	return upload, nil
+}
+
+// DeleteAvatar clears the member's avatar
+func (s *ImageService) DeleteAvatar(ctx context.Context, memberID uuid.UUID, changedBy string) error {
+	member, err := s.memberRepo.GetByID(ctx, memberID)
+	if err != nil {
+		return err
+	}
+	if member.AvatarURL == nil {
+		return nil
+	}
+
+	previous := member.AvatarURL
+	if err := s.setAvatar(ctx, member, nil, changedBy); err != nil {
+		return err
+	}
+	s.collect(ctx, previous)
+	return nil
+}
+
+// setAvatar saves the avatar URL like any other member update, so the change
+// shows in the member history
+func (s *ImageService) setAvatar(ctx context.Context, member *models.Member, url *string, changedBy string) error {
+	before := *member
+	member.AvatarURL = url
+
+	changedByUUID, _ := uuid.Parse(changedBy)
+	history := &models.MemberHistory{
+		Action:    "updated",
+		ChangedBy: &changedByUUID,
+		Changes:   diffMember(&before, member),
+	}
+
+	_, err := s.memberRepo.Update(ctx, member, history)
+	return err
+}
+
+// UploadEventCover stores a new cover image for the event and sets
+// cover_image_url. The previous cover is removed if it was uploaded here.
+func (s *ImageService) UploadEventCover(ctx context.Context, eventID uuid.UUID, r io.Reader) (*models.ImageUpload, error) {
+	event, err := s.eventRepo.GetByID(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	upload, key, err := s.store(ctx, eventCoverImage, eventID, r)
+	if err != nil {
+		return nil, err
+	}
+
+	previous := event.CoverImageURL
+	event.CoverImageURL = &upload.URL
+	if _, err := s.eventRepo.Update(ctx, event); err != nil {
+		s.remove(ctx, key)
+		return nil, err
+	}
+	s.collect(ctx, previous)
+
+	return upload, nil
+}
+
+// DeleteEventCover clears the event's cover image
+func (s *ImageService) DeleteEventCover(ctx context.Context, eventID uuid.UUID) error {
+	event, err := s.eventRepo.GetByID(ctx, eventID)
+	if err != nil {
+		return err
+	}
+	if event.CoverImageURL == nil {
+		return nil
+	}
+
+	previous := event.CoverImageURL
+	event.CoverImageURL = nil
+	if _, err := s.eventRepo.Update(ctx, event); err != nil {
+		return err
+	}
+	s.collect(ctx, previous)
+	return nil
+}
+
+// store processes an uploaded image and saves it and its thumbnail under
+// spec.Prefix/<ownerID>/. It returns the upload and the image key.
+func (s *ImageService) store(ctx context.Context, spec imageSpec, ownerID uuid.UUID, r io.Reader) (*models.ImageUpload, string, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxImageSize+1))
+	if err != nil {
+		return nil, "", err
+	}
+	if len(data) > maxImageSize {
+		return nil, "", &ImageError{Message: fmt.Sprintf("Image is larger than %d MB", maxImageSize>>20)}
+	}
+
+	img, err := decodeImage(data, spec)
+	if err != nil {
+		return nil, "", err
+	}
+
+	main := resizeImage(img, spec.Width, spec.Height)
+	thumb := resizeImage(img, spec.ThumbWidth, spec.ThumbHeight)
+
+	key := fmt.Sprintf("%s%s/%s.jpg", spec.Prefix, ownerID, uuid.New())
+	if err := s.put(ctx, key, main); err != nil {
+		return nil, "", err
+	}
+	if err := s.put(ctx, thumbnailKey(key), thumb); err != nil {
+		s.remove(ctx, key)
+		return nil, "", err
+	}
+
+	bounds := main.Bounds()
+	return &models.ImageUpload{
+		URL:          s.storage.URL(key),
+		ThumbnailURL: s.storage.URL(thumbnailKey(key)),
+		Width:        bounds.Dx(),
+		Height:       bounds.Dy(),
+	}, key, nil
+}
+
+func (s *ImageService) put(ctx context.Context, key string, img image.Image) error {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: imageQuality}); err != nil {
+		return err
+	}
+	return s.storage.Put(ctx, key, &buf, int64(buf.Len()), "image/jpeg")
+}
+
+// collect removes a replaced image if it was uploaded here. Images hosted
+// elsewhere are left alone.
+func (s *ImageService) collect(ctx context.Context, url *string) {
+	if url == nil {
+		return
+	}
+	key, ok := s.storage.KeyFromURL(*url)
+	if !ok || !strings.HasPrefix(key, PublicImagePrefix) {
+		return
+	}
+
+	// The same URL may have been copied to another record
+	referenced, err := s.repo.Referenced(ctx, []string{*url})
+	if err != nil {
+		log.Error().Err(err).Str("object_key", key).Msg("Failed to check image references")
+		return
+	}
+	if !referenced[*url] {
+		s.remove(ctx, key)
+	}
+}
+
+// remove deletes an image and its thumbnail. Failures leave the objects to
+// the periodic collection.
+func (s *ImageService) remove(ctx context.Context, key string) {
+	for _, k := range []string{key, thumbnailKey(key)} {
+		if err := s.storage.Remove(ctx, k); err != nil {
+			log.Error().Err(err).Str("object_key", k).Msg("Failed to remove image")
+		}
+	}
+}
+
+// RunCollector periodically removes uploaded images no longer referenced by
+// any member or event, e.g. after a URL was overwritten through a regular
+// update or the owner was deleted. It runs until ctx is cancelled.
+func (s *ImageService) RunCollector(ctx context.Context) {
+	ticker := time.NewTicker(imageCollectInterval)
+	defer ticker.Stop()
+
+	log.Info().Msg("Image collector started")
+
+	for {
+		s.collectUnreferenced(ctx)
+
+		select {
+		case <-ctx.Done():
+			log.Info().Msg("Image collector stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *ImageService) collectUnreferenced(ctx context.Context) {
+	objects, err := s.storage.List(ctx, PublicImagePrefix)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Error().Err(err).Msg("Failed to list images")
+		}
+		return
+	}
+
+	urls := unreferencedCandidates(objects, time.Now().Add(-imageCollectGrace), s.storage.URL)
+	if len(urls) == 0 {
+		return
+	}
+
+	referenced, err := s.repo.Referenced(ctx, urls)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Error().Err(err).Msg("Failed to check image references")
+		}
+		return
+	}
+
+	removed := 0
+	for _, url := range urls {
+		if referenced[url] {
+			continue
+		}
+		key, _ := s.storage.KeyFromURL(url)
+		s.remove(ctx, key)
+		removed++
+	}
+	if removed > 0 {
+		log.Info().Int("count", removed).Msg("Removed unreferenced images")
+	}
+}
+
+// unreferencedCandidates returns the URLs of the images (not thumbnails)
+// last modified before cutoff
+func unreferencedCandidates(objects []storage.Object, cutoff time.Time, url func(string) string) []string {
+	var urls []string
+	for _, o := range objects {
+		if strings.HasSuffix(o.Key, thumbnailSuffix) || !o.LastModified.Before(cutoff) {
+			continue
+		}
+		urls = append(urls, url(o.Key))
+	}
+	return urls
+}
+
+func thumbnailKey(key string) string {
+	return strings.TrimSuffix(key, ".jpg") + thumbnailSuffix
+}
+
+// decodeImage checks the format and dimensions of an uploaded image before
+// decoding it
+func decodeImage(data []byte, spec imageSpec) (image.Image, error) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, &ImageError{Message: "Only JPEG, PNG and WebP images are accepted"}
+	}
+	if cfg.Width < spec.MinWidth || cfg.Height < spec.MinHeight {
+		return nil, &ImageError{Message: fmt.Sprintf("Image must be at least %dx%d pixels", spec.MinWidth, spec.MinHeight)}
+	}
+	if cfg.Width*cfg.Height > maxImagePixels {
+		return nil, &ImageError{Message: "Image dimensions are too large"}
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, &ImageError{Message: "Image is corrupt or not a valid " + format + " file"}
+	}
+	return img, nil
+}
+
+// resizeImage crops src around its centre to the aspect ratio of
+// width x height and scales it down to at most that size. Transparent areas
+// become white, since the result is stored as JPEG.
+func resizeImage(src image.Image, width, height int) image.Image {
+	b := src.Bounds()
+
+	crop := b
+	if b.Dx()*height > b.Dy()*width {
+		w := b.Dy() * width / height
+		crop.Min.X = b.Min.X + (b.Dx()-w)/2
+		crop.Max.X = crop.Min.X + w
+	} else {
+		h := b.Dx() * height / width
+		crop.Min.Y = b.Min.Y + (b.Dy()-h)/2
+		crop.Max.Y = crop.Min.Y + h
+	}
+
+	w, h := width, height
+	if crop.Dx() < width {
+		w, h = crop.Dx(), crop.Dy()
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, crop, draw.Over, nil)
+	return dst
+}
+
+type ImageError struct {
+	Message string
+}
+
+func (e *ImageError) Error() string {
+	return e.Message
+}
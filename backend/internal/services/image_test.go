@@ -0,0 +1,91 @@
+package services
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sdyn/backend/pkg/storage"
+)
+
+func encodePNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for x := 0; x < width; x++ {
+		img.Set(x, 0, color.NRGBA{R: 255, A: 255})
+	}
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func TestDecodeImage(t *testing.T) {
+	img, err := decodeImage(encodePNG(t, 300, 200), avatarImage)
+	require.NoError(t, err)
+	assert.Equal(t, 300, img.Bounds().Dx())
+
+	var imageErr *ImageError
+	_, err = decodeImage(encodePNG(t, 100, 200), avatarImage)
+	require.ErrorAs(t, err, &imageErr)
+	assert.Contains(t, imageErr.Message, "128x128")
+
+	_, err = decodeImage(encodePNG(t, 600, 400), eventCoverImage)
+	require.ErrorAs(t, err, &imageErr)
+
+	_, err = decodeImage([]byte("GIF89a\x01\x00\x01\x00"), avatarImage)
+	require.ErrorAs(t, err, &imageErr)
+
+	_, err = decodeImage([]byte("<svg xmlns='http://www.w3.org/2000/svg'/>"), avatarImage)
+	require.ErrorAs(t, err, &imageErr)
+}
+
+func TestResizeImage(t *testing.T) {
+	tests := []struct {
+		name          string
+		width, height int
+		boxW, boxH    int
+		wantW, wantH  int
+	}{
+		{"landscape to square", 1200, 800, 512, 512, 512, 512},
+		{"portrait to square", 800, 1200, 128, 128, 128, 128},
+		{"small square is not enlarged", 200, 300, 512, 512, 200, 200},
+		{"cover crop", 4000, 4000, 1600, 900, 1600, 900},
+		{"small cover is not enlarged", 1000, 1000, 1600, 900, 1000, 562},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := image.NewRGBA(image.Rect(0, 0, tt.width, tt.height))
+			got := resizeImage(src, tt.boxW, tt.boxH).Bounds()
+			assert.Equal(t, tt.wantW, got.Dx())
+			assert.Equal(t, tt.wantH, got.Dy())
+		})
+	}
+}
+
+func TestResizeImageFillsTransparency(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(0, 0, 200, 200)) // fully transparent
+	r, g, b, _ := resizeImage(src, 128, 128).At(64, 64).RGBA()
+	assert.Equal(t, [3]uint32{0xffff, 0xffff, 0xffff}, [3]uint32{r, g, b})
+}
+
+func TestUnreferencedCandidates(t *testing.T) {
+	now := time.Now()
+	objects := []storage.Object{
+		{Key: "public/avatars/a/1.jpg", LastModified: now.Add(-2 * time.Hour)},
+		{Key: "public/avatars/a/1_thumb.jpg", LastModified: now.Add(-2 * time.Hour)},
+		{Key: "public/avatars/a/2.jpg", LastModified: now.Add(-time.Minute)},
+	}
+
+	urls := unreferencedCandidates(objects, now.Add(-imageCollectGrace), func(key string) string {
+		return "https://minio.example/bucket/" + key
+	})
+	assert.Equal(t, []string{"https://minio.example/bucket/public/avatars/a/1.jpg"}, urls)
+	assert.Equal(t, "public/avatars/a/1_thumb.jpg", thumbnailKey("public/avatars/a/1.jpg"))
+}
@@ -2,15 +2,33 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog/log"
 
 	"github.com/sdyn/backend/internal/models"
 	"github.com/sdyn/backend/internal/repository"
 )
 
+const (
+	// suspensionCheckInterval is how often ended suspensions are checked
+	suspensionCheckInterval = 15 * time.Minute
+	// membershipExpiryInterval is how often expired memberships are closed
+	membershipExpiryInterval = 24 * time.Hour
+	// maxExpiringDays caps the look-ahead window of ListExpiring
+	maxExpiringDays = 365
+)
+
+// ErrMemberStatusChanged is returned when a member's status changes between
+// reading it and applying an update
+var ErrMemberStatusChanged = errors.New("member status was changed by another request")
+
 type MemberService struct {
 	repo  *repository.MemberRepository
 	redis *redis.Client
@@ -108,11 +126,14 @@ func (s *MemberService) Create(ctx context.Context, req *models.CreateMemberRequ
 	return s.repo.Create(ctx, member)
 }
 
-func (s *MemberService) Update(ctx context.Context, id uuid.UUID, req *models.UpdateMemberRequest) (*models.Member, error) {
+// Update applies the provided fields and records the changed ones in the
+// member history
+func (s *MemberService) Update(ctx context.Context, id uuid.UUID, req *models.UpdateMemberRequest, changedBy string) (*models.Member, error) {
 	member, err := s.repo.GetByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
+	before := *member
 
 	// Update fields if provided
 	if req.FirstName != nil {
@@ -168,6 +189,11 @@ func (s *MemberService) Update(ctx context.Context, id uuid.UUID, req *models.Up
 	if req.OrganizationID != nil {
 		id, err := uuid.Parse(*req.OrganizationID)
 		if err == nil {
+			// Moving between organizations needs the receiving side's
+			// consent, see TransferService
+			if before.OrganizationID != nil && *before.OrganizationID != id {
+				return nil, &MemberError{Message: "Use a transfer request to move a member to another organization"}
+			}
 			member.OrganizationID = &id
 		}
 	}
@@ -178,42 +204,153 @@ func (s *MemberService) Update(ctx context.Context, id uuid.UUID, req *models.Up
 		member.Education = &edu
 	}
 
-	return s.repo.Update(ctx, member)
+	changes := diffMember(&before, member)
+	if len(changes) == 0 {
+		return member, nil
+	}
+
+	changedByUUID, _ := uuid.Parse(changedBy)
+	history := &models.MemberHistory{
+		Action:    "updated",
+		ChangedBy: &changedByUUID,
+		Reason:    req.Reason,
+		Changes:   changes,
+	}
+
+	return s.repo.Update(ctx, member, history)
 }
 
 func (s *MemberService) Delete(ctx context.Context, id uuid.UUID) error {
 	return s.repo.Delete(ctx, id)
 }
 
+// UpdateStatus moves a member to a new status following
+// models.MemberStatusTransitions. Suspension and expulsion require a reason.
 func (s *MemberService) UpdateStatus(ctx context.Context, id uuid.UUID, req *models.UpdateStatusRequest, changedBy string) (*models.Member, error) {
 	member, err := s.repo.GetByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 
-	oldStatus := member.Status
-	member.Status = req.Status
+	if err := validateStatusChange(member.Status, req, time.Now()); err != nil {
+		return nil, err
+	}
 
 	// Create history record
 	changedByUUID, _ := uuid.Parse(changedBy)
 	history := &models.MemberHistory{
-		MemberID:  id,
 		Action:    "status_change",
-		OldValue:  stringPtr(string(oldStatus)),
+		OldValue:  stringPtr(string(member.Status)),
 		NewValue:  stringPtr(string(req.Status)),
 		ChangedBy: &changedByUUID,
 		Reason:    req.Reason,
 	}
 
-	if err := s.repo.CreateHistory(ctx, history); err != nil {
+	err = s.repo.UpdateStatus(ctx, id, member.Status, req.Status, req.SuspendedUntil, history)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// Changed by someone else since it was loaded
+		return nil, ErrMemberStatusChanged
+	}
+	if err != nil {
 		return nil, err
 	}
 
-	return s.repo.Update(ctx, member)
+	return s.repo.GetByID(ctx, id)
 }
 
-func (s *MemberService) GetHistory(ctx context.Context, memberID uuid.UUID) ([]models.MemberHistory, error) {
-	return s.repo.GetHistory(ctx, memberID)
+// RunScheduler runs periodic member maintenance until ctx is cancelled:
+// ending suspensions and, once a day, expiring lapsed memberships
+func (s *MemberService) RunScheduler(ctx context.Context) {
+	suspensionTicker := time.NewTicker(suspensionCheckInterval)
+	defer suspensionTicker.Stop()
+	expiryTicker := time.NewTicker(membershipExpiryInterval)
+	defer expiryTicker.Stop()
+
+	log.Info().Msg("Member scheduler started")
+
+	s.reinstateSuspended(ctx)
+	s.expireMemberships(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info().Msg("Member scheduler stopped")
+			return
+		case <-suspensionTicker.C:
+			s.reinstateSuspended(ctx)
+		case <-expiryTicker.C:
+			s.expireMemberships(ctx)
+		}
+	}
+}
+
+func (s *MemberService) reinstateSuspended(ctx context.Context) {
+	n, err := s.repo.ReinstateSuspended(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Error().Err(err).Msg("Failed to reinstate suspended members")
+		}
+		return
+	}
+	if n > 0 {
+		log.Info().Int64("count", n).Msg("Reinstated members after suspension")
+	}
+}
+
+func (s *MemberService) expireMemberships(ctx context.Context) {
+	n, err := s.repo.ExpireMemberships(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Error().Err(err).Msg("Failed to expire memberships")
+		}
+		return
+	}
+	if n > 0 {
+		log.Info().Int64("count", n).Msg("Expired memberships")
+	}
+}
+
+// ListExpiring returns active members whose membership expires within the
+// next days days, soonest first
+func (s *MemberService) ListExpiring(ctx context.Context, params *models.MemberListParams, days int) (*models.MemberListResponse, error) {
+	if days <= 0 || days > maxExpiringDays {
+		return nil, &MemberError{Message: fmt.Sprintf("days must be between 1 and %d", maxExpiringDays)}
+	}
+
+	now := time.Now()
+	until := now.AddDate(0, 0, days)
+	status := models.MemberStatusActive
+	params.Status = &status
+	params.ExpiresFrom = &now
+	params.ExpiresTo = &until
+	params.SortBy = "membership_expires_at"
+	params.SortOrder = "asc"
+
+	return s.repo.List(ctx, params)
+}
+
+// validateStatusChange checks a status change request against the
+// transition table and the reason and end date rules
+func validateStatusChange(from models.MemberStatus, req *models.UpdateStatusRequest, now time.Time) error {
+	if !from.CanTransitionTo(req.Status) {
+		return &StatusTransitionError{From: from, To: req.Status}
+	}
+
+	if (req.Status == models.MemberStatusSuspended || req.Status == models.MemberStatusExpelled) &&
+		(req.Reason == nil || strings.TrimSpace(*req.Reason) == "") {
+		return &MemberError{Message: "Reason is required to suspend or expel a member"}
+	}
+
+	if req.SuspendedUntil != nil {
+		if req.Status != models.MemberStatusSuspended {
+			return &MemberError{Message: "suspended_until is only allowed when suspending a member"}
+		}
+		if !req.SuspendedUntil.After(now) {
+			return &MemberError{Message: "suspended_until must be in the future"}
+		}
+	}
+
+	return nil
 }
 
 func (s *MemberService) GetReport(ctx context.Context, orgID string) (map[string]interface{}, error) {
@@ -223,3 +360,14 @@ func (s *MemberService) GetReport(ctx context.Context, orgID string) (map[string
 func stringPtr(s string) *string {
 	return &s
 }
+
+// StatusTransitionError is returned for a status change that is not allowed
+// from the member's current status
+type StatusTransitionError struct {
+	From models.MemberStatus
+	To   models.MemberStatus
+}
+
+func (e *StatusTransitionError) Error() string {
+	return fmt.Sprintf("cannot change member status from %s to %s", e.From, e.To)
+}
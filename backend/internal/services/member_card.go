@@ -0,0 +1,323 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/go-pdf/fpdf"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog/log"
+	"github.com/skip2/go-qrcode"
+
+	"github.com/sdyn/backend/internal/config"
+	"github.com/sdyn/backend/internal/models"
+	"github.com/sdyn/backend/internal/repository"
+	"github.com/sdyn/backend/pkg/fonts"
+	"github.com/sdyn/backend/pkg/storage"
+)
+
+const (
+	// cardTokenAudience keeps card tokens from being accepted anywhere else
+	cardTokenAudience = "member-card"
+	// maxCardAvatarSize caps the avatar read from storage
+	maxCardAvatarSize = 2 << 20
+
+	// Card dimensions in mm (ISO/IEC 7810 ID-1)
+	cardWidth  = 85.6
+	cardHeight = 53.98
+)
+
+type cardClaims struct {
+	MemberID string `json:"mid"`
+	jwt.RegisteredClaims
+}
+
+type MemberCardService struct {
+	memberRepo *repository.MemberRepository
+	storage    *storage.Storage
+	key        []byte
+}
+
+func NewMemberCardService(memberRepo *repository.MemberRepository, storage *storage.Storage, cfg *config.Config) *MemberCardService {
+	return &MemberCardService{
+		memberRepo: memberRepo,
+		storage:    storage,
+		key:        cardSigningKey(cfg),
+	}
+}
+
+// cardSigningKey returns the configured card key, or one derived from the
+// JWT secret so card tokens can never pass as access tokens
+func cardSigningKey(cfg *config.Config) []byte {
+	if cfg.CardSigningKey != "" {
+		return []byte(cfg.CardSigningKey)
+	}
+	return deriveSigningKey(cfg.JWTSecret, cardTokenAudience)
+}
+
+// deriveSigningKey derives a key for one kind of token from a shared secret
+func deriveSigningKey(secret, purpose string) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(purpose))
+	return mac.Sum(nil)
+}
+
+// Render writes the member's printable membership card as a PDF
+func (s *MemberCardService) Render(ctx context.Context, memberID uuid.UUID, w io.Writer) (*models.Member, error) {
+	member, err := s.memberRepo.GetByID(ctx, memberID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	token, err := s.issueToken(member, now)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := renderCard(w, member, s.avatar(ctx, member), token, now); err != nil {
+		return nil, err
+	}
+	return member, nil
+}
+
+// Verify checks a card token and reports whether the membership is active
+// right now. A bad or foreign token is not an error; it is reported as
+// invalid.
+func (s *MemberCardService) Verify(ctx context.Context, token string) (*models.CardVerification, error) {
+	claims, err := parseCardToken(token, s.key)
+	if err != nil {
+		return &models.CardVerification{}, nil
+	}
+
+	id, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return &models.CardVerification{}, nil
+	}
+
+	member, err := s.memberRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return &models.CardVerification{}, nil
+		}
+		return nil, err
+	}
+
+	issuedAt := claims.IssuedAt.Time
+	return &models.CardVerification{
+		Valid:               true,
+		Active:              membershipActive(member, time.Now()),
+		ID:                  &member.ID,
+		MemberID:            &member.MemberID,
+		FirstName:           &member.FirstName,
+		LastName:            &member.LastName,
+		OrganizationName:    member.OrganizationName,
+		Status:              &member.Status,
+		MembershipExpiresAt: member.MembershipExpiresAt,
+		IssuedAt:            &issuedAt,
+	}, nil
+}
+
+func (s *MemberCardService) issueToken(member *models.Member, now time.Time) (string, error) {
+	claims := cardClaims{
+		MemberID: member.MemberID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:  member.ID.String(),
+			Audience: jwt.ClaimStrings{cardTokenAudience},
+			IssuedAt: jwt.NewNumericDate(now),
+		},
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.key)
+}
+
+func parseCardToken(token string, key []byte) (*cardClaims, error) {
+	claims := new(cardClaims)
+	_, err := jwt.ParseWithClaims(token, claims, func(*jwt.Token) (interface{}, error) {
+		return key, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithAudience(cardTokenAudience), jwt.WithIssuedAt())
+	if err != nil {
+		return nil, err
+	}
+	if claims.IssuedAt == nil {
+		return nil, errors.New("card token has no issue time")
+	}
+	return claims, nil
+}
+
+// membershipActive reports whether the member is active and the membership
+// has not expired
+func membershipActive(member *models.Member, now time.Time) bool {
+	if member.Status != models.MemberStatusActive {
+		return false
+	}
+	return member.MembershipExpiresAt == nil || member.MembershipExpiresAt.After(now)
+}
+
+// avatar returns the member's avatar as JPEG if it was uploaded to our
+// storage. Avatars hosted elsewhere are not fetched.
+func (s *MemberCardService) avatar(ctx context.Context, member *models.Member) []byte {
+	if member.AvatarURL == nil {
+		return nil
+	}
+	key, ok := s.storage.KeyFromURL(*member.AvatarURL)
+	if !ok || !strings.HasPrefix(key, avatarImage.Prefix) {
+		return nil
+	}
+
+	obj, err := s.storage.Get(ctx, key)
+	if err != nil {
+		log.Warn().Err(err).Str("object_key", key).Msg("Failed to open avatar for card")
+		return nil
+	}
+	defer obj.Close()
+
+	data, err := io.ReadAll(io.LimitReader(obj, maxCardAvatarSize))
+	if err != nil {
+		log.Warn().Err(err).Str("object_key", key).Msg("Failed to read avatar for card")
+		return nil
+	}
+	return data
+}
+
+// renderCard draws an ID-1 size card: header, photo (or initials), name,
+// member code, organization, expiry and the QR code with the signed token
+func renderCard(w io.Writer, member *models.Member, avatar []byte, token string, now time.Time) error {
+	qr, err := qrcode.New(token, qrcode.Medium)
+	if err != nil {
+		return err
+	}
+	qr.DisableBorder = true
+	qrPNG, err := qr.PNG(384)
+	if err != nil {
+		return err
+	}
+
+	pdf := fpdf.NewCustom(&fpdf.InitType{
+		OrientationStr: "L",
+		UnitStr:        "mm",
+		Size:           fpdf.SizeType{Wd: cardWidth, Ht: cardHeight},
+	})
+	pdf.SetMargins(0, 0, 0)
+	pdf.SetAutoPageBreak(false, 0)
+	pdf.SetTitle(fmt.Sprintf("Гишүүний үнэмлэх %s", member.MemberID), true)
+	pdf.AddUTF8FontFromBytes(fonts.Family, "", fonts.Regular)
+	pdf.AddUTF8FontFromBytes(fonts.Family, "B", fonts.Bold)
+	pdf.AddPage()
+
+	// Header
+	pdf.SetFillColor(200, 16, 46)
+	pdf.Rect(0, 0, cardWidth, 10, "F")
+	pdf.SetTextColor(255, 255, 255)
+	pdf.SetFont(fonts.Family, "B", 9)
+	pdf.SetXY(4, 0)
+	pdf.CellFormat(cardWidth-8, 10, "ГИШҮҮНИЙ ҮНЭМЛЭХ", "", 0, "L", false, 0, "")
+	pdf.SetFont(fonts.Family, "", 7)
+	pdf.SetXY(4, 0)
+	pdf.CellFormat(cardWidth-8, 10, "СДЗН", "", 0, "R", false, 0, "")
+
+	// Photo
+	const photoX, photoY, photoW, photoH = 4.0, 13.0, 20.0, 25.0
+	hasPhoto := false
+	if avatar != nil && pdf.Ok() {
+		// A broken avatar must not prevent the card
+		pdf.RegisterImageOptionsReader("avatar", fpdf.ImageOptions{ImageType: "JPG"}, bytes.NewReader(avatar))
+		hasPhoto = pdf.Ok()
+		pdf.ClearError()
+	}
+	if hasPhoto {
+		pdf.ImageOptions("avatar", photoX, photoY, photoW, photoH, false, fpdf.ImageOptions{ImageType: "JPG"}, 0, "")
+	} else {
+		pdf.SetFillColor(230, 230, 230)
+		pdf.Rect(photoX, photoY, photoW, photoH, "F")
+		pdf.SetTextColor(120, 120, 120)
+		pdf.SetFont(fonts.Family, "B", 14)
+		pdf.SetXY(photoX, photoY)
+		pdf.CellFormat(photoW, photoH, initials(member), "", 0, "CM", false, 0, "")
+	}
+
+	// Details
+	const textX, textW = 27.0, 31.0
+	pdf.SetTextColor(0, 0, 0)
+	pdf.SetFont(fonts.Family, "B", 10)
+	pdf.SetXY(textX, 13)
+	pdf.CellFormat(textW, 5, fitText(pdf, member.FirstName, textW), "", 0, "L", false, 0, "")
+	pdf.SetFont(fonts.Family, "", 8)
+	pdf.SetXY(textX, 18)
+	pdf.CellFormat(textW, 4, fitText(pdf, member.LastName, textW), "", 0, "L", false, 0, "")
+
+	organization := "-"
+	if member.OrganizationName != nil {
+		organization = *member.OrganizationName
+	}
+	expiry := "Хугацаагүй"
+	if member.MembershipExpiresAt != nil {
+		expiry = member.MembershipExpiresAt.Format("2006-01-02")
+	}
+
+	y := 24.0
+	for _, field := range [][2]string{
+		{"Гишүүний дугаар", member.MemberID},
+		{"Байгууллага", organization},
+		{"Хүчинтэй хугацаа", expiry},
+	} {
+		pdf.SetTextColor(110, 110, 110)
+		pdf.SetFont(fonts.Family, "", 5)
+		pdf.SetXY(textX, y)
+		pdf.CellFormat(textW, 2.5, field[0], "", 0, "L", false, 0, "")
+		pdf.SetTextColor(0, 0, 0)
+		pdf.SetFont(fonts.Family, "", 7)
+		pdf.SetXY(textX, y+2.5)
+		pdf.CellFormat(textW, 3.5, fitText(pdf, field[1], textW), "", 0, "L", false, 0, "")
+		y += 7
+	}
+
+	// QR code
+	pdf.RegisterImageOptionsReader("qr", fpdf.ImageOptions{ImageType: "PNG"}, bytes.NewReader(qrPNG))
+	pdf.ImageOptions("qr", 60, 13, 22, 22, false, fpdf.ImageOptions{ImageType: "PNG"}, 0, "")
+	pdf.SetTextColor(110, 110, 110)
+	pdf.SetFont(fonts.Family, "", 5)
+	pdf.SetXY(60, 35.5)
+	pdf.CellFormat(22, 2.5, "Уншуулж шалгана уу", "", 0, "C", false, 0, "")
+
+	// Footer
+	pdf.SetXY(4, cardHeight-6)
+	pdf.CellFormat(cardWidth-8, 3, "Олгосон: "+now.Format("2006-01-02"), "", 0, "R", false, 0, "")
+
+	return pdf.Output(w)
+}
+
+// fitText shortens s with an ellipsis until it fits in width mm in the
+// current font
+func fitText(pdf *fpdf.Fpdf, s string, width float64) string {
+	if pdf.GetStringWidth(s) <= width {
+		return s
+	}
+	for s != "" {
+		_, size := utf8.DecodeLastRuneInString(s)
+		s = s[:len(s)-size]
+		if pdf.GetStringWidth(s+"…") <= width {
+			break
+		}
+	}
+	return strings.TrimSpace(s) + "…"
+}
+
+func initials(member *models.Member) string {
+	var b strings.Builder
+	for _, name := range []string{member.LastName, member.FirstName} {
+		if r, _ := utf8.DecodeRuneInString(name); r != utf8.RuneError {
+			b.WriteRune(r)
+		}
+	}
+	return strings.ToUpper(b.String())
+}
@@ -0,0 +1,91 @@
+package services
+
+import (
+	"bytes"
+	"image"
+	"image/jpeg"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sdyn/backend/internal/config"
+	"github.com/sdyn/backend/internal/models"
+)
+
+func TestCardTokenRoundTrip(t *testing.T) {
+	s := &MemberCardService{key: cardSigningKey(&config.Config{JWTSecret: "secret"})}
+	member := &models.Member{ID: uuid.New(), MemberID: "SDYN-2026-00042"}
+
+	token, err := s.issueToken(member, time.Now())
+	require.NoError(t, err)
+
+	claims, err := parseCardToken(token, s.key)
+	require.NoError(t, err)
+	assert.Equal(t, member.ID.String(), claims.Subject)
+	assert.Equal(t, member.MemberID, claims.MemberID)
+
+	_, err = parseCardToken(token, cardSigningKey(&config.Config{JWTSecret: "other"}))
+	assert.Error(t, err, "token signed with another key")
+
+	_, err = parseCardToken(token[:len(token)-2]+"xx", s.key)
+	assert.Error(t, err, "tampered signature")
+}
+
+func TestCardSigningKeyIsNotTheJWTSecret(t *testing.T) {
+	cfg := &config.Config{JWTSecret: "secret"}
+	assert.NotEqual(t, []byte(cfg.JWTSecret), cardSigningKey(cfg))
+
+	cfg.CardSigningKey = "card-key"
+	assert.Equal(t, []byte("card-key"), cardSigningKey(cfg))
+
+	// An access token signed with the JWT secret is not a card token
+	access, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
+		Subject:  uuid.NewString(),
+		IssuedAt: jwt.NewNumericDate(time.Now()),
+	}).SignedString([]byte(cfg.JWTSecret))
+	require.NoError(t, err)
+	_, err = parseCardToken(access, []byte(cfg.JWTSecret))
+	assert.Error(t, err, "missing card audience")
+}
+
+func TestMembershipActive(t *testing.T) {
+	now := time.Now()
+	past, future := now.Add(-time.Hour), now.Add(time.Hour)
+
+	assert.True(t, membershipActive(&models.Member{Status: models.MemberStatusActive}, now))
+	assert.True(t, membershipActive(&models.Member{Status: models.MemberStatusActive, MembershipExpiresAt: &future}, now))
+	assert.False(t, membershipActive(&models.Member{Status: models.MemberStatusActive, MembershipExpiresAt: &past}, now))
+	assert.False(t, membershipActive(&models.Member{Status: models.MemberStatusSuspended}, now))
+}
+
+func TestRenderCard(t *testing.T) {
+	org := "Сүхбаатар дүүргийн салбар"
+	expires := time.Date(2027, 3, 1, 0, 0, 0, 0, time.UTC)
+	member := &models.Member{
+		ID:                  uuid.New(),
+		MemberID:            "SDYN-2026-00042",
+		FirstName:           "Өлзийбаяр",
+		LastName:            "Үүрцайх",
+		OrganizationName:    &org,
+		MembershipExpiresAt: &expires,
+	}
+
+	var photo bytes.Buffer
+	require.NoError(t, jpeg.Encode(&photo, image.NewRGBA(image.Rect(0, 0, 64, 80)), nil))
+
+	for name, avatar := range map[string][]byte{
+		"with photo":    photo.Bytes(),
+		"without photo": nil,
+		"broken photo":  []byte("not a jpeg"),
+	} {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, renderCard(&buf, member, avatar, "token", time.Now()))
+			assert.True(t, bytes.HasPrefix(buf.Bytes(), []byte("%PDF-")))
+		})
+	}
+}
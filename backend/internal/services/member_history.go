@@ -0,0 +1,313 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/sdyn/backend/internal/models"
+)
+
+// ErrMemberNotYetCreated is returned for an "as of" view before the member
+// was registered
+var ErrMemberNotYetCreated = errors.New("member did not exist at that time")
+
+// memberField reads and writes one tracked member field as its history
+// string form
+type memberField struct {
+	name      string
+	sensitive bool
+	get       func(m *models.Member) *string
+	set       func(m *models.Member, v *string)
+}
+
+// memberFields are the fields diffed on update, in response order
+var memberFields = []memberField{
+	{name: "first_name",
+		get: func(m *models.Member) *string { return &m.FirstName },
+		set: func(m *models.Member, v *string) { m.FirstName = derefString(v) }},
+	{name: "last_name",
+		get: func(m *models.Member) *string { return &m.LastName },
+		set: func(m *models.Member, v *string) { m.LastName = derefString(v) }},
+	{name: "gender",
+		get: func(m *models.Member) *string { return enumString(m.Gender) },
+		set: func(m *models.Member, v *string) { m.Gender = enumPtr[models.Gender](v) }},
+	{name: "birth_date",
+		get: func(m *models.Member) *string { return dateString(m.BirthDate) },
+		set: func(m *models.Member, v *string) { m.BirthDate = parseDate(v) }},
+	{name: "national_id", sensitive: true,
+		get: func(m *models.Member) *string { return m.NationalID },
+		set: func(m *models.Member, v *string) { m.NationalID = v }},
+	{name: "email",
+		get: func(m *models.Member) *string { return m.Email },
+		set: func(m *models.Member, v *string) { m.Email = v }},
+	{name: "phone",
+		get: func(m *models.Member) *string { return m.Phone },
+		set: func(m *models.Member, v *string) { m.Phone = v }},
+	{name: "address",
+		get: func(m *models.Member) *string { return m.Address },
+		set: func(m *models.Member, v *string) { m.Address = v }},
+	{name: "province_id",
+		get: func(m *models.Member) *string { return uuidString(m.ProvinceID) },
+		set: func(m *models.Member, v *string) { m.ProvinceID = parseUUID(v) }},
+	{name: "district_id",
+		get: func(m *models.Member) *string { return uuidString(m.DistrictID) },
+		set: func(m *models.Member, v *string) { m.DistrictID = parseUUID(v) }},
+	{name: "organization_id",
+		get: func(m *models.Member) *string { return uuidString(m.OrganizationID) },
+		set: func(m *models.Member, v *string) { m.OrganizationID = parseUUID(v) }},
+	{name: "education",
+		get: func(m *models.Member) *string { return enumString(m.Education) },
+		set: func(m *models.Member, v *string) { m.Education = enumPtr[models.EducationLevel](v) }},
+	{name: "occupation",
+		get: func(m *models.Member) *string { return m.Occupation },
+		set: func(m *models.Member, v *string) { m.Occupation = v }},
+	{name: "workplace",
+		get: func(m *models.Member) *string { return m.Workplace },
+		set: func(m *models.Member, v *string) { m.Workplace = v }},
+	{name: "avatar_url",
+		get: func(m *models.Member) *string { return m.AvatarURL },
+		set: func(m *models.Member, v *string) { m.AvatarURL = v }},
+	{name: "bio",
+		get: func(m *models.Member) *string { return m.Bio },
+		set: func(m *models.Member, v *string) { m.Bio = v }},
+	{name: "notes",
+		get: func(m *models.Member) *string { return m.Notes },
+		set: func(m *models.Member, v *string) { m.Notes = v }},
+}
+
+// statusHistoryActions are the history actions whose old/new values are
+// member statuses
+var statusHistoryActions = map[string]bool{
+	"status_change":      true,
+	"approved":           true,
+	"rejected":           true,
+	"membership_expired": true,
+}
+
+// diffMember returns the tracked fields that differ between before and after
+func diffMember(before, after *models.Member) []models.FieldChange {
+	var changes []models.FieldChange
+	for _, f := range memberFields {
+		oldValue, newValue := f.get(before), f.get(after)
+		if equalStringPtr(oldValue, newValue) {
+			continue
+		}
+		changes = append(changes, models.FieldChange{
+			Field:    f.name,
+			OldValue: copyStringPtr(oldValue),
+			NewValue: copyStringPtr(newValue),
+		})
+	}
+	return changes
+}
+
+// memberAsOf rebuilds the member record as it was at the given time by
+// undoing, newest first, every history entry recorded after it. history must
+// be ordered newest first, as returned by the repository.
+func memberAsOf(current *models.Member, history []models.MemberHistory, at time.Time) (*models.Member, error) {
+	if at.Before(current.CreatedAt) {
+		return nil, ErrMemberNotYetCreated
+	}
+
+	member := *current
+	for _, h := range history {
+		if !h.CreatedAt.After(at) {
+			break
+		}
+		switch {
+		case len(h.Changes) > 0:
+			for _, change := range h.Changes {
+				if f, ok := findMemberField(change.Field); ok {
+					f.set(&member, copyStringPtr(change.OldValue))
+				}
+			}
+		case statusHistoryActions[h.Action] && h.OldValue != nil:
+			member.Status = models.MemberStatus(*h.OldValue)
+		case h.Action == "membership_renewed":
+			member.MembershipExpiresAt = parseDate(h.OldValue)
+		}
+	}
+
+	// Joined names describe the current references only
+	if !equalUUIDPtr(member.ProvinceID, current.ProvinceID) {
+		member.ProvinceName = nil
+	}
+	if !equalUUIDPtr(member.DistrictID, current.DistrictID) {
+		member.DistrictName = nil
+	}
+	if !equalUUIDPtr(member.OrganizationID, current.OrganizationID) {
+		member.OrganizationName = nil
+	}
+	member.UpdatedAt = at
+
+	return &member, nil
+}
+
+// maskHistory hides the values of sensitive fields in history entries
+func maskHistory(history []models.MemberHistory) {
+	for i := range history {
+		for j := range history[i].Changes {
+			change := &history[i].Changes[j]
+			if f, ok := findMemberField(change.Field); ok && f.sensitive {
+				change.OldValue = maskValue(change.OldValue)
+				change.NewValue = maskValue(change.NewValue)
+			}
+		}
+	}
+}
+
+// maskMember hides the sensitive fields of member
+func maskMember(member *models.Member) {
+	for _, f := range memberFields {
+		if f.sensitive {
+			f.set(member, maskValue(f.get(member)))
+		}
+	}
+}
+
+// maskValue keeps the last two characters of v so masked values can still
+// be told apart
+func maskValue(v *string) *string {
+	if v == nil {
+		return nil
+	}
+	runes := []rune(*v)
+	keep := 2
+	if len(runes) <= keep*2 {
+		keep = 0
+	}
+	masked := strings.Repeat("*", len(runes)-keep) + string(runes[len(runes)-keep:])
+	return &masked
+}
+
+// GetHistory returns the member's history, newest first. Sensitive field
+// values are masked unless showSensitive is set.
+func (s *MemberService) GetHistory(ctx context.Context, memberID uuid.UUID, showSensitive bool) ([]models.MemberHistory, error) {
+	history, err := s.repo.GetHistory(ctx, memberID)
+	if err != nil {
+		return nil, err
+	}
+	if !showSensitive {
+		maskHistory(history)
+	}
+	return history, nil
+}
+
+// GetAsOf returns the member record as it was at the given time, rebuilt
+// from the member history
+func (s *MemberService) GetAsOf(ctx context.Context, memberID uuid.UUID, at time.Time, showSensitive bool) (*models.Member, error) {
+	current, err := s.repo.GetByID(ctx, memberID)
+	if err != nil {
+		return nil, err
+	}
+
+	history, err := s.repo.GetHistory(ctx, memberID)
+	if err != nil {
+		return nil, err
+	}
+
+	member, err := memberAsOf(current, history, at)
+	if err != nil {
+		return nil, err
+	}
+	if !showSensitive {
+		maskMember(member)
+	}
+	return member, nil
+}
+
+func findMemberField(name string) (memberField, bool) {
+	for _, f := range memberFields {
+		if f.name == name {
+			return f, true
+		}
+	}
+	return memberField{}, false
+}
+
+func equalStringPtr(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func equalUUIDPtr(a, b *uuid.UUID) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func copyStringPtr(v *string) *string {
+	if v == nil {
+		return nil
+	}
+	c := *v
+	return &c
+}
+
+func derefString(v *string) string {
+	if v == nil {
+		return ""
+	}
+	return *v
+}
+
+func enumString[T ~string](v *T) *string {
+	if v == nil {
+		return nil
+	}
+	s := string(*v)
+	return &s
+}
+
+func enumPtr[T ~string](v *string) *T {
+	if v == nil {
+		return nil
+	}
+	e := T(*v)
+	return &e
+}
+
+func uuidString(v *uuid.UUID) *string {
+	if v == nil {
+		return nil
+	}
+	s := v.String()
+	return &s
+}
+
+func parseUUID(v *string) *uuid.UUID {
+	if v == nil {
+		return nil
+	}
+	id, err := uuid.Parse(*v)
+	if err != nil {
+		return nil
+	}
+	return &id
+}
+
+func dateString(v *time.Time) *string {
+	if v == nil {
+		return nil
+	}
+	s := v.Format("2006-01-02")
+	return &s
+}
+
+func parseDate(v *string) *time.Time {
+	if v == nil {
+		return nil
+	}
+	t, err := time.Parse("2006-01-02", *v)
+	if err != nil {
+		return nil
+	}
+	return &t
+}
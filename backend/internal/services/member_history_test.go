@@ -0,0 +1,122 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sdyn/backend/internal/models"
+)
+
+func TestDiffMember(t *testing.T) {
+	orgID := uuid.New()
+	newOrgID := uuid.New()
+	phone := "99001122"
+	nationalID := "УБ99112233"
+
+	before := &models.Member{FirstName: "Бат", LastName: "Дорж", Phone: &phone, OrganizationID: &orgID}
+	after := *before
+	after.FirstName = "Болд"
+	after.Phone = nil
+	after.NationalID = &nationalID
+	after.OrganizationID = &newOrgID
+
+	changes := diffMember(before, &after)
+	require.Len(t, changes, 4)
+
+	assert.Equal(t, "first_name", changes[0].Field)
+	assert.Equal(t, "Бат", *changes[0].OldValue)
+	assert.Equal(t, "Болд", *changes[0].NewValue)
+
+	assert.Equal(t, "national_id", changes[1].Field)
+	assert.Nil(t, changes[1].OldValue)
+
+	assert.Equal(t, "phone", changes[2].Field)
+	assert.Nil(t, changes[2].NewValue)
+
+	assert.Equal(t, "organization_id", changes[3].Field)
+	assert.Equal(t, orgID.String(), *changes[3].OldValue)
+	assert.Equal(t, newOrgID.String(), *changes[3].NewValue)
+
+	assert.Empty(t, diffMember(before, before))
+}
+
+func TestMemberAsOf(t *testing.T) {
+	created := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	expires := time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)
+	phone := "88001122"
+	orgName := "Шинэ салбар"
+	orgID := uuid.New()
+
+	current := &models.Member{
+		FirstName:           "Болд",
+		Phone:               &phone,
+		OrganizationID:      &orgID,
+		OrganizationName:    &orgName,
+		Status:              models.MemberStatusActive,
+		MembershipExpiresAt: &expires,
+		CreatedAt:           created,
+	}
+
+	// Newest first, as returned by the repository
+	history := []models.MemberHistory{
+		{Action: "membership_renewed", OldValue: stringPtr("2026-01-01"), NewValue: stringPtr("2027-01-01"), CreatedAt: time.Date(2025, 12, 20, 0, 0, 0, 0, time.UTC)},
+		{Action: "updated", CreatedAt: time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC), Changes: []models.FieldChange{
+			{Field: "first_name", OldValue: stringPtr("Бат"), NewValue: stringPtr("Болд")},
+			{Field: "phone", OldValue: nil, NewValue: stringPtr(phone)},
+			{Field: "organization_id", OldValue: nil, NewValue: stringPtr(orgID.String())},
+		}},
+		{Action: "approved", OldValue: stringPtr("pending"), NewValue: stringPtr("active"), CreatedAt: time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	t.Run("before the update", func(t *testing.T) {
+		member, err := memberAsOf(current, history, time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC))
+		require.NoError(t, err)
+		assert.Equal(t, "Бат", member.FirstName)
+		assert.Nil(t, member.Phone)
+		assert.Nil(t, member.OrganizationID)
+		assert.Nil(t, member.OrganizationName)
+		assert.Equal(t, models.MemberStatusActive, member.Status)
+		assert.Equal(t, "2026-01-01", member.MembershipExpiresAt.Format("2006-01-02"))
+	})
+
+	t.Run("before approval", func(t *testing.T) {
+		member, err := memberAsOf(current, history, time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC))
+		require.NoError(t, err)
+		assert.Equal(t, models.MemberStatusPending, member.Status)
+	})
+
+	t.Run("now", func(t *testing.T) {
+		member, err := memberAsOf(current, history, time.Now())
+		require.NoError(t, err)
+		assert.Equal(t, "Болд", member.FirstName)
+		assert.Equal(t, &orgName, member.OrganizationName)
+		// The current record is left untouched
+		assert.Equal(t, "Болд", current.FirstName)
+	})
+
+	t.Run("before registration", func(t *testing.T) {
+		_, err := memberAsOf(current, history, created.Add(-time.Hour))
+		assert.ErrorIs(t, err, ErrMemberNotYetCreated)
+	})
+}
+
+func TestMaskHistory(t *testing.T) {
+	history := []models.MemberHistory{{Action: "updated", Changes: []models.FieldChange{
+		{Field: "national_id", OldValue: stringPtr("УБ99112233"), NewValue: stringPtr("УБ99112244")},
+		{Field: "phone", OldValue: stringPtr("99001122"), NewValue: stringPtr("88001122")},
+	}}}
+
+	maskHistory(history)
+
+	assert.Equal(t, "********33", *history[0].Changes[0].OldValue)
+	assert.Equal(t, "********44", *history[0].Changes[0].NewValue)
+	assert.Equal(t, "99001122", *history[0].Changes[1].OldValue)
+
+	short := "123"
+	assert.Equal(t, "***", *maskValue(&short))
+	assert.Nil(t, maskValue(nil))
+}
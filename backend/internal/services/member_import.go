@@ -0,0 +1,492 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/xuri/excelize/v2"
+
+	"github.com/sdyn/backend/internal/models"
+)
+
+// maxImportRows caps the number of data rows accepted in one import file
+const maxImportRows = 5000
+
+// memberImportColumns maps normalized header names onto CreateMemberRequest
+// fields. Header names are the JSON field names; the headers produced by the
+// member export normalize to the same names, so exported files can be
+// re-imported.
+var memberImportColumns = map[string]func(req *models.CreateMemberRequest, v string){
+	"first_name": func(req *models.CreateMemberRequest, v string) { req.FirstName = v },
+	"last_name":  func(req *models.CreateMemberRequest, v string) { req.LastName = v },
+	"gender": func(req *models.CreateMemberRequest, v string) {
+		g := models.Gender(strings.ToLower(v))
+		req.Gender = &g
+	},
+	"birth_date":      func(req *models.CreateMemberRequest, v string) { req.BirthDate = &v },
+	"national_id":     func(req *models.CreateMemberRequest, v string) { v = strings.ToUpper(v); req.NationalID = &v },
+	"email":           func(req *models.CreateMemberRequest, v string) { v = strings.ToLower(v); req.Email = &v },
+	"phone":           func(req *models.CreateMemberRequest, v string) { req.Phone = &v },
+	"address":         func(req *models.CreateMemberRequest, v string) { req.Address = &v },
+	"province_id":     func(req *models.CreateMemberRequest, v string) { req.ProvinceID = &v },
+	"district_id":     func(req *models.CreateMemberRequest, v string) { req.DistrictID = &v },
+	"organization_id": func(req *models.CreateMemberRequest, v string) { req.OrganizationID = &v },
+	"education":       func(req *models.CreateMemberRequest, v string) { v = strings.ToLower(v); req.Education = &v },
+	"occupation":      func(req *models.CreateMemberRequest, v string) { req.Occupation = &v },
+	"workplace":       func(req *models.CreateMemberRequest, v string) { req.Workplace = &v },
+	"referred_by":     func(req *models.CreateMemberRequest, v string) { req.ReferredBy = &v },
+	"notes":           func(req *models.CreateMemberRequest, v string) { req.Notes = &v },
+}
+
+// ImportScope restricts imported members to the importer's data scope. Rows
+// without a value are assigned to the scope; rows outside it are rejected.
+type ImportScope struct {
+	ProvinceID     *string
+	DistrictID     *string
+	OrganizationID *string
+}
+
+// ParseMemberImport reads a CSV or XLSX member import file. The first row
+// must be a header; unknown columns are ignored.
+func ParseMemberImport(r io.Reader, format string) ([]models.MemberImportRow, error) {
+	table, err := readImportTable(r, format)
+	if err != nil {
+		return nil, err
+	}
+	if len(table) == 0 {
+		return nil, &ImportError{Message: "File is empty"}
+	}
+
+	setters := make([]func(*models.CreateMemberRequest, string), len(table[0]))
+	found := map[string]bool{}
+	for i, header := range table[0] {
+		name := normalizeImportHeader(header)
+		if set, ok := memberImportColumns[name]; ok && !found[name] {
+			setters[i] = set
+			found[name] = true
+		}
+	}
+	for _, required := range []string{"first_name", "last_name"} {
+		if !found[required] {
+			return nil, &ImportError{Message: fmt.Sprintf("Missing required column: %s", required)}
+		}
+	}
+
+	rows := []models.MemberImportRow{}
+	for i, record := range table[1:] {
+		if isBlankRecord(record) {
+			continue
+		}
+		if len(rows) == maxImportRows {
+			return nil, &ImportError{Message: fmt.Sprintf("File has more than %d rows", maxImportRows)}
+		}
+
+		row := models.MemberImportRow{Row: i + 2}
+		for j, value := range record {
+			value = strings.TrimSpace(value)
+			if j < len(setters) && setters[j] != nil && value != "" {
+				setters[j](&row.Request, value)
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	if len(rows) == 0 {
+		return nil, &ImportError{Message: "File has no data rows"}
+	}
+
+	return rows, nil
+}
+
+func readImportTable(r io.Reader, format string) ([][]string, error) {
+	switch format {
+	case "csv":
+		br := bufio.NewReader(r)
+		if bom, _ := br.Peek(3); bytes.Equal(bom, []byte{0xEF, 0xBB, 0xBF}) {
+			br.Discard(3)
+		}
+		cr := csv.NewReader(br)
+		cr.FieldsPerRecord = -1
+		records, err := cr.ReadAll()
+		if err != nil {
+			return nil, &ImportError{Message: "Invalid CSV file: " + err.Error()}
+		}
+		return records, nil
+	case "xlsx":
+		f, err := excelize.OpenReader(r)
+		if err != nil {
+			return nil, &ImportError{Message: "Invalid XLSX file"}
+		}
+		defer f.Close()
+
+		sheets := f.GetSheetList()
+		if len(sheets) == 0 {
+			return nil, nil
+		}
+		return f.GetRows(sheets[0])
+	default:
+		return nil, &ImportError{Message: "Invalid format. Valid formats: csv, xlsx"}
+	}
+}
+
+func normalizeImportHeader(header string) string {
+	header = strings.ToLower(strings.TrimSpace(header))
+	header = strings.NewReplacer(" ", "_", "-", "_").Replace(header)
+	return header
+}
+
+func isBlankRecord(record []string) bool {
+	for _, v := range record {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// ImportValidationErrors converts the result of validating a row's
+// CreateMemberRequest into row errors keyed by JSON field name
+func ImportValidationErrors(row int, err error) []models.MemberImportError {
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return []models.MemberImportError{{Row: row, Message: err.Error()}}
+	}
+
+	reqType := reflect.TypeOf(models.CreateMemberRequest{})
+	result := make([]models.MemberImportError, 0, len(validationErrors))
+	for _, fe := range validationErrors {
+		field := fe.Field()
+		if sf, ok := reqType.FieldByName(fe.StructField()); ok {
+			field = strings.Split(sf.Tag.Get("json"), ",")[0]
+		}
+		result = append(result, models.MemberImportError{
+			Row:     row,
+			Field:   field,
+			Message: fmt.Sprintf("failed on '%s' validation", fe.Tag()),
+		})
+	}
+	return result
+}
+
+// Import checks import rows for invalid values, scope violations and
+// duplicates (within the file and against existing members), then creates
+// the members in one transaction. Nothing is written on a dry run or if any
+// row has errors. Rows should already have been validated with the request
+// validator, with failures recorded in row.Errors.
+func (s *MemberService) Import(ctx context.Context, rows []models.MemberImportRow, scope ImportScope, dryRun bool) (*models.MemberImportResult, error) {
+	members := make([]*models.Member, len(rows))
+	for i := range rows {
+		applyImportScope(&rows[i], scope)
+		members[i] = memberFromImportRow(&rows[i])
+	}
+
+	if err := s.checkImportPlacements(ctx, rows, scope); err != nil {
+		return nil, err
+	}
+	if err := s.checkImportDuplicates(ctx, rows); err != nil {
+		return nil, err
+	}
+
+	result := &models.MemberImportResult{
+		DryRun:    dryRun,
+		TotalRows: len(rows),
+		Errors:    []models.MemberImportError{},
+	}
+	valid := make([]*models.Member, 0, len(rows))
+	for i := range rows {
+		if len(rows[i].Errors) == 0 {
+			valid = append(valid, members[i])
+			continue
+		}
+		result.Errors = append(result.Errors, rows[i].Errors...)
+	}
+	result.ValidRows = len(valid)
+
+	sort.SliceStable(result.Errors, func(i, j int) bool {
+		return result.Errors[i].Row < result.Errors[j].Row
+	})
+
+	if dryRun || len(result.Errors) > 0 {
+		return result, nil
+	}
+
+	if err := s.repo.CreateBatch(ctx, valid); err != nil {
+		return nil, err
+	}
+	result.Imported = len(valid)
+
+	return result, nil
+}
+
+func applyImportScope(row *models.MemberImportRow, scope ImportScope) {
+	check := func(field string, value **string, scoped *string) {
+		if scoped == nil {
+			return
+		}
+		if *value == nil {
+			id := *scoped
+			*value = &id
+			return
+		}
+		if !strings.EqualFold(**value, *scoped) {
+			row.Errors = append(row.Errors, models.MemberImportError{
+				Row: row.Row, Field: field, Message: "outside your data scope",
+			})
+		}
+	}
+
+	check("province_id", &row.Request.ProvinceID, scope.ProvinceID)
+	check("district_id", &row.Request.DistrictID, scope.DistrictID)
+	check("organization_id", &row.Request.OrganizationID, scope.OrganizationID)
+}
+
+// checkImportPlacements rejects rows whose district or organization lies
+// outside the scope even though the scoped column matches, e.g. a district
+// of another province
+func (s *MemberService) checkImportPlacements(ctx context.Context, rows []models.MemberImportRow, scope ImportScope) error {
+	if scope.ProvinceID == nil && scope.DistrictID == nil && scope.OrganizationID == nil {
+		return nil
+	}
+
+	var districtIDs, organizationIDs []uuid.UUID
+	add := func(ids []uuid.UUID, value *string) []uuid.UUID {
+		if value == nil {
+			return ids
+		}
+		if id, err := uuid.Parse(*value); err == nil {
+			ids = append(ids, id)
+		}
+		return ids
+	}
+	districtIDs = add(districtIDs, scope.DistrictID)
+	organizationIDs = add(organizationIDs, scope.OrganizationID)
+	for _, row := range rows {
+		districtIDs = add(districtIDs, row.Request.DistrictID)
+		organizationIDs = add(organizationIDs, row.Request.OrganizationID)
+	}
+
+	districtProvinces, organizations, err := s.repo.FindPlacements(ctx, districtIDs, organizationIDs)
+	if err != nil {
+		return err
+	}
+
+	for i := range rows {
+		checkImportPlacement(&rows[i], scope, districtProvinces, organizations)
+	}
+	return nil
+}
+
+// checkImportPlacement checks that the row's district and organization lie
+// within the scope, given the province of each district and the province and
+// district of each organization. Run it after applyImportScope.
+func checkImportPlacement(row *models.MemberImportRow, scope ImportScope, districtProvinces map[uuid.UUID]uuid.UUID, organizations map[uuid.UUID]models.Organization) {
+	req := &row.Request
+	outside := func(field string) {
+		row.Errors = append(row.Errors, models.MemberImportError{
+			Row: row.Row, Field: field, Message: "outside your data scope",
+		})
+	}
+	// parse returns uuid.Nil for a missing or invalid ID; memberFromImportRow
+	// reports invalid ones
+	parse := func(value *string) uuid.UUID {
+		if value == nil {
+			return uuid.Nil
+		}
+		id, _ := uuid.Parse(*value)
+		return id
+	}
+	within := func(id *uuid.UUID, scoped uuid.UUID) bool {
+		return id != nil && *id == scoped
+	}
+
+	districtID := parse(req.DistrictID)
+	organizationID := parse(req.OrganizationID)
+	org, orgFound := organizations[organizationID]
+
+	if provinceID := parse(scope.ProvinceID); provinceID != uuid.Nil {
+		if districtID != uuid.Nil {
+			if p, ok := districtProvinces[districtID]; !ok || p != provinceID {
+				outside("district_id")
+			}
+		}
+		if organizationID != uuid.Nil && (!orgFound || !within(org.ProvinceID, provinceID)) {
+			outside("organization_id")
+		}
+	}
+
+	if scopeDistrictID := parse(scope.DistrictID); scopeDistrictID != uuid.Nil {
+		if provinceID := parse(req.ProvinceID); provinceID != uuid.Nil {
+			if p, ok := districtProvinces[scopeDistrictID]; !ok || p != provinceID {
+				outside("province_id")
+			}
+		}
+		if organizationID != uuid.Nil && (!orgFound || !within(org.DistrictID, scopeDistrictID)) {
+			outside("organization_id")
+		}
+	}
+
+	// An organization constrains members only down to its own level
+	if scopeOrgID := parse(scope.OrganizationID); scopeOrgID != uuid.Nil {
+		scoped := organizations[scopeOrgID]
+		if provinceID := parse(req.ProvinceID); provinceID != uuid.Nil && scoped.ProvinceID != nil && *scoped.ProvinceID != provinceID {
+			outside("province_id")
+		}
+		if districtID != uuid.Nil {
+			p, ok := districtProvinces[districtID]
+			switch {
+			case scoped.DistrictID != nil && *scoped.DistrictID != districtID,
+				scoped.DistrictID == nil && scoped.ProvinceID != nil && (!ok || p != *scoped.ProvinceID):
+				outside("district_id")
+			}
+		}
+	}
+}
+
+// memberFromImportRow converts a row into a member, recording values that
+// MemberService.Create would silently drop as row errors instead
+func memberFromImportRow(row *models.MemberImportRow) *models.Member {
+	req := &row.Request
+	addError := func(field, message string) {
+		row.Errors = append(row.Errors, models.MemberImportError{Row: row.Row, Field: field, Message: message})
+	}
+
+	member := &models.Member{
+		FirstName:  req.FirstName,
+		LastName:   req.LastName,
+		NationalID: req.NationalID,
+		Email:      req.Email,
+		Phone:      req.Phone,
+		Address:    req.Address,
+		Occupation: req.Occupation,
+		Workplace:  req.Workplace,
+		Notes:      req.Notes,
+		Status:     models.MemberStatusPending,
+	}
+
+	if req.Gender != nil {
+		switch *req.Gender {
+		case models.GenderMale, models.GenderFemale, models.GenderOther:
+			member.Gender = req.Gender
+		default:
+			addError("gender", "must be one of: male, female, other")
+		}
+	}
+
+	if req.BirthDate != nil {
+		birthDate, err := time.Parse("2006-01-02", *req.BirthDate)
+		if err != nil {
+			addError("birth_date", "must be a date in YYYY-MM-DD format")
+		} else {
+			member.BirthDate = &birthDate
+		}
+	}
+
+	if req.Education != nil {
+		edu := models.EducationLevel(*req.Education)
+		switch edu {
+		case models.EducationPrimary, models.EducationSecondary, models.EducationHighSchool,
+			models.EducationVocational, models.EducationBachelor, models.EducationMaster, models.EducationDoctorate:
+			member.Education = &edu
+		default:
+			addError("education", "invalid education level")
+		}
+	}
+
+	for _, f := range []struct {
+		field string
+		value *string
+		dest  **uuid.UUID
+	}{
+		{"province_id", req.ProvinceID, &member.ProvinceID},
+		{"district_id", req.DistrictID, &member.DistrictID},
+		{"organization_id", req.OrganizationID, &member.OrganizationID},
+		{"referred_by", req.ReferredBy, &member.ReferredBy},
+	} {
+		if f.value == nil {
+			continue
+		}
+		id, err := uuid.Parse(*f.value)
+		if err != nil {
+			addError(f.field, "must be a valid UUID")
+			continue
+		}
+		*f.dest = &id
+	}
+
+	return member
+}
+
+// checkImportDuplicates flags rows whose email, phone or national ID is
+// repeated within the file or already belongs to a member
+func (s *MemberService) checkImportDuplicates(ctx context.Context, rows []models.MemberImportRow) error {
+	var emails, phones, nationalIDs []string
+	for _, row := range rows {
+		if row.Request.Email != nil {
+			emails = append(emails, *row.Request.Email)
+		}
+		if row.Request.Phone != nil {
+			phones = append(phones, *row.Request.Phone)
+		}
+		if row.Request.NationalID != nil {
+			nationalIDs = append(nationalIDs, *row.Request.NationalID)
+		}
+	}
+
+	existingEmails, existingPhones, existingNationalIDs, err := s.repo.FindExistingIdentifiers(ctx, emails, phones, nationalIDs)
+	if err != nil {
+		return err
+	}
+
+	seen := map[string]int{}
+	for i := range rows {
+		row := &rows[i]
+		for _, f := range []struct {
+			field    string
+			value    *string
+			existing map[string]bool
+		}{
+			{"email", row.Request.Email, existingEmails},
+			{"phone", row.Request.Phone, existingPhones},
+			{"national_id", row.Request.NationalID, existingNationalIDs},
+		} {
+			if f.value == nil {
+				continue
+			}
+			if f.existing[*f.value] {
+				row.Errors = append(row.Errors, models.MemberImportError{
+					Row: row.Row, Field: f.field, Message: "a member with this value already exists",
+				})
+				continue
+			}
+			key := f.field + ":" + *f.value
+			if first, ok := seen[key]; ok {
+				row.Errors = append(row.Errors, models.MemberImportError{
+					Row: row.Row, Field: f.field, Message: fmt.Sprintf("duplicate of row %d", first),
+				})
+				continue
+			}
+			seen[key] = row.Row
+		}
+	}
+
+	return nil
+}
+
+type ImportError struct {
+	Message string
+}
+
+func (e *ImportError) Error() string {
+	return e.Message
+}
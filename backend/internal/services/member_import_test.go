@@ -0,0 +1,186 @@
+package services
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/xuri/excelize/v2"
+
+	"github.com/sdyn/backend/internal/models"
+)
+
+func TestParseMemberImport_CSV(t *testing.T) {
+	data := "\xef\xbb\xbfFirst name,Last name,Email,Unknown,national_id\n" +
+		"Бат,Болд,BAT@Example.com,x,уб99010112\n" +
+		",,,,\n" +
+		"Сараа,Дорж,,,\n"
+
+	rows, err := ParseMemberImport(strings.NewReader(data), "csv")
+
+	assert.NoError(t, err)
+	assert.Len(t, rows, 2)
+	assert.Equal(t, 2, rows[0].Row)
+	assert.Equal(t, "Бат", rows[0].Request.FirstName)
+	assert.Equal(t, "Болд", rows[0].Request.LastName)
+	assert.Equal(t, "bat@example.com", *rows[0].Request.Email)
+	assert.Equal(t, "УБ99010112", *rows[0].Request.NationalID)
+	assert.Equal(t, 4, rows[1].Row)
+	assert.Nil(t, rows[1].Request.Email)
+}
+
+func TestParseMemberImport_XLSX(t *testing.T) {
+	f := excelize.NewFile()
+	f.SetSheetRow("Sheet1", "A1", &[]interface{}{"first_name", "last_name", "phone"})
+	f.SetSheetRow("Sheet1", "A2", &[]interface{}{"Бат", "Болд", "99112233"})
+	var buf bytes.Buffer
+	assert.NoError(t, f.Write(&buf))
+
+	rows, err := ParseMemberImport(&buf, "xlsx")
+
+	assert.NoError(t, err)
+	assert.Len(t, rows, 1)
+	assert.Equal(t, "99112233", *rows[0].Request.Phone)
+}
+
+func TestParseMemberImport_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		format  string
+		message string
+	}{
+		{"empty file", "", "csv", "File is empty"},
+		{"missing column", "first_name,email\nБат,a@b.mn\n", "csv", "Missing required column: last_name"},
+		{"header only", "first_name,last_name\n", "csv", "File has no data rows"},
+		{"bad format", "first_name,last_name\n", "ods", "Invalid format. Valid formats: csv, xlsx"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseMemberImport(strings.NewReader(tt.data), tt.format)
+			assert.EqualError(t, err, tt.message)
+		})
+	}
+}
+
+func TestImportValidationErrors(t *testing.T) {
+	email := "not-an-email"
+	req := models.CreateMemberRequest{FirstName: "Б", LastName: "Болд", Email: &email}
+
+	errs := ImportValidationErrors(5, validator.New().Struct(&req))
+
+	assert.Len(t, errs, 2)
+	assert.Equal(t, models.MemberImportError{Row: 5, Field: "first_name", Message: "failed on 'min' validation"}, errs[0])
+	assert.Equal(t, "email", errs[1].Field)
+}
+
+func TestMemberFromImportRow(t *testing.T) {
+	gender := models.Gender("unknown")
+	birthDate := "1999-01-31"
+	orgID := "not-a-uuid"
+	row := &models.MemberImportRow{
+		Row: 3,
+		Request: models.CreateMemberRequest{
+			FirstName:      "Бат",
+			LastName:       "Болд",
+			Gender:         &gender,
+			BirthDate:      &birthDate,
+			OrganizationID: &orgID,
+		},
+	}
+
+	member := memberFromImportRow(row)
+
+	assert.Equal(t, models.MemberStatusPending, member.Status)
+	assert.Equal(t, "1999-01-31", member.BirthDate.Format("2006-01-02"))
+	assert.Nil(t, member.Gender)
+	assert.Nil(t, member.OrganizationID)
+	assert.Len(t, row.Errors, 2)
+	assert.Equal(t, "gender", row.Errors[0].Field)
+	assert.Equal(t, "organization_id", row.Errors[1].Field)
+}
+
+func TestApplyImportScope(t *testing.T) {
+	scopeOrg := "9b2f0d8e-6f1a-4c7e-9a51-2d3c4b5a6f70"
+	otherOrg := "0c1d2e3f-4a5b-6c7d-8e9f-a0b1c2d3e4f5"
+
+	unset := &models.MemberImportRow{Row: 2}
+	applyImportScope(unset, ImportScope{OrganizationID: &scopeOrg})
+	assert.Equal(t, scopeOrg, *unset.Request.OrganizationID)
+	assert.Empty(t, unset.Errors)
+
+	outside := &models.MemberImportRow{Row: 3, Request: models.CreateMemberRequest{OrganizationID: &otherOrg}}
+	applyImportScope(outside, ImportScope{OrganizationID: &scopeOrg})
+	assert.Equal(t, otherOrg, *outside.Request.OrganizationID)
+	assert.Len(t, outside.Errors, 1)
+	assert.Equal(t, "outside your data scope", outside.Errors[0].Message)
+}
+
+func TestCheckImportPlacement(t *testing.T) {
+	province, otherProvince := uuid.New(), uuid.New()
+	district, otherDistrict := uuid.New(), uuid.New()
+	org, otherOrg, provinceOrg := uuid.New(), uuid.New(), uuid.New()
+
+	districtProvinces := map[uuid.UUID]uuid.UUID{district: province, otherDistrict: otherProvince}
+	organizations := map[uuid.UUID]models.Organization{
+		org:         {ID: org, ProvinceID: &province, DistrictID: &district},
+		otherOrg:    {ID: otherOrg, ProvinceID: &otherProvince, DistrictID: &otherDistrict},
+		provinceOrg: {ID: provinceOrg, ProvinceID: &province},
+	}
+	id := func(id uuid.UUID) *string {
+		s := id.String()
+		return &s
+	}
+
+	tests := []struct {
+		name    string
+		scope   ImportScope
+		request models.CreateMemberRequest
+		fields  []string
+	}{
+		{"province scope, inside", ImportScope{ProvinceID: id(province)},
+			models.CreateMemberRequest{ProvinceID: id(province), DistrictID: id(district), OrganizationID: id(org)}, nil},
+		{"province scope, district of another province", ImportScope{ProvinceID: id(province)},
+			models.CreateMemberRequest{ProvinceID: id(province), DistrictID: id(otherDistrict)}, []string{"district_id"}},
+		{"province scope, organization of another province", ImportScope{ProvinceID: id(province)},
+			models.CreateMemberRequest{ProvinceID: id(province), OrganizationID: id(otherOrg)}, []string{"organization_id"}},
+		{"province scope, unknown organization", ImportScope{ProvinceID: id(province)},
+			models.CreateMemberRequest{ProvinceID: id(province), OrganizationID: id(uuid.New())}, []string{"organization_id"}},
+		{"district scope, inside", ImportScope{DistrictID: id(district)},
+			models.CreateMemberRequest{ProvinceID: id(province), DistrictID: id(district), OrganizationID: id(org)}, nil},
+		{"district scope, another province", ImportScope{DistrictID: id(district)},
+			models.CreateMemberRequest{ProvinceID: id(otherProvince), DistrictID: id(district)}, []string{"province_id"}},
+		{"district scope, organization of another district", ImportScope{DistrictID: id(district)},
+			models.CreateMemberRequest{DistrictID: id(district), OrganizationID: id(provinceOrg)}, []string{"organization_id"}},
+		{"organization scope, inside", ImportScope{OrganizationID: id(org)},
+			models.CreateMemberRequest{ProvinceID: id(province), DistrictID: id(district), OrganizationID: id(org)}, nil},
+		{"organization scope, another province and district", ImportScope{OrganizationID: id(org)},
+			models.CreateMemberRequest{ProvinceID: id(otherProvince), DistrictID: id(otherDistrict), OrganizationID: id(org)},
+			[]string{"province_id", "district_id"}},
+		{"province level organization, district of its province", ImportScope{OrganizationID: id(provinceOrg)},
+			models.CreateMemberRequest{DistrictID: id(district), OrganizationID: id(provinceOrg)}, nil},
+		{"province level organization, district of another province", ImportScope{OrganizationID: id(provinceOrg)},
+			models.CreateMemberRequest{DistrictID: id(otherDistrict), OrganizationID: id(provinceOrg)}, []string{"district_id"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			row := &models.MemberImportRow{Row: 2, Request: tt.request}
+			checkImportPlacement(row, tt.scope, districtProvinces, organizations)
+
+			fields := []string{}
+			for _, e := range row.Errors {
+				assert.Equal(t, "outside your data scope", e.Message)
+				fields = append(fields, e.Field)
+			}
+			if tt.fields == nil {
+				tt.fields = []string{}
+			}
+			assert.Equal(t, tt.fields, fields)
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package services
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/google/uuid"
+
+	"github.com/sdyn/backend/internal/models"
+)
+
+const (
+	// defaultDuplicateMinScore is the lowest score reported as a duplicate;
+	// a shared email, or a matching name and birth date, is enough
+	defaultDuplicateMinScore = 40
+	// maxDuplicateCandidates caps the candidate pairs scored per request
+	maxDuplicateCandidates = 1000
+)
+
+// Duplicate score weights per matching field. Scores are capped at 100.
+const (
+	duplicateWeightNationalID = 50
+	duplicateWeightEmail      = 40
+	duplicateWeightPhone      = 30
+	duplicateWeightName       = 25
+	duplicateWeightBirthDate  = 20
+)
+
+// FindDuplicates returns suspected duplicate member pairs, best matches first
+func (s *MemberService) FindDuplicates(ctx context.Context, params *models.DuplicateListParams) ([]models.DuplicateCandidate, error) {
+	if params.MinScore <= 0 {
+		params.MinScore = defaultDuplicateMinScore
+	}
+	if params.Limit <= 0 || params.Limit > 100 {
+		params.Limit = 50
+	}
+
+	pairs, err := s.repo.FindDuplicateCandidates(ctx, params, maxDuplicateCandidates)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := []models.DuplicateCandidate{}
+	for _, pair := range pairs {
+		score, reasons := scoreDuplicate(&pair[0], &pair[1])
+		if score < params.MinScore {
+			continue
+		}
+		candidates = append(candidates, models.DuplicateCandidate{
+			Member:    pair[0],
+			Duplicate: pair[1],
+			Score:     score,
+			Reasons:   reasons,
+		})
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Score > candidates[j].Score
+	})
+	if len(candidates) > params.Limit {
+		candidates = candidates[:params.Limit]
+	}
+
+	return candidates, nil
+}
+
+// Merge merges the duplicate member into the survivor. Fees, event
+// participations, positions and history move to the survivor and the
+// duplicate is deleted.
+func (s *MemberService) Merge(ctx context.Context, survivorID uuid.UUID, req *models.MergeMembersRequest, changedBy string) (*models.Member, error) {
+	duplicateID, err := uuid.Parse(req.DuplicateID)
+	if err != nil {
+		return nil, &MemberError{Message: "Invalid duplicate member ID"}
+	}
+	if duplicateID == survivorID {
+		return nil, &MemberError{Message: "Cannot merge a member into itself"}
+	}
+
+	survivor, err := s.repo.GetByID(ctx, survivorID)
+	if err != nil {
+		return nil, err
+	}
+
+	changedByUUID, _ := uuid.Parse(changedBy)
+	history := &models.MemberHistory{
+		Action:    "merged",
+		NewValue:  stringPtr(survivor.MemberID),
+		ChangedBy: &changedByUUID,
+		Reason:    req.Reason,
+	}
+
+	if err := s.repo.Merge(ctx, survivorID, duplicateID, history); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetByID(ctx, survivorID)
+}
+
+// scoreDuplicate scores how likely two members are the same person and
+// returns the fields that matched
+func scoreDuplicate(a, b *models.Member) (int, []string) {
+	score := 0
+	reasons := []string{}
+	match := func(reason string, weight int) {
+		score += weight
+		reasons = append(reasons, reason)
+	}
+
+	if equalNormalized(a.NationalID, b.NationalID, strings.ToUpper) {
+		match("national_id", duplicateWeightNationalID)
+	}
+	if equalNormalized(a.Email, b.Email, strings.ToLower) {
+		match("email", duplicateWeightEmail)
+	}
+	if equalNormalized(a.Phone, b.Phone, normalizePhone) {
+		match("phone", duplicateWeightPhone)
+	}
+
+	firstA, lastA := normalizeName(a.FirstName), normalizeName(a.LastName)
+	firstB, lastB := normalizeName(b.FirstName), normalizeName(b.LastName)
+	if firstA != "" && lastA != "" &&
+		((firstA == firstB && lastA == lastB) || (firstA == lastB && lastA == firstB)) {
+		match("name", duplicateWeightName)
+	}
+
+	if a.BirthDate != nil && b.BirthDate != nil &&
+		a.BirthDate.Format("2006-01-02") == b.BirthDate.Format("2006-01-02") {
+		match("birth_date", duplicateWeightBirthDate)
+	}
+
+	if score > 100 {
+		score = 100
+	}
+	return score, reasons
+}
+
+func equalNormalized(a, b *string, normalize func(string) string) bool {
+	if a == nil || b == nil {
+		return false
+	}
+	na, nb := normalize(strings.TrimSpace(*a)), normalize(strings.TrimSpace(*b))
+	return na != "" && na == nb
+}
+
+// normalizeName lower-cases a name and drops everything but letters, so
+// "Бат-Эрдэнэ" and "батэрдэнэ" compare equal
+func normalizeName(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// normalizePhone keeps the last 8 digits of a phone number, dropping
+// formatting and the +976 country code
+func normalizePhone(phone string) string {
+	var b strings.Builder
+	for _, r := range phone {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	digits := b.String()
+	if len(digits) > 8 {
+		digits = digits[len(digits)-8:]
+	}
+	return digits
+}
+
+type MemberError struct {
+	Message string
+}
+
+func (e *MemberError) Error() string {
+	return e.Message
+}
@@ -0,0 +1,74 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sdyn/backend/internal/models"
+)
+
+func TestScoreDuplicate(t *testing.T) {
+	birthDate := time.Date(1998, 4, 12, 0, 0, 0, 0, time.UTC)
+	otherBirthDate := time.Date(1999, 4, 12, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		a, b    models.Member
+		score   int
+		reasons []string
+	}{
+		{
+			name:    "same email, different case",
+			a:       models.Member{FirstName: "Бат", LastName: "Болд", Email: stringPtr("Bat@Example.com")},
+			b:       models.Member{FirstName: "Сараа", LastName: "Дорж", Email: stringPtr("bat@example.com ")},
+			score:   duplicateWeightEmail,
+			reasons: []string{"email"},
+		},
+		{
+			name:    "swapped name and birth date",
+			a:       models.Member{FirstName: "Бат-Эрдэнэ", LastName: "Болд", BirthDate: &birthDate},
+			b:       models.Member{FirstName: "болд", LastName: "батэрдэнэ", BirthDate: &birthDate},
+			score:   duplicateWeightName + duplicateWeightBirthDate,
+			reasons: []string{"name", "birth_date"},
+		},
+		{
+			name:    "phone with country code",
+			a:       models.Member{FirstName: "Бат", LastName: "Болд", Phone: stringPtr("+976 9911-2233"), BirthDate: &birthDate},
+			b:       models.Member{FirstName: "Сараа", LastName: "Болд", Phone: stringPtr("99112233"), BirthDate: &otherBirthDate},
+			score:   duplicateWeightPhone,
+			reasons: []string{"phone"},
+		},
+		{
+			name: "everything matches is capped",
+			a: models.Member{FirstName: "Бат", LastName: "Болд", NationalID: stringPtr("уб98041211"),
+				Email: stringPtr("bat@example.com"), Phone: stringPtr("99112233"), BirthDate: &birthDate},
+			b: models.Member{FirstName: "Бат", LastName: "Болд", NationalID: stringPtr("УБ98041211"),
+				Email: stringPtr("bat@example.com"), Phone: stringPtr("99112233"), BirthDate: &birthDate},
+			score:   100,
+			reasons: []string{"national_id", "email", "phone", "name", "birth_date"},
+		},
+		{
+			name:    "empty values never match",
+			a:       models.Member{FirstName: "Бат", LastName: "Болд", Email: stringPtr(""), Phone: stringPtr("-")},
+			b:       models.Member{FirstName: "Сараа", LastName: "Дорж", Email: stringPtr(""), Phone: stringPtr("-")},
+			score:   0,
+			reasons: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			score, reasons := scoreDuplicate(&tt.a, &tt.b)
+			assert.Equal(t, tt.score, score)
+			assert.Equal(t, tt.reasons, reasons)
+		})
+	}
+}
+
+func TestNormalizePhone(t *testing.T) {
+	assert.Equal(t, "99112233", normalizePhone("+976 9911-2233"))
+	assert.Equal(t, "99112233", normalizePhone("(9911) 2233"))
+	assert.Equal(t, "", normalizePhone("n/a"))
+}
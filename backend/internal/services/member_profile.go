@@ -0,0 +1,111 @@
+package services
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/sdyn/backend/internal/models"
+)
+
+// GetCompleteness returns the member's profile completeness score and the
+// filled and verified state of each profile field
+func (s *MemberService) GetCompleteness(ctx context.Context, id uuid.UUID) (*models.ProfileCompleteness, error) {
+	member, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	verifications, err := s.repo.GetVerifications(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return profileCompleteness(member, verifications), nil
+}
+
+// VerifyField marks a filled, verifiable profile field as verified
+func (s *MemberService) VerifyField(ctx context.Context, id uuid.UUID, req *models.VerifyFieldRequest, verifiedBy string) (*models.ProfileCompleteness, error) {
+	field, ok := models.FindProfileField(req.Field)
+	if !ok || !field.Verifiable {
+		return nil, &MemberError{Message: "Field " + req.Field + " cannot be verified"}
+	}
+
+	member, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !profileFieldFilled(member, field.Name) {
+		return nil, &MemberError{Message: "Field " + req.Field + " is empty"}
+	}
+
+	verifiedByUUID, _ := uuid.Parse(verifiedBy)
+	err = s.repo.VerifyField(ctx, &models.FieldVerification{
+		MemberID:   id,
+		Field:      field.Name,
+		VerifiedBy: &verifiedByUUID,
+		Note:       req.Note,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetCompleteness(ctx, id)
+}
+
+// UnverifyField withdraws a field verification
+func (s *MemberService) UnverifyField(ctx context.Context, id uuid.UUID, field string) (*models.ProfileCompleteness, error) {
+	if err := s.repo.UnverifyField(ctx, id, field); err != nil {
+		return nil, err
+	}
+	return s.GetCompleteness(ctx, id)
+}
+
+// IncompleteProfiles reports members with incomplete profiles, lowest score
+// first, with a summary per organization
+func (s *MemberService) IncompleteProfiles(ctx context.Context, params *models.IncompleteProfileParams) (*models.IncompleteProfileReport, error) {
+	return s.repo.IncompleteProfiles(ctx, params)
+}
+
+// profileCompleteness scores member against models.ProfileFields
+func profileCompleteness(member *models.Member, verifications []models.FieldVerification) *models.ProfileCompleteness {
+	verified := make(map[string]*models.FieldVerification, len(verifications))
+	for i := range verifications {
+		verified[verifications[i].Field] = &verifications[i]
+	}
+
+	result := &models.ProfileCompleteness{
+		MemberID: member.ID,
+		Missing:  []string{},
+		Fields:   make([]models.ProfileFieldStatus, 0, len(models.ProfileFields)),
+	}
+	for _, f := range models.ProfileFields {
+		status := models.ProfileFieldStatus{
+			Field:      f.Name,
+			Weight:     f.Weight,
+			Filled:     profileFieldFilled(member, f.Name),
+			Verifiable: f.Verifiable,
+		}
+		if status.Filled {
+			result.Score += f.Weight
+		} else {
+			result.Missing = append(result.Missing, f.Name)
+		}
+		if v, ok := verified[f.Name]; ok && f.Verifiable && status.Filled {
+			status.Verified = true
+			status.Verification = v
+		}
+		result.Fields = append(result.Fields, status)
+	}
+
+	return result
+}
+
+func profileFieldFilled(member *models.Member, name string) bool {
+	f, ok := findMemberField(name)
+	if !ok {
+		return false
+	}
+	v := f.get(member)
+	return v != nil && *v != ""
+}
@@ -0,0 +1,73 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sdyn/backend/internal/models"
+)
+
+func TestProfileFieldsAreConsistent(t *testing.T) {
+	total := 0
+	for _, f := range models.ProfileFields {
+		total += f.Weight
+		_, ok := findMemberField(f.Name)
+		assert.True(t, ok, "profile field %s has no member field", f.Name)
+	}
+	assert.Equal(t, 100, total)
+}
+
+func TestProfileCompleteness(t *testing.T) {
+	nationalID := "УБ99112233"
+	phone := "99001122"
+	empty := ""
+	birthDate := time.Date(2000, 5, 1, 0, 0, 0, 0, time.UTC)
+	districtID := uuid.New()
+
+	member := &models.Member{
+		ID:         uuid.New(),
+		NationalID: &nationalID,
+		Phone:      &phone,
+		Email:      &empty,
+		BirthDate:  &birthDate,
+		DistrictID: &districtID,
+	}
+	verifications := []models.FieldVerification{
+		{Field: "national_id", VerifiedAt: time.Now()},
+		// Stale verification of a field that is now empty
+		{Field: "email", VerifiedAt: time.Now()},
+	}
+
+	result := profileCompleteness(member, verifications)
+
+	assert.Equal(t, 15+15+10+10, result.Score)
+	assert.Contains(t, result.Missing, "email")
+	assert.Contains(t, result.Missing, "province_id")
+	assert.NotContains(t, result.Missing, "phone")
+
+	byField := map[string]models.ProfileFieldStatus{}
+	for _, f := range result.Fields {
+		byField[f.Field] = f
+	}
+	require.Len(t, byField, len(models.ProfileFields))
+	assert.True(t, byField["national_id"].Verified)
+	assert.NotNil(t, byField["national_id"].Verification)
+	assert.False(t, byField["phone"].Verified)
+	assert.False(t, byField["email"].Verified)
+	assert.False(t, byField["district_id"].Verifiable)
+}
+
+func TestVerifyFieldRejectsUnverifiableField(t *testing.T) {
+	s := &MemberService{}
+
+	for _, field := range []string{"district_id", "password"} {
+		_, err := s.VerifyField(context.Background(), uuid.New(), &models.VerifyFieldRequest{Field: field}, "")
+		var memberErr *MemberError
+		assert.ErrorAs(t, err, &memberErr, field)
+	}
+}
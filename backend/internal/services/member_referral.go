@@ -0,0 +1,87 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/sdyn/backend/internal/models"
+)
+
+const (
+	defaultReferralDepth = 3
+	maxReferralDepth     = 5
+)
+
+// GetReferrals returns the referral tree below the member, depth levels deep
+func (s *MemberService) GetReferrals(ctx context.Context, id uuid.UUID, depth int) (*models.ReferralTree, error) {
+	if depth == 0 {
+		depth = defaultReferralDepth
+	}
+	if depth < 1 || depth > maxReferralDepth {
+		return nil, &MemberError{Message: fmt.Sprintf("depth must be between 1 and %d", maxReferralDepth)}
+	}
+
+	// Make sure the member exists so an unknown ID is a 404, not an empty tree
+	if _, err := s.repo.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	nodes, err := s.repo.GetReferrals(ctx, id, depth)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildReferralTree(id, depth, nodes), nil
+}
+
+// ReferralLeaderboard ranks referrers by activated referrals in the period
+func (s *MemberService) ReferralLeaderboard(ctx context.Context, params *models.ReferralLeaderboardParams) (*models.ReferralLeaderboard, error) {
+	if params.Limit <= 0 || params.Limit > 100 {
+		params.Limit = 20
+	}
+
+	leaders, err := s.repo.GetReferralLeaderboard(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.ReferralLeaderboard{From: params.From, To: params.To, Leaders: leaders}, nil
+}
+
+// buildReferralTree nests the flat, level-ordered nodes under their
+// referrers and fills in the activated referral counts
+func buildReferralTree(rootID uuid.UUID, depth int, nodes []models.ReferralNode) *models.ReferralTree {
+	children := make(map[uuid.UUID][]models.ReferralNode)
+	for _, n := range nodes {
+		children[n.ReferredBy] = append(children[n.ReferredBy], n)
+	}
+
+	var attach func(parent uuid.UUID) ([]models.ReferralNode, int, int)
+	attach = func(parent uuid.UUID) ([]models.ReferralNode, int, int) {
+		list := children[parent]
+		delete(children, parent) // guards against cycles
+		direct, total := 0, 0
+		for i := range list {
+			node := &list[i]
+			node.Referrals, node.ActiveReferrals, node.TotalActiveReferrals = attach(node.ID)
+			if node.Referrals == nil {
+				node.Referrals = []models.ReferralNode{}
+			}
+			if node.Activated {
+				direct++
+				total++
+			}
+			total += node.TotalActiveReferrals
+		}
+		return list, direct, total
+	}
+
+	tree := &models.ReferralTree{MemberID: rootID, Depth: depth}
+	tree.Referrals, tree.ActiveReferrals, tree.TotalActiveReferrals = attach(rootID)
+	if tree.Referrals == nil {
+		tree.Referrals = []models.ReferralNode{}
+	}
+	return tree
+}
@@ -0,0 +1,55 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sdyn/backend/internal/models"
+)
+
+func TestBuildReferralTree(t *testing.T) {
+	root := uuid.New()
+	a, b, c, d := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+
+	// Level-ordered, as returned by the repository
+	nodes := []models.ReferralNode{
+		{ID: a, ReferredBy: root, Level: 1, Activated: true},
+		{ID: b, ReferredBy: root, Level: 1, Activated: false},
+		{ID: c, ReferredBy: a, Level: 2, Activated: true},
+		{ID: d, ReferredBy: b, Level: 2, Activated: true},
+	}
+
+	tree := buildReferralTree(root, 3, nodes)
+
+	assert.Equal(t, root, tree.MemberID)
+	assert.Equal(t, 1, tree.ActiveReferrals, "pending referral b is not counted")
+	assert.Equal(t, 3, tree.TotalActiveReferrals)
+	require.Len(t, tree.Referrals, 2)
+
+	nodeA := tree.Referrals[0]
+	assert.Equal(t, a, nodeA.ID)
+	assert.Equal(t, 1, nodeA.ActiveReferrals)
+	require.Len(t, nodeA.Referrals, 1)
+	assert.Equal(t, c, nodeA.Referrals[0].ID)
+	assert.NotNil(t, nodeA.Referrals[0].Referrals)
+
+	nodeB := tree.Referrals[1]
+	assert.Equal(t, 1, nodeB.ActiveReferrals, "b's own referrals count even though b never activated")
+}
+
+func TestBuildReferralTreeEmpty(t *testing.T) {
+	tree := buildReferralTree(uuid.New(), 3, []models.ReferralNode{})
+	assert.NotNil(t, tree.Referrals)
+	assert.Zero(t, tree.TotalActiveReferrals)
+}
+
+func TestGetReferralsRejectsDepth(t *testing.T) {
+	s := &MemberService{}
+	_, err := s.GetReferrals(context.Background(), uuid.New(), maxReferralDepth+1)
+	var memberErr *MemberError
+	assert.ErrorAs(t, err, &memberErr)
+}
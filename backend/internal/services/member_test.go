@@ -249,3 +249,63 @@ func TestMemberService_GetReport(t *testing.T) {
 	assert.Equal(t, 85, report["active_members"])
 	mockRepo.AssertExpectations(t)
 }
+
+func TestMemberStatusTransitions(t *testing.T) {
+	assert.True(t, models.MemberStatusPending.CanTransitionTo(models.MemberStatusActive))
+	assert.True(t, models.MemberStatusActive.CanTransitionTo(models.MemberStatusSuspended))
+	assert.True(t, models.MemberStatusSuspended.CanTransitionTo(models.MemberStatusActive))
+	assert.False(t, models.MemberStatusActive.CanTransitionTo(models.MemberStatusActive))
+	assert.False(t, models.MemberStatusActive.CanTransitionTo(models.MemberStatusPending))
+	assert.False(t, models.MemberStatusPending.CanTransitionTo(models.MemberStatusSuspended))
+
+	for _, status := range []models.MemberStatus{
+		models.MemberStatusPending, models.MemberStatusActive, models.MemberStatusInactive, models.MemberStatusSuspended,
+	} {
+		assert.False(t, models.MemberStatusExpelled.CanTransitionTo(status), "expelled -> %s", status)
+	}
+}
+
+func TestValidateStatusChange(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	future := now.Add(30 * 24 * time.Hour)
+	past := now.Add(-time.Hour)
+	reason := "Дүрэм зөрчсөн"
+	blank := " "
+
+	tests := []struct {
+		name    string
+		from    models.MemberStatus
+		req     models.UpdateStatusRequest
+		wantErr interface{}
+	}{
+		{"activate pending", models.MemberStatusPending, models.UpdateStatusRequest{Status: models.MemberStatusActive}, nil},
+		{"reactivate expelled", models.MemberStatusExpelled, models.UpdateStatusRequest{Status: models.MemberStatusActive}, &StatusTransitionError{}},
+		{"suspend without reason", models.MemberStatusActive, models.UpdateStatusRequest{Status: models.MemberStatusSuspended, Reason: &blank}, &MemberError{}},
+		{"expel without reason", models.MemberStatusActive, models.UpdateStatusRequest{Status: models.MemberStatusExpelled}, &MemberError{}},
+		{"suspend indefinitely", models.MemberStatusActive, models.UpdateStatusRequest{Status: models.MemberStatusSuspended, Reason: &reason}, nil},
+		{"suspend until date", models.MemberStatusActive, models.UpdateStatusRequest{Status: models.MemberStatusSuspended, Reason: &reason, SuspendedUntil: &future}, nil},
+		{"suspend until past date", models.MemberStatusActive, models.UpdateStatusRequest{Status: models.MemberStatusSuspended, Reason: &reason, SuspendedUntil: &past}, &MemberError{}},
+		{"end date without suspension", models.MemberStatusActive, models.UpdateStatusRequest{Status: models.MemberStatusInactive, SuspendedUntil: &future}, &MemberError{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateStatusChange(tt.from, &tt.req, now)
+			if tt.wantErr == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.IsType(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestMemberService_ListExpiringDays(t *testing.T) {
+	s := NewMemberService(nil, nil)
+
+	for _, days := range []int{0, -1, maxExpiringDays + 1} {
+		_, err := s.ListExpiring(context.Background(), &models.MemberListParams{}, days)
+		var memberErr *MemberError
+		assert.ErrorAs(t, err, &memberErr, "days=%d", days)
+	}
+}
@@ -0,0 +1,29 @@
+package services
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/sdyn/backend/internal/models"
+	"github.com/sdyn/backend/internal/repository"
+)
+
+// notificationListLimit caps the notifications returned at once
+const notificationListLimit = 100
+
+type NotificationService struct {
+	repo *repository.NotificationRepository
+}
+
+func NewNotificationService(repo *repository.NotificationRepository) *NotificationService {
+	return &NotificationService{repo: repo}
+}
+
+func (s *NotificationService) List(ctx context.Context, memberID uuid.UUID, unreadOnly bool) ([]models.Notification, error) {
+	return s.repo.ListByMember(ctx, memberID, unreadOnly, notificationListLimit)
+}
+
+func (s *NotificationService) MarkRead(ctx context.Context, id, memberID uuid.UUID) error {
+	return s.repo.MarkRead(ctx, id, memberID)
+}
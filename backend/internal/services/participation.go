@@ -0,0 +1,106 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/sdyn/backend/internal/config"
+	"github.com/sdyn/backend/internal/models"
+	"github.com/sdyn/backend/internal/repository"
+)
+
+// maxParticipantHours caps the hours credited for one event, including
+// those computed at check-out
+const maxParticipantHours = 500
+
+type ParticipationService struct {
+	repo           *repository.EventRepository
+	memberRepo     *repository.MemberRepository
+	pointsPerEvent int
+	pointsPerHour  int
+}
+
+func NewParticipationService(repo *repository.EventRepository, memberRepo *repository.MemberRepository, cfg *config.Config) *ParticipationService {
+	return &ParticipationService{
+		repo:           repo,
+		memberRepo:     memberRepo,
+		pointsPerEvent: cfg.ParticipationPointsPerEvent,
+		pointsPerHour:  cfg.ParticipationPointsPerHour,
+	}
+}
+
+// SetHours credits the hours an organizer entered to an attended
+// participant of a volunteer or campaign event. They replace any hours
+// computed at check-out.
+func (s *ParticipationService) SetHours(ctx context.Context, eventID, memberID uuid.UUID, req *models.SetParticipantHoursRequest, enteredBy string) (*models.EventParticipant, error) {
+	event, err := s.repo.GetByID(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+	enteredByID, err := checkHoursEntry(event, enteredBy)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.SetHours(ctx, eventID, memberID, req.Hours, enteredByID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotAttended
+		}
+		return nil, err
+	}
+	return s.repo.GetParticipant(ctx, eventID, memberID)
+}
+
+// checkHoursEntry checks that the event credits hours and returns the
+// organizer entering them
+func checkHoursEntry(event *models.Event, enteredBy string) (uuid.UUID, error) {
+	if !event.Type.CreditsHours() {
+		return uuid.Nil, &EventError{Message: "Hours are only credited for volunteer and campaign events"}
+	}
+	enteredByID, err := uuid.Parse(enteredBy)
+	if err != nil {
+		return uuid.Nil, &EventError{Message: "Invalid user ID"}
+	}
+	return enteredByID, nil
+}
+
+// checkoutHours returns the hours from check-in to check-out, rounded to
+// two decimals and capped at maxParticipantHours
+func checkoutHours(attendedAt, checkedOutAt time.Time) float64 {
+	hours := math.Round(checkedOutAt.Sub(attendedAt).Hours()*100) / 100
+	return math.Min(math.Max(hours, 0), maxParticipantHours)
+}
+
+// MemberSummary returns the member's cumulative participation
+func (s *ParticipationService) MemberSummary(ctx context.Context, memberID uuid.UUID) (*models.ParticipationSummary, error) {
+	// Make sure the member exists so an unknown ID is a 404, not zeros
+	if _, err := s.memberRepo.GetByID(ctx, memberID); err != nil {
+		return nil, err
+	}
+	return s.repo.ParticipationSummary(ctx, memberID, s.pointsPerEvent, s.pointsPerHour)
+}
+
+// Leaderboard ranks members by participation points in the period
+func (s *ParticipationService) Leaderboard(ctx context.Context, params *models.ParticipationLeaderboardParams) (*models.ParticipationLeaderboard, error) {
+	if params.Limit <= 0 || params.Limit > 100 {
+		params.Limit = 20
+	}
+
+	leaders, err := s.repo.GetParticipationLeaderboard(ctx, params, s.pointsPerEvent, s.pointsPerHour)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.ParticipationLeaderboard{
+		From:           params.From,
+		To:             params.To,
+		Leaders:        leaders,
+		PointsPerEvent: s.pointsPerEvent,
+		PointsPerHour:  s.pointsPerHour,
+	}, nil
+}
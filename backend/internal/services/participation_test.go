@@ -0,0 +1,57 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sdyn/backend/internal/models"
+)
+
+func TestCheckoutHours(t *testing.T) {
+	checkedIn := time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		checkedOut time.Time
+		want       float64
+	}{
+		{"whole hours", checkedIn.Add(3 * time.Hour), 3},
+		{"rounded to two decimals", checkedIn.Add(100 * time.Minute), 1.67},
+		{"same instant", checkedIn, 0},
+		{"clock skew", checkedIn.Add(-time.Minute), 0},
+		{"at the cap", checkedIn.Add(maxParticipantHours * time.Hour), maxParticipantHours},
+		{"capped", checkedIn.Add(30 * 24 * time.Hour), maxParticipantHours},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, checkoutHours(checkedIn, tt.checkedOut))
+		})
+	}
+}
+
+func TestCheckHoursEntry(t *testing.T) {
+	organizer := uuid.New()
+
+	for _, eventType := range []models.EventType{models.EventTypeVolunteer, models.EventTypeCampaign} {
+		id, err := checkHoursEntry(&models.Event{Type: eventType}, organizer.String())
+		assert.NoError(t, err, eventType)
+		assert.Equal(t, organizer, id)
+	}
+
+	for _, eventType := range []models.EventType{
+		models.EventTypeMeeting, models.EventTypeTraining, models.EventTypeCultural,
+		models.EventTypeSports, models.EventTypeOther,
+	} {
+		_, err := checkHoursEntry(&models.Event{Type: eventType}, organizer.String())
+		var eventErr *EventError
+		assert.ErrorAs(t, err, &eventErr, eventType)
+	}
+
+	_, err := checkHoursEntry(&models.Event{Type: models.EventTypeVolunteer}, "not-a-uuid")
+	var eventErr *EventError
+	assert.ErrorAs(t, err, &eventErr)
+}
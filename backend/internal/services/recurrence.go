@@ -0,0 +1,289 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Recurrence rules are a subset of RFC 5545 RRULE: FREQ=WEEKLY or MONTHLY,
+// INTERVAL, BYDAY (with an ordinal such as 2TU or -1FR for monthly rules),
+// BYMONTHDAY, COUNT and UNTIL. Occurrences keep the local time of day of
+// the series start in the series time zone.
+
+const (
+	recurrenceWeekly  = "WEEKLY"
+	recurrenceMonthly = "MONTHLY"
+
+	maxRecurrenceInterval = 99
+	maxRecurrenceCount    = 1000
+)
+
+var rruleWeekdays = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+// rruleWeekday is a BYDAY entry; n is the ordinal within the month (2 for
+// the second, -1 for the last) or 0 for every such weekday
+type rruleWeekday struct {
+	n   int
+	day time.Weekday
+}
+
+type recurrence struct {
+	freq       string
+	interval   int
+	byDay      []rruleWeekday
+	byMonthDay []int
+	count      int
+	until      *time.Time
+}
+
+func parseRecurrence(rule string) (*recurrence, error) {
+	r := &recurrence{interval: 1}
+	rule = strings.TrimPrefix(strings.TrimSpace(rule), "RRULE:")
+	if rule == "" {
+		return nil, fmt.Errorf("recurrence is empty")
+	}
+
+	seen := map[string]bool{}
+	for _, part := range strings.Split(rule, ";") {
+		name, value, ok := strings.Cut(part, "=")
+		name = strings.ToUpper(strings.TrimSpace(name))
+		value = strings.ToUpper(strings.TrimSpace(value))
+		if !ok || value == "" {
+			return nil, fmt.Errorf("invalid recurrence part %q", part)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("%s is given twice", name)
+		}
+		seen[name] = true
+
+		switch name {
+		case "FREQ":
+			if value != recurrenceWeekly && value != recurrenceMonthly {
+				return nil, fmt.Errorf("FREQ must be WEEKLY or MONTHLY")
+			}
+			r.freq = value
+		case "INTERVAL":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 || n > maxRecurrenceInterval {
+				return nil, fmt.Errorf("INTERVAL must be between 1 and %d", maxRecurrenceInterval)
+			}
+			r.interval = n
+		case "BYDAY":
+			for _, item := range strings.Split(value, ",") {
+				day, err := parseRRuleWeekday(item)
+				if err != nil {
+					return nil, err
+				}
+				r.byDay = append(r.byDay, day)
+			}
+		case "BYMONTHDAY":
+			for _, item := range strings.Split(value, ",") {
+				n, err := strconv.Atoi(item)
+				if err != nil || n == 0 || n < -31 || n > 31 {
+					return nil, fmt.Errorf("invalid BYMONTHDAY %q", item)
+				}
+				r.byMonthDay = append(r.byMonthDay, n)
+			}
+		case "COUNT":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 || n > maxRecurrenceCount {
+				return nil, fmt.Errorf("COUNT must be between 1 and %d", maxRecurrenceCount)
+			}
+			r.count = n
+		case "UNTIL":
+			until, err := parseRRuleUntil(value)
+			if err != nil {
+				return nil, err
+			}
+			r.until = &until
+		case "WKST":
+			if value != "MO" {
+				return nil, fmt.Errorf("only WKST=MO is supported")
+			}
+		default:
+			return nil, fmt.Errorf("%s is not supported", name)
+		}
+	}
+
+	if r.freq == "" {
+		return nil, fmt.Errorf("FREQ is required")
+	}
+	if r.count > 0 && r.until != nil {
+		return nil, fmt.Errorf("COUNT and UNTIL cannot be combined")
+	}
+	if r.freq == recurrenceWeekly {
+		if len(r.byMonthDay) > 0 {
+			return nil, fmt.Errorf("BYMONTHDAY requires FREQ=MONTHLY")
+		}
+		for _, d := range r.byDay {
+			if d.n != 0 {
+				return nil, fmt.Errorf("BYDAY ordinals require FREQ=MONTHLY")
+			}
+		}
+	}
+	if len(r.byDay) > 0 && len(r.byMonthDay) > 0 {
+		return nil, fmt.Errorf("BYDAY and BYMONTHDAY cannot be combined")
+	}
+
+	return r, nil
+}
+
+func parseRRuleWeekday(s string) (rruleWeekday, error) {
+	if len(s) < 2 {
+		return rruleWeekday{}, fmt.Errorf("invalid BYDAY %q", s)
+	}
+	day, ok := rruleWeekdays[s[len(s)-2:]]
+	if !ok {
+		return rruleWeekday{}, fmt.Errorf("invalid BYDAY %q", s)
+	}
+	w := rruleWeekday{day: day}
+	if ordinal := s[:len(s)-2]; ordinal != "" {
+		n, err := strconv.Atoi(ordinal)
+		if err != nil || n == 0 || n < -5 || n > 5 {
+			return rruleWeekday{}, fmt.Errorf("invalid BYDAY %q", s)
+		}
+		w.n = n
+	}
+	return w, nil
+}
+
+func parseRRuleUntil(s string) (time.Time, error) {
+	for _, layout := range []string{"20060102T150405Z", "20060102"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			if layout == "20060102" {
+				// A date includes the whole day
+				t = t.Add(24*time.Hour - time.Second)
+			}
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid UNTIL %q", s)
+}
+
+// between returns the occurrences of a series starting at start that fall
+// after after and no later than before, in start's time zone. COUNT counts
+// from the series start, so the rule is walked from there.
+func (r *recurrence) between(start, after, before time.Time) []time.Time {
+	var result []time.Time
+	n := 0
+	for period := 0; ; period += r.interval {
+		candidates, periodStart := r.period(start, period)
+		if periodStart.After(before) || (r.until != nil && periodStart.After(*r.until)) {
+			return result
+		}
+		for _, t := range candidates {
+			if t.Before(start) {
+				continue
+			}
+			if t.After(before) || (r.until != nil && t.After(*r.until)) {
+				return result
+			}
+			n++
+			if t.After(after) {
+				result = append(result, t)
+			}
+			if r.count > 0 && n >= r.count {
+				return result
+			}
+		}
+	}
+}
+
+// period returns the candidate occurrences of the week or month period
+// periods after the one containing start, in order, and the start of that
+// period
+func (r *recurrence) period(start time.Time, period int) ([]time.Time, time.Time) {
+	loc := start.Location()
+	hour, min, sec := start.Clock()
+	at := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, hour, min, sec, 0, loc)
+	}
+
+	if r.freq == recurrenceWeekly {
+		// Weeks start on Monday
+		offset := (int(start.Weekday()) + 6) % 7
+		monday := time.Date(start.Year(), start.Month(), start.Day()-offset+7*period, 0, 0, 0, 0, loc)
+
+		days := r.byDay
+		if len(days) == 0 {
+			days = []rruleWeekday{{day: start.Weekday()}}
+		}
+		var times []time.Time
+		for _, d := range days {
+			times = append(times, at(monday.Year(), monday.Month(), monday.Day()+(int(d.day)+6)%7))
+		}
+		return sortedUnique(times), monday
+	}
+
+	first := time.Date(start.Year(), start.Month()+time.Month(period), 1, 0, 0, 0, 0, loc)
+	year, month := first.Year(), first.Month()
+	daysInMonth := time.Date(year, month+1, 0, 0, 0, 0, 0, loc).Day()
+
+	var times []time.Time
+	switch {
+	case len(r.byDay) > 0:
+		for _, d := range r.byDay {
+			days := monthWeekdays(year, month, d.day, daysInMonth)
+			if d.n == 0 {
+				for _, day := range days {
+					times = append(times, at(year, month, day))
+				}
+				continue
+			}
+			i := d.n - 1
+			if d.n < 0 {
+				i = len(days) + d.n
+			}
+			if i >= 0 && i < len(days) {
+				times = append(times, at(year, month, days[i]))
+			}
+		}
+	case len(r.byMonthDay) > 0:
+		for _, day := range r.byMonthDay {
+			if day < 0 {
+				day = daysInMonth + day + 1
+			}
+			if day >= 1 && day <= daysInMonth {
+				times = append(times, at(year, month, day))
+			}
+		}
+	default:
+		// Months without the start's day are skipped
+		if start.Day() <= daysInMonth {
+			times = append(times, at(year, month, start.Day()))
+		}
+	}
+	return sortedUnique(times), first
+}
+
+// monthWeekdays returns the days of the month falling on weekday
+func monthWeekdays(year int, month time.Month, weekday time.Weekday, daysInMonth int) []int {
+	firstWeekday := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC).Weekday()
+	var days []int
+	for day := 1 + (int(weekday)-int(firstWeekday)+7)%7; day <= daysInMonth; day += 7 {
+		days = append(days, day)
+	}
+	return days
+}
+
+func sortedUnique(times []time.Time) []time.Time {
+	sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+	result := times[:0]
+	for i, t := range times {
+		if i == 0 || !t.Equal(times[i-1]) {
+			result = append(result, t)
+		}
+	}
+	return result
+}
@@ -0,0 +1,124 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var ulaanbaatar = time.FixedZone("ULAT", 8*60*60)
+
+func occurrenceDates(t *testing.T, rule string, start time.Time, after, before time.Time) []string {
+	t.Helper()
+	r, err := parseRecurrence(rule)
+	require.NoError(t, err)
+
+	var dates []string
+	for _, o := range r.between(start, after, before) {
+		dates = append(dates, o.Format("2006-01-02 15:04 Mon"))
+	}
+	return dates
+}
+
+func TestRecurrenceWeekly(t *testing.T) {
+	// Tuesday 18:30
+	start := time.Date(2026, 3, 3, 18, 30, 0, 0, ulaanbaatar)
+
+	dates := occurrenceDates(t, "FREQ=WEEKLY;BYDAY=TU,TH;COUNT=5", start, start.Add(-time.Second), start.AddDate(1, 0, 0))
+	assert.Equal(t, []string{
+		"2026-03-03 18:30 Tue",
+		"2026-03-05 18:30 Thu",
+		"2026-03-10 18:30 Tue",
+		"2026-03-12 18:30 Thu",
+		"2026-03-17 18:30 Tue",
+	}, dates)
+
+	dates = occurrenceDates(t, "FREQ=WEEKLY;INTERVAL=2", start, start.Add(-time.Second), start.AddDate(0, 0, 35))
+	assert.Equal(t, []string{
+		"2026-03-03 18:30 Tue",
+		"2026-03-17 18:30 Tue",
+		"2026-03-31 18:30 Tue",
+	}, dates)
+}
+
+func TestRecurrenceWeeklySkipsDaysBeforeStart(t *testing.T) {
+	// Wednesday start with a Monday/Wednesday rule
+	start := time.Date(2026, 3, 4, 9, 0, 0, 0, ulaanbaatar)
+
+	dates := occurrenceDates(t, "FREQ=WEEKLY;BYDAY=MO,WE;COUNT=3", start, start.Add(-time.Second), start.AddDate(1, 0, 0))
+	assert.Equal(t, []string{
+		"2026-03-04 09:00 Wed",
+		"2026-03-09 09:00 Mon",
+		"2026-03-11 09:00 Wed",
+	}, dates)
+}
+
+func TestRecurrenceMonthlyNthWeekday(t *testing.T) {
+	start := time.Date(2026, 1, 13, 19, 0, 0, 0, ulaanbaatar)
+
+	// Second Tuesday
+	dates := occurrenceDates(t, "FREQ=MONTHLY;BYDAY=2TU;COUNT=3", start, start.Add(-time.Second), start.AddDate(1, 0, 0))
+	assert.Equal(t, []string{
+		"2026-01-13 19:00 Tue",
+		"2026-02-10 19:00 Tue",
+		"2026-03-10 19:00 Tue",
+	}, dates)
+
+	// Last Friday
+	dates = occurrenceDates(t, "FREQ=MONTHLY;BYDAY=-1FR;UNTIL=20260331", start, start.Add(-time.Second), start.AddDate(1, 0, 0))
+	assert.Equal(t, []string{
+		"2026-01-30 19:00 Fri",
+		"2026-02-27 19:00 Fri",
+		"2026-03-27 19:00 Fri",
+	}, dates)
+}
+
+func TestRecurrenceMonthlyDay(t *testing.T) {
+	start := time.Date(2026, 1, 31, 10, 0, 0, 0, ulaanbaatar)
+
+	// Months without a 31st are skipped
+	dates := occurrenceDates(t, "FREQ=MONTHLY", start, start.Add(-time.Second), time.Date(2026, 6, 1, 0, 0, 0, 0, ulaanbaatar))
+	assert.Equal(t, []string{
+		"2026-01-31 10:00 Sat",
+		"2026-03-31 10:00 Tue",
+		"2026-05-31 10:00 Sun",
+	}, dates)
+
+	// The last day of every month
+	dates = occurrenceDates(t, "FREQ=MONTHLY;BYMONTHDAY=-1;COUNT=3", start, start.Add(-time.Second), start.AddDate(1, 0, 0))
+	assert.Equal(t, []string{
+		"2026-01-31 10:00 Sat",
+		"2026-02-28 10:00 Sat",
+		"2026-03-31 10:00 Tue",
+	}, dates)
+}
+
+func TestRecurrenceBetweenKeepsCount(t *testing.T) {
+	start := time.Date(2026, 3, 2, 18, 0, 0, 0, ulaanbaatar)
+	after := time.Date(2026, 3, 20, 0, 0, 0, 0, ulaanbaatar)
+
+	// The first three of the four occurrences are before after
+	dates := occurrenceDates(t, "FREQ=WEEKLY;COUNT=4", start, after, start.AddDate(1, 0, 0))
+	assert.Equal(t, []string{"2026-03-23 18:00 Mon"}, dates)
+}
+
+func TestParseRecurrenceRejectsInvalidRules(t *testing.T) {
+	for _, rule := range []string{
+		"",
+		"FREQ=DAILY",
+		"BYDAY=MO",
+		"FREQ=WEEKLY;BYDAY=2MO",
+		"FREQ=WEEKLY;BYMONTHDAY=1",
+		"FREQ=MONTHLY;BYDAY=MO;BYMONTHDAY=1",
+		"FREQ=MONTHLY;COUNT=3;UNTIL=20261231",
+		"FREQ=WEEKLY;INTERVAL=0",
+		"FREQ=WEEKLY;BYDAY=XX",
+		"FREQ=WEEKLY;FREQ=MONTHLY",
+		"FREQ=WEEKLY;BYHOUR=9",
+	} {
+		_, err := parseRecurrence(rule)
+		assert.Error(t, err, rule)
+	}
+}
@@ -0,0 +1,262 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/sdyn/backend/internal/models"
+	"github.com/sdyn/backend/internal/repository"
+)
+
+// ErrTransferClosed is returned when deciding or cancelling a transfer that
+// is no longer pending
+var ErrTransferClosed = errors.New("transfer is no longer pending")
+
+// ErrTransferExists is returned when the member already has a pending
+// transfer
+var ErrTransferExists = errors.New("member already has a pending transfer")
+
+type TransferService struct {
+	repo       *repository.TransferRepository
+	memberRepo *repository.MemberRepository
+	orgRepo    *repository.OrganizationRepository
+}
+
+func NewTransferService(repo *repository.TransferRepository, memberRepo *repository.MemberRepository, orgRepo *repository.OrganizationRepository) *TransferService {
+	return &TransferService{repo: repo, memberRepo: memberRepo, orgRepo: orgRepo}
+}
+
+// List returns an organization's transfers, oldest request first
+func (s *TransferService) List(ctx context.Context, params *models.TransferListParams) (*models.TransferListResponse, error) {
+	return s.repo.List(ctx, params)
+}
+
+// Create requests a transfer of the member to another organization. Only the
+// member or an admin of the member's current organization may request it.
+func (s *TransferService) Create(ctx context.Context, memberID uuid.UUID, req *models.CreateTransferRequest, filter *DataScopeFilter, requestedBy string) (*models.MemberTransfer, error) {
+	toOrgID, err := uuid.Parse(req.ToOrganizationID)
+	if err != nil {
+		return nil, &TransferError{Message: "Invalid organization ID"}
+	}
+
+	member, err := s.memberRepo.GetByID(ctx, memberID)
+	if err != nil {
+		return nil, err
+	}
+	if member.Status == models.MemberStatusExpelled {
+		return nil, &TransferError{Message: "Expelled members cannot be transferred"}
+	}
+
+	ok, err := s.canInitiate(ctx, member, filter)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, &TransferError{Message: "Only the member or an admin of their organization can request a transfer", Forbidden: true}
+	}
+
+	toOrg, err := s.orgRepo.GetByID(ctx, toOrgID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, &TransferError{Message: "Receiving organization not found"}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !toOrg.IsActive {
+		return nil, &TransferError{Message: "Receiving organization is not active"}
+	}
+	if member.OrganizationID != nil && *member.OrganizationID == toOrg.ID {
+		return nil, &TransferError{Message: "Member already belongs to this organization"}
+	}
+
+	requestedByUUID, _ := uuid.Parse(requestedBy)
+	transfer, err := s.repo.Create(ctx, &models.MemberTransfer{
+		MemberID:           member.ID,
+		FromOrganizationID: member.OrganizationID,
+		ToOrganizationID:   toOrg.ID,
+		Reason:             req.Reason,
+		RequestedBy:        &requestedByUUID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrTransferExists
+	}
+	return transfer, err
+}
+
+// Accept moves the member to the receiving organization. Only an admin of
+// the receiving organization may accept.
+func (s *TransferService) Accept(ctx context.Context, id uuid.UUID, req *models.TransferDecisionRequest, filter *DataScopeFilter, decidedBy string) (*models.MemberTransfer, error) {
+	transfer, toOrg, err := s.loadForDecision(ctx, id, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	member, err := s.memberRepo.GetByID(ctx, transfer.MemberID)
+	if err != nil {
+		return nil, err
+	}
+	if !equalUUIDPtr(member.OrganizationID, transfer.FromOrganizationID) {
+		return nil, &TransferError{Message: "Member has changed organization since the transfer was requested"}
+	}
+
+	after := *member
+	after.OrganizationID = &toOrg.ID
+	after.ProvinceID = toOrg.ProvinceID
+	after.DistrictID = toOrg.DistrictID
+
+	decidedByUUID, _ := uuid.Parse(decidedBy)
+	history := &models.MemberHistory{
+		Action:    "transferred",
+		OldValue:  transfer.FromOrganizationName,
+		NewValue:  &toOrg.Name,
+		ChangedBy: &decidedByUUID,
+		Reason:    transfer.Reason,
+		Changes:   diffMember(member, &after),
+	}
+
+	err = s.repo.Accept(ctx, id, &decidedByUUID, req.Reason, history)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// Decided, or the member moved, since it was loaded
+		return nil, ErrTransferClosed
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetByID(ctx, id)
+}
+
+// Decline rejects the transfer; the member stays where they are. Only an
+// admin of the receiving organization may decline.
+func (s *TransferService) Decline(ctx context.Context, id uuid.UUID, req *models.TransferDecisionRequest, filter *DataScopeFilter, decidedBy string) (*models.MemberTransfer, error) {
+	if _, _, err := s.loadForDecision(ctx, id, filter); err != nil {
+		return nil, err
+	}
+	return s.close(ctx, id, models.TransferStatusDeclined, req.Reason, decidedBy)
+}
+
+// Cancel withdraws a pending transfer. Anyone who could have requested it
+// may cancel it.
+func (s *TransferService) Cancel(ctx context.Context, id uuid.UUID, req *models.TransferDecisionRequest, filter *DataScopeFilter, decidedBy string) (*models.MemberTransfer, error) {
+	transfer, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if transfer.Status != models.TransferStatusPending {
+		return nil, ErrTransferClosed
+	}
+
+	member, err := s.memberRepo.GetByID(ctx, transfer.MemberID)
+	if err != nil {
+		return nil, err
+	}
+	ok, err := s.canInitiate(ctx, member, filter)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, &TransferError{Message: "You cannot cancel this transfer", Forbidden: true}
+	}
+
+	return s.close(ctx, id, models.TransferStatusCancelled, req.Reason, decidedBy)
+}
+
+func (s *TransferService) close(ctx context.Context, id uuid.UUID, status models.TransferStatus, reason *string, decidedBy string) (*models.MemberTransfer, error) {
+	decidedByUUID, _ := uuid.Parse(decidedBy)
+	err := s.repo.Close(ctx, id, status, &decidedByUUID, reason)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrTransferClosed
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(ctx, id)
+}
+
+// loadForDecision loads a pending transfer and its receiving organization
+// and checks that the caller administers that organization
+func (s *TransferService) loadForDecision(ctx context.Context, id uuid.UUID, filter *DataScopeFilter) (*models.MemberTransfer, *models.Organization, error) {
+	transfer, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	toOrg, err := s.orgRepo.GetByID(ctx, transfer.ToOrganizationID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !scopeCoversOrganization(filter, toOrg) {
+		return nil, nil, &TransferError{Message: "Only an admin of the receiving organization can decide this transfer", Forbidden: true}
+	}
+	if transfer.Status != models.TransferStatusPending {
+		return nil, nil, ErrTransferClosed
+	}
+
+	return transfer, toOrg, nil
+}
+
+// canInitiate reports whether the caller is the member or an admin of the
+// member's current organization. Members without an organization are
+// covered by the admins of their province or district.
+func (s *TransferService) canInitiate(ctx context.Context, member *models.Member, filter *DataScopeFilter) (bool, error) {
+	if filter == nil {
+		return false, nil
+	}
+	if filter.UserID != nil && *filter.UserID == member.ID {
+		return true, nil
+	}
+	if filter.Scope == models.ScopeOwn {
+		return false, nil
+	}
+
+	if member.OrganizationID == nil {
+		return scopeCoversOrganization(filter, &models.Organization{
+			ProvinceID: member.ProvinceID,
+			DistrictID: member.DistrictID,
+		}), nil
+	}
+
+	fromOrg, err := s.orgRepo.GetByID(ctx, *member.OrganizationID)
+	if err != nil {
+		return false, err
+	}
+	return scopeCoversOrganization(filter, fromOrg), nil
+}
+
+// scopeCoversOrganization reports whether the caller's data scope includes
+// the organization, matching the organization checks of CanAccessResource
+func scopeCoversOrganization(filter *DataScopeFilter, org *models.Organization) bool {
+	if filter == nil {
+		return false
+	}
+
+	switch filter.Scope {
+	case models.ScopeAll:
+		return true
+	case models.ScopeProvince:
+		return filter.ProvinceID != nil && org.ProvinceID != nil && *org.ProvinceID == *filter.ProvinceID
+	case models.ScopeDistrict:
+		if filter.DistrictID != nil {
+			return org.DistrictID != nil && *org.DistrictID == *filter.DistrictID
+		}
+		if filter.OrganizationID == nil || org.ID == uuid.Nil {
+			return false
+		}
+		return org.ID == *filter.OrganizationID ||
+			(org.ParentID != nil && *org.ParentID == *filter.OrganizationID)
+	}
+
+	return false
+}
+
+type TransferError struct {
+	Message   string
+	Forbidden bool
+}
+
+func (e *TransferError) Error() string {
+	return e.Message
+}
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sdyn/backend/internal/models"
+)
+
+func TestScopeCoversOrganization(t *testing.T) {
+	provinceID := uuid.New()
+	districtID := uuid.New()
+	parentID := uuid.New()
+	other := uuid.New()
+
+	org := &models.Organization{ID: uuid.New(), ParentID: &parentID, ProvinceID: &provinceID, DistrictID: &districtID}
+
+	tests := []struct {
+		name   string
+		filter *DataScopeFilter
+		want   bool
+	}{
+		{"national admin", &DataScopeFilter{Scope: models.ScopeAll}, true},
+		{"province admin of the province", &DataScopeFilter{Scope: models.ScopeProvince, ProvinceID: &provinceID}, true},
+		{"province admin of another province", &DataScopeFilter{Scope: models.ScopeProvince, ProvinceID: &other}, false},
+		{"district admin of the district", &DataScopeFilter{Scope: models.ScopeDistrict, DistrictID: &districtID}, true},
+		{"district admin of another district", &DataScopeFilter{Scope: models.ScopeDistrict, DistrictID: &other}, false},
+		{"admin of the organization", &DataScopeFilter{Scope: models.ScopeDistrict, OrganizationID: &org.ID}, true},
+		{"admin of the parent organization", &DataScopeFilter{Scope: models.ScopeDistrict, OrganizationID: &parentID}, true},
+		{"admin of another organization", &DataScopeFilter{Scope: models.ScopeDistrict, OrganizationID: &other}, false},
+		{"member", &DataScopeFilter{Scope: models.ScopeOwn, UserID: &other}, false},
+		{"no scope", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, scopeCoversOrganization(tt.filter, org))
+		})
+	}
+}
+
+func TestTransferCanInitiate(t *testing.T) {
+	s := NewTransferService(nil, nil, nil)
+	memberID := uuid.New()
+	provinceID := uuid.New()
+	other := uuid.New()
+
+	// Member without an organization, so no organization lookup is needed
+	member := &models.Member{ID: memberID, ProvinceID: &provinceID}
+
+	ok, err := s.canInitiate(context.Background(), member, &DataScopeFilter{Scope: models.ScopeOwn, UserID: &memberID})
+	require.NoError(t, err)
+	assert.True(t, ok, "the member themselves")
+
+	ok, err = s.canInitiate(context.Background(), member, &DataScopeFilter{Scope: models.ScopeOwn, UserID: &other})
+	require.NoError(t, err)
+	assert.False(t, ok, "another member")
+
+	ok, err = s.canInitiate(context.Background(), member, &DataScopeFilter{Scope: models.ScopeProvince, ProvinceID: &provinceID})
+	require.NoError(t, err)
+	assert.True(t, ok, "admin of the member's province")
+
+	ok, err = s.canInitiate(context.Background(), member, &DataScopeFilter{Scope: models.ScopeProvince, ProvinceID: &other})
+	require.NoError(t, err)
+	assert.False(t, ok, "admin of another province")
+}
+
+func TestCreateTransferRejectsInvalidOrganization(t *testing.T) {
+	s := NewTransferService(nil, nil, nil)
+
+	_, err := s.Create(context.Background(), uuid.New(), &models.CreateTransferRequest{ToOrganizationID: "nope"}, &DataScopeFilter{Scope: models.ScopeAll}, "")
+	var transferErr *TransferError
+	assert.ErrorAs(t, err, &transferErr)
+}
@@ -0,0 +1,27 @@
+-- Background export jobs (large CSV/XLSX exports built outside the request)
+CREATE TABLE export_jobs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    requested_by VARCHAR(255) NOT NULL,
+    type VARCHAR(50) NOT NULL,
+    format VARCHAR(10) NOT NULL,
+    params JSONB NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending', -- pending, processing, completed, failed
+    object_key TEXT,
+    file_name VARCHAR(255),
+    file_size BIGINT,
+    error_message TEXT,
+    attempts INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    started_at TIMESTAMP WITH TIME ZONE,
+    completed_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_export_jobs_requested_by ON export_jobs(requested_by, created_at DESC);
+
+-- Partial index for the worker queue
+CREATE INDEX idx_export_jobs_pending ON export_jobs(created_at) WHERE status = 'pending';
+
+-- Comments
+COMMENT ON TABLE export_jobs IS 'Stores asynchronous export jobs; finished files live in MinIO';
+COMMENT ON COLUMN export_jobs.params IS 'JSON export params, including the data scope of the requester';
+COMMENT ON COLUMN export_jobs.object_key IS 'MinIO object key of the finished file';
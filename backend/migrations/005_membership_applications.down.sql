@@ -0,0 +1,6 @@
+-- Drop trigger and function
+DROP TRIGGER IF EXISTS trg_members_create_application ON members;
+DROP FUNCTION IF EXISTS create_membership_application();
+
+-- Drop tables
+DROP TABLE IF EXISTS membership_applications;
@@ -0,0 +1,71 @@
+-- Membership applications: approval queue for pending members
+CREATE TABLE membership_applications (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    member_id UUID NOT NULL REFERENCES members(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending', -- pending, approved, rejected
+    level VARCHAR(20) NOT NULL DEFAULT 'district', -- district, province, national
+    province_id UUID,
+    district_id UUID,
+    submitted_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    due_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    escalated_at TIMESTAMP WITH TIME ZONE,
+    escalated_by UUID,
+    escalation_reason TEXT,
+    decided_at TIMESTAMP WITH TIME ZONE,
+    decided_by UUID,
+    decision_reason TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- A member has at most one open application
+CREATE UNIQUE INDEX idx_membership_applications_open ON membership_applications(member_id) WHERE status = 'pending';
+
+CREATE INDEX idx_membership_applications_district ON membership_applications(district_id, due_at) WHERE status = 'pending';
+CREATE INDEX idx_membership_applications_province ON membership_applications(province_id, due_at) WHERE status = 'pending';
+
+-- Route new pending members to the lowest level that can review them:
+-- district admin of the member's district, else province, else national
+CREATE OR REPLACE FUNCTION create_membership_application()
+RETURNS trigger AS $$
+BEGIN
+    IF NEW.status = 'pending' THEN
+        INSERT INTO membership_applications (member_id, level, province_id, district_id, submitted_at, due_at)
+        VALUES (
+            NEW.id,
+            CASE
+                WHEN NEW.district_id IS NOT NULL THEN 'district'
+                WHEN NEW.province_id IS NOT NULL THEN 'province'
+                ELSE 'national'
+            END,
+            NEW.province_id,
+            NEW.district_id,
+            COALESCE(NEW.created_at, NOW()),
+            COALESCE(NEW.created_at, NOW()) + INTERVAL '3 days'
+        )
+        ON CONFLICT DO NOTHING;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_members_create_application
+    AFTER INSERT ON members
+    FOR EACH ROW EXECUTE FUNCTION create_membership_application();
+
+-- Backfill members that are already waiting
+INSERT INTO membership_applications (member_id, level, province_id, district_id, submitted_at, due_at)
+SELECT id,
+       CASE
+           WHEN district_id IS NOT NULL THEN 'district'
+           WHEN province_id IS NOT NULL THEN 'province'
+           ELSE 'national'
+       END,
+       province_id, district_id, created_at, created_at + INTERVAL '3 days'
+FROM members
+WHERE status = 'pending';
+
+-- Comments
+COMMENT ON TABLE membership_applications IS 'Approval queue for new members, routed to district, province or national admins';
+COMMENT ON COLUMN membership_applications.level IS 'Level currently responsible for the decision; escalation moves district to province';
+COMMENT ON COLUMN membership_applications.due_at IS 'SLA deadline for a decision at the current level';
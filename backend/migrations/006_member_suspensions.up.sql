@@ -0,0 +1,23 @@
+-- Member suspensions: reason and optional end date for each suspension
+CREATE TABLE member_suspensions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    member_id UUID NOT NULL REFERENCES members(id) ON DELETE CASCADE,
+    reason TEXT NOT NULL,
+    suspended_by UUID,
+    suspended_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    ends_at TIMESTAMP WITH TIME ZONE, -- NULL = until lifted by an admin
+    lifted_at TIMESTAMP WITH TIME ZONE,
+    lifted_by UUID,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- A member has at most one open suspension
+CREATE UNIQUE INDEX idx_member_suspensions_open ON member_suspensions(member_id) WHERE lifted_at IS NULL;
+
+-- Used by the scheduler to find suspensions that have ended
+CREATE INDEX idx_member_suspensions_ends_at ON member_suspensions(ends_at) WHERE lifted_at IS NULL AND ends_at IS NOT NULL;
+
+-- Comments
+COMMENT ON TABLE member_suspensions IS 'Suspension periods; the member is reinstated when ends_at passes';
+COMMENT ON COLUMN member_suspensions.ends_at IS 'When the member automatically returns to active; NULL for an indefinite suspension';
+COMMENT ON COLUMN member_suspensions.lifted_at IS 'When the suspension ended, either automatically or by a status change; lifted_by is NULL for automatic reinstatement';
@@ -0,0 +1,7 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_members_search_fts;
+DROP INDEX IF EXISTS idx_members_search_trgm;
+
+-- Drop functions
+DROP FUNCTION IF EXISTS member_search_key(TEXT, TEXT, TEXT, TEXT, TEXT, TEXT);
+DROP FUNCTION IF EXISTS member_search_translit(TEXT);
@@ -0,0 +1,41 @@
+-- Member search: full-text and trigram indexes over a transliterated key
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- Transliterate Mongolian Cyrillic to Latin so "Батболд", "batbold" and
+-- "Batbold" produce the same key. Multi-letter sounds are replaced first,
+-- then single letters; ө/ү and ö/ü fold to o/u, and soft/hard signs are dropped.
+CREATE OR REPLACE FUNCTION member_search_translit(input TEXT)
+RETURNS TEXT AS $$
+    SELECT translate(
+        replace(replace(replace(replace(replace(replace(replace(replace(replace(replace(replace(
+            lower(COALESCE(input, '')),
+            'ё', 'yo'), 'ж', 'j'), 'х', 'kh'), 'ц', 'ts'), 'ч', 'ch'), 'ш', 'sh'), 'щ', 'sh'),
+            'ю', 'yu'), 'я', 'ya'), 'ъ', ''), 'ь', ''),
+        'абвгдезийклмнопрстуфыэөүöü',
+        'abvgdeziiklmnoprstufyeouou'
+    );
+$$ LANGUAGE sql IMMUTABLE PARALLEL SAFE;
+
+-- Search key over every searchable member field
+CREATE OR REPLACE FUNCTION member_search_key(
+    first_name TEXT, last_name TEXT, member_id TEXT, phone TEXT, email TEXT, workplace TEXT
+)
+RETURNS TEXT AS $$
+    SELECT member_search_translit(
+        COALESCE(first_name, '') || ' ' || COALESCE(last_name, '') || ' ' ||
+        COALESCE(member_id, '') || ' ' || COALESCE(phone, '') || ' ' ||
+        COALESCE(email, '') || ' ' || COALESCE(workplace, '')
+    );
+$$ LANGUAGE sql IMMUTABLE PARALLEL SAFE;
+
+-- Trigram index for fuzzy matching (typos, partial words)
+CREATE INDEX idx_members_search_trgm ON members
+    USING gin (member_search_key(first_name, last_name, member_id, phone, email, workplace) gin_trgm_ops);
+
+-- Full-text index for whole-word matching and ranking
+CREATE INDEX idx_members_search_fts ON members
+    USING gin (to_tsvector('simple', member_search_key(first_name, last_name, member_id, phone, email, workplace)));
+
+-- Comments
+COMMENT ON FUNCTION member_search_translit(TEXT) IS 'Lower-cases and transliterates Mongolian Cyrillic to Latin for search';
+COMMENT ON FUNCTION member_search_key(TEXT, TEXT, TEXT, TEXT, TEXT, TEXT) IS 'Transliterated search key used by the member search indexes';
@@ -0,0 +1,3 @@
+-- Drop indexes and columns
+DROP INDEX IF EXISTS idx_member_history_member_created;
+ALTER TABLE member_history DROP COLUMN IF EXISTS changes;
@@ -0,0 +1,8 @@
+-- Member history: per-field before/after values of profile updates
+ALTER TABLE member_history ADD COLUMN changes JSONB;
+
+-- History is read per member, newest first, and replayed for "as of" views
+CREATE INDEX idx_member_history_member_created ON member_history(member_id, created_at DESC);
+
+-- Comments
+COMMENT ON COLUMN member_history.changes IS 'Array of {field, old_value, new_value} for "updated" entries; NULL for other actions';
@@ -0,0 +1,27 @@
+-- Member transfers: requests to move a member between organizations
+CREATE TABLE member_transfers (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    member_id UUID NOT NULL REFERENCES members(id) ON DELETE CASCADE,
+    from_organization_id UUID REFERENCES organizations(id) ON DELETE SET NULL,
+    to_organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending', -- pending, accepted, declined, cancelled
+    reason TEXT,
+    requested_by UUID,
+    requested_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    decided_at TIMESTAMP WITH TIME ZONE,
+    decided_by UUID,
+    decision_reason TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- A member has at most one open transfer
+CREATE UNIQUE INDEX idx_member_transfers_open ON member_transfers(member_id) WHERE status = 'pending';
+
+CREATE INDEX idx_member_transfers_to ON member_transfers(to_organization_id, status, requested_at);
+CREATE INDEX idx_member_transfers_from ON member_transfers(from_organization_id, status, requested_at);
+
+-- Comments
+COMMENT ON TABLE member_transfers IS 'Organization transfer requests; the receiving organization accepts or declines';
+COMMENT ON COLUMN member_transfers.from_organization_id IS 'Member organization when the transfer was requested; NULL if the member had none';
+COMMENT ON COLUMN member_transfers.decided_by IS 'Receiving admin for accepted/declined, initiator side for cancelled';
@@ -0,0 +1,13 @@
+-- Member field verifications: profile fields an admin has checked
+CREATE TABLE member_field_verifications (
+    member_id UUID NOT NULL REFERENCES members(id) ON DELETE CASCADE,
+    field VARCHAR(50) NOT NULL,
+    verified_by UUID,
+    verified_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    note TEXT,
+    PRIMARY KEY (member_id, field)
+);
+
+-- Comments
+COMMENT ON TABLE member_field_verifications IS 'Admin verification of member profile fields; a row is deleted when the field value changes';
+COMMENT ON COLUMN member_field_verifications.note IS 'How the value was verified, e.g. the document checked';
@@ -0,0 +1,21 @@
+-- Member documents: files uploaded against a member, stored in MinIO
+CREATE TABLE member_documents (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    member_id UUID NOT NULL REFERENCES members(id) ON DELETE CASCADE,
+    type VARCHAR(30) NOT NULL, -- id_scan, application_form, signed_pledge, other
+    file_name VARCHAR(255) NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    size_bytes BIGINT NOT NULL,
+    sha256 CHAR(64) NOT NULL,
+    object_key TEXT NOT NULL UNIQUE,
+    description TEXT,
+    uploaded_by UUID,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_member_documents_member ON member_documents(member_id, created_at DESC);
+
+-- Comments
+COMMENT ON TABLE member_documents IS 'Metadata of member documents; the files live in MinIO';
+COMMENT ON COLUMN member_documents.content_type IS 'Type detected from the file contents, not the one sent by the client';
+COMMENT ON COLUMN member_documents.object_key IS 'MinIO object key of the file';
@@ -0,0 +1,10 @@
+-- Event settings: per-event options kept outside the events table
+CREATE TABLE event_settings (
+    event_id UUID PRIMARY KEY REFERENCES events(id) ON DELETE CASCADE,
+    allow_walk_ins BOOLEAN NOT NULL DEFAULT FALSE,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Comments
+COMMENT ON TABLE event_settings IS 'Per-event options; events without a row use the column defaults';
+COMMENT ON COLUMN event_settings.allow_walk_ins IS 'Members who did not register may check in at the door';
@@ -0,0 +1,9 @@
+-- Drop triggers and functions
+DROP TRIGGER IF EXISTS trg_events_promote_waitlist ON events;
+DROP TRIGGER IF EXISTS trg_event_participants_promote_waitlist ON event_participants;
+DROP FUNCTION IF EXISTS promote_event_waitlist_on_change();
+DROP FUNCTION IF EXISTS promote_event_waitlist(UUID);
+
+-- Drop columns
+DROP INDEX IF EXISTS idx_event_participants_status;
+ALTER TABLE event_participants DROP COLUMN IF EXISTS status;
@@ -0,0 +1,64 @@
+-- Event waitlist: participants beyond max_participants wait in line and are
+-- promoted in registration order as seats free up
+ALTER TABLE event_participants
+    ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'registered'; -- registered, waitlisted
+
+CREATE INDEX idx_event_participants_status ON event_participants(event_id, status, registered_at);
+
+-- Fill free seats of an event from its waitlist. The event row is locked so
+-- promotion and registration never overbook.
+CREATE OR REPLACE FUNCTION promote_event_waitlist(p_event_id UUID)
+RETURNS void AS $$
+DECLARE
+    capacity INTEGER;
+    taken INTEGER;
+BEGIN
+    SELECT max_participants INTO capacity FROM events WHERE id = p_event_id FOR UPDATE;
+    IF NOT FOUND THEN
+        RETURN;
+    END IF;
+
+    SELECT COUNT(*) INTO taken
+    FROM event_participants
+    WHERE event_id = p_event_id AND status = 'registered';
+
+    UPDATE event_participants SET status = 'registered'
+    WHERE id IN (
+        SELECT id FROM event_participants
+        WHERE event_id = p_event_id AND status = 'waitlisted'
+        ORDER BY registered_at, id
+        LIMIT CASE WHEN capacity IS NULL THEN NULL ELSE GREATEST(capacity - taken, 0) END
+    );
+END;
+$$ LANGUAGE plpgsql;
+
+-- Promote when a seat is freed or the event's capacity changes
+CREATE OR REPLACE FUNCTION promote_event_waitlist_on_change()
+RETURNS trigger AS $$
+BEGIN
+    IF TG_TABLE_NAME = 'events' THEN
+        PERFORM promote_event_waitlist(NEW.id);
+    ELSIF OLD.status = 'registered' THEN
+        IF TG_OP = 'DELETE' THEN
+            PERFORM promote_event_waitlist(OLD.event_id);
+        ELSIF NEW.status <> 'registered' THEN
+            PERFORM promote_event_waitlist(OLD.event_id);
+        END IF;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_event_participants_promote_waitlist
+    AFTER DELETE OR UPDATE OF status ON event_participants
+    FOR EACH ROW EXECUTE FUNCTION promote_event_waitlist_on_change();
+
+CREATE TRIGGER trg_events_promote_waitlist
+    AFTER UPDATE OF max_participants ON events
+    FOR EACH ROW
+    WHEN (NEW.max_participants IS DISTINCT FROM OLD.max_participants)
+    EXECUTE FUNCTION promote_event_waitlist_on_change();
+
+-- Comments
+COMMENT ON COLUMN event_participants.status IS 'registered holds a seat; waitlisted waits for one in registered_at order';
+COMMENT ON FUNCTION promote_event_waitlist(UUID) IS 'Moves waitlisted participants into free seats, earliest registration first';
@@ -0,0 +1,54 @@
+-- Restore waitlist functions
+CREATE OR REPLACE FUNCTION promote_event_waitlist(p_event_id UUID)
+RETURNS void AS $$
+DECLARE
+    capacity INTEGER;
+    taken INTEGER;
+BEGIN
+    SELECT max_participants INTO capacity FROM events WHERE id = p_event_id FOR UPDATE;
+    IF NOT FOUND THEN
+        RETURN;
+    END IF;
+
+    SELECT COUNT(*) INTO taken
+    FROM event_participants
+    WHERE event_id = p_event_id AND status = 'registered';
+
+    UPDATE event_participants SET status = 'registered'
+    WHERE id IN (
+        SELECT id FROM event_participants
+        WHERE event_id = p_event_id AND status = 'waitlisted'
+        ORDER BY registered_at, id
+        LIMIT CASE WHEN capacity IS NULL THEN NULL ELSE GREATEST(capacity - taken, 0) END
+    );
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION promote_event_waitlist_on_change()
+RETURNS trigger AS $$
+BEGIN
+    IF TG_TABLE_NAME = 'events' THEN
+        PERFORM promote_event_waitlist(NEW.id);
+    ELSIF OLD.status = 'registered' THEN
+        IF TG_OP = 'DELETE' THEN
+            PERFORM promote_event_waitlist(OLD.event_id);
+        ELSIF NEW.status <> 'registered' THEN
+            PERFORM promote_event_waitlist(OLD.event_id);
+        END IF;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Restore attended flag
+ALTER TABLE event_participants ADD COLUMN attended BOOLEAN DEFAULT FALSE;
+UPDATE event_participants SET attended = (status = 'attended');
+DELETE FROM event_participants WHERE status = 'cancelled';
+UPDATE event_participants SET status = 'registered' WHERE status IN ('attended', 'no_show');
+
+-- Drop columns
+ALTER TABLE event_settings DROP COLUMN IF EXISTS cancellation_cutoff_hours;
+ALTER TABLE event_participants
+    DROP COLUMN IF EXISTS cancelled_at,
+    DROP COLUMN IF EXISTS cancelled_by,
+    DROP COLUMN IF EXISTS cancellation_reason;
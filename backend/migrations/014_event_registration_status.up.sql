@@ -0,0 +1,63 @@
+-- Event registration status: status replaces the attended flag and
+-- cancelled registrations are kept with who cancelled and why
+ALTER TABLE event_participants
+    ADD COLUMN cancelled_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN cancelled_by UUID,
+    ADD COLUMN cancellation_reason TEXT;
+
+-- Hours before the start after which members can no longer cancel
+ALTER TABLE event_settings
+    ADD COLUMN cancellation_cutoff_hours INTEGER NOT NULL DEFAULT 0;
+
+-- registered, attended and no_show hold a seat
+CREATE OR REPLACE FUNCTION promote_event_waitlist(p_event_id UUID)
+RETURNS void AS $$
+DECLARE
+    capacity INTEGER;
+    taken INTEGER;
+BEGIN
+    SELECT max_participants INTO capacity FROM events WHERE id = p_event_id FOR UPDATE;
+    IF NOT FOUND THEN
+        RETURN;
+    END IF;
+
+    SELECT COUNT(*) INTO taken
+    FROM event_participants
+    WHERE event_id = p_event_id AND status IN ('registered', 'attended', 'no_show');
+
+    UPDATE event_participants SET status = 'registered'
+    WHERE id IN (
+        SELECT id FROM event_participants
+        WHERE event_id = p_event_id AND status = 'waitlisted'
+        ORDER BY registered_at, id
+        LIMIT CASE WHEN capacity IS NULL THEN NULL ELSE GREATEST(capacity - taken, 0) END
+    );
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION promote_event_waitlist_on_change()
+RETURNS trigger AS $$
+BEGIN
+    IF TG_TABLE_NAME = 'events' THEN
+        PERFORM promote_event_waitlist(NEW.id);
+    ELSIF OLD.status IN ('registered', 'attended', 'no_show') THEN
+        IF TG_OP = 'DELETE' THEN
+            PERFORM promote_event_waitlist(OLD.event_id);
+        ELSIF NEW.status NOT IN ('registered', 'attended', 'no_show') THEN
+            PERFORM promote_event_waitlist(OLD.event_id);
+        END IF;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Replace the attended flag; the functions above already count attended
+-- participants as seated, so this does not promote anyone
+UPDATE event_participants SET status = 'attended' WHERE attended;
+
+ALTER TABLE event_participants DROP COLUMN attended;
+
+-- Comments
+COMMENT ON COLUMN event_participants.status IS 'registered, attended and no_show hold a seat; waitlisted waits for one in registered_at order; cancelled gave it up';
+COMMENT ON COLUMN event_participants.cancelled_by IS 'Member who cancelled: the participant, or the admin who removed them';
+COMMENT ON COLUMN event_settings.cancellation_cutoff_hours IS 'Members cannot cancel a seat this many hours before the start; 0 allows it until the start';
@@ -0,0 +1,3 @@
+-- Drop tables
+DROP TABLE IF EXISTS event_series_occurrences;
+DROP TABLE IF EXISTS event_series;
@@ -0,0 +1,45 @@
+-- Event series: recurring events. Occurrences are regular events generated
+-- up to a horizon and linked to their series.
+CREATE TABLE event_series (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID REFERENCES organizations(id) ON DELETE CASCADE,
+    title VARCHAR(255) NOT NULL,
+    description TEXT,
+    type VARCHAR(50) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'draft', -- status of new occurrences: draft, planned
+    location VARCHAR(255),
+    address TEXT,
+    is_online BOOLEAN NOT NULL DEFAULT FALSE,
+    online_url VARCHAR(500),
+    max_participants INTEGER,
+    is_public BOOLEAN NOT NULL DEFAULT FALSE,
+    cover_image_url VARCHAR(500),
+    organizer_id UUID REFERENCES members(id) ON DELETE SET NULL,
+    recurrence VARCHAR(255) NOT NULL,
+    start_date TIMESTAMP WITH TIME ZONE NOT NULL,
+    duration_minutes INTEGER,
+    timezone VARCHAR(64) NOT NULL DEFAULT 'Asia/Ulaanbaatar',
+    generated_until TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- One row per generated occurrence, keyed by its start in the rule. A
+-- deleted occurrence keeps its row with no event so it is not generated
+-- again.
+CREATE TABLE event_series_occurrences (
+    series_id UUID NOT NULL REFERENCES event_series(id) ON DELETE CASCADE,
+    original_start TIMESTAMP WITH TIME ZONE NOT NULL,
+    event_id UUID UNIQUE REFERENCES events(id) ON DELETE SET NULL,
+    detached BOOLEAN NOT NULL DEFAULT FALSE,
+    PRIMARY KEY (series_id, original_start)
+);
+
+CREATE INDEX idx_event_series_organization ON event_series(organization_id);
+
+-- Comments
+COMMENT ON TABLE event_series IS 'Recurring event templates; occurrences are generated into events';
+COMMENT ON COLUMN event_series.recurrence IS 'RRULE subset: FREQ=WEEKLY|MONTHLY, INTERVAL, BYDAY, BYMONTHDAY, COUNT, UNTIL';
+COMMENT ON COLUMN event_series.start_date IS 'Start of the first occurrence; occurrences keep its local time of day in timezone';
+COMMENT ON COLUMN event_series.generated_until IS 'Occurrences up to this time have been generated';
+COMMENT ON COLUMN event_series_occurrences.detached IS 'Edited as a single occurrence; series edits leave it alone';
@@ -0,0 +1,13 @@
+-- Calendar feed tokens: a member's private ICS feeds are read by calendar
+-- apps with this token instead of a JWT. Only a hash is stored; a new
+-- token replaces the old one.
+CREATE TABLE calendar_tokens (
+    member_id UUID PRIMARY KEY REFERENCES members(id) ON DELETE CASCADE,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    last_used_at TIMESTAMP WITH TIME ZONE
+);
+
+-- Comments
+COMMENT ON TABLE calendar_tokens IS 'Revocable tokens for private calendar (ICS) feeds';
+COMMENT ON COLUMN calendar_tokens.token_hash IS 'Hex SHA-256 of the token';
@@ -0,0 +1,28 @@
+-- Restore waitlist promotion for every event
+CREATE OR REPLACE FUNCTION promote_event_waitlist(p_event_id UUID)
+RETURNS void AS $$
+DECLARE
+    capacity INTEGER;
+    taken INTEGER;
+BEGIN
+    SELECT max_participants INTO capacity FROM events WHERE id = p_event_id FOR UPDATE;
+    IF NOT FOUND THEN
+        RETURN;
+    END IF;
+
+    SELECT COUNT(*) INTO taken
+    FROM event_participants
+    WHERE event_id = p_event_id AND status IN ('registered', 'attended', 'no_show');
+
+    UPDATE event_participants SET status = 'registered'
+    WHERE id IN (
+        SELECT id FROM event_participants
+        WHERE event_id = p_event_id AND status = 'waitlisted'
+        ORDER BY registered_at, id
+        LIMIT CASE WHEN capacity IS NULL THEN NULL ELSE GREATEST(capacity - taken, 0) END
+    );
+END;
+$$ LANGUAGE plpgsql;
+
+-- Drop tables
+DROP TABLE IF EXISTS member_notifications;
@@ -0,0 +1,45 @@
+-- Event lifecycle: in-app notifications for members, written when an event
+-- they are registered for is cancelled
+CREATE TABLE member_notifications (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    member_id UUID NOT NULL REFERENCES members(id) ON DELETE CASCADE,
+    type VARCHAR(50) NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    body TEXT,
+    event_id UUID REFERENCES events(id) ON DELETE CASCADE,
+    read_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_member_notifications_member ON member_notifications(member_id, created_at DESC);
+
+-- Finished and cancelled events no longer move the waitlist up
+CREATE OR REPLACE FUNCTION promote_event_waitlist(p_event_id UUID)
+RETURNS void AS $$
+DECLARE
+    capacity INTEGER;
+    event_status VARCHAR(20);
+    taken INTEGER;
+BEGIN
+    SELECT max_participants, status INTO capacity, event_status FROM events WHERE id = p_event_id FOR UPDATE;
+    IF NOT FOUND OR event_status IN ('completed', 'cancelled') THEN
+        RETURN;
+    END IF;
+
+    SELECT COUNT(*) INTO taken
+    FROM event_participants
+    WHERE event_id = p_event_id AND status IN ('registered', 'attended', 'no_show');
+
+    UPDATE event_participants SET status = 'registered'
+    WHERE id IN (
+        SELECT id FROM event_participants
+        WHERE event_id = p_event_id AND status = 'waitlisted'
+        ORDER BY registered_at, id
+        LIMIT CASE WHEN capacity IS NULL THEN NULL ELSE GREATEST(capacity - taken, 0) END
+    );
+END;
+$$ LANGUAGE plpgsql;
+
+-- Comments
+COMMENT ON TABLE member_notifications IS 'In-app notifications, e.g. event_cancelled';
+COMMENT ON COLUMN member_notifications.read_at IS 'When the member marked it read; NULL while unread';
@@ -0,0 +1,4 @@
+-- Drop tables
+DROP TABLE IF EXISTS event_survey_answers;
+DROP TABLE IF EXISTS event_survey_responses;
+DROP TABLE IF EXISTS event_survey_questions;
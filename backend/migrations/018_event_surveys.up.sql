@@ -0,0 +1,37 @@
+-- Event surveys: feedback questions per event, answered once by each
+-- participant who attended
+CREATE TABLE event_survey_questions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    event_id UUID NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    type VARCHAR(20) NOT NULL, -- rating, choice, text
+    prompt TEXT NOT NULL,
+    options TEXT[] NOT NULL DEFAULT '{}',
+    required BOOLEAN NOT NULL DEFAULT FALSE,
+    UNIQUE (event_id, position)
+);
+
+CREATE TABLE event_survey_responses (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    event_id UUID NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+    member_id UUID NOT NULL REFERENCES members(id) ON DELETE CASCADE,
+    submitted_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (event_id, member_id)
+);
+
+CREATE TABLE event_survey_answers (
+    response_id UUID NOT NULL REFERENCES event_survey_responses(id) ON DELETE CASCADE,
+    question_id UUID NOT NULL REFERENCES event_survey_questions(id) ON DELETE CASCADE,
+    rating INTEGER CHECK (rating BETWEEN 1 AND 5),
+    choice TEXT,
+    text TEXT,
+    PRIMARY KEY (response_id, question_id)
+);
+
+CREATE INDEX idx_event_survey_answers_question ON event_survey_answers(question_id);
+
+-- Comments
+COMMENT ON TABLE event_survey_questions IS 'Feedback survey of an event; fixed once answered';
+COMMENT ON COLUMN event_survey_questions.options IS 'Choices of a choice question';
+COMMENT ON TABLE event_survey_responses IS 'One survey submission per attended participant';
+COMMENT ON COLUMN event_survey_answers.rating IS 'Answer to a rating question, 1-5';
@@ -0,0 +1,8 @@
+-- Drop index
+DROP INDEX IF EXISTS idx_event_participants_attended_member;
+
+-- Drop columns
+ALTER TABLE event_participants
+    DROP COLUMN IF EXISTS checked_out_at,
+    DROP COLUMN IF EXISTS hours,
+    DROP COLUMN IF EXISTS hours_entered_by;
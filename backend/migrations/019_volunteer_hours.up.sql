@@ -0,0 +1,12 @@
+-- Volunteer hours: hours credited to each attended participant, from
+-- check-in to check-out or entered by an organizer
+ALTER TABLE event_participants
+    ADD COLUMN checked_out_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN hours NUMERIC(6, 2) CHECK (hours >= 0),
+    ADD COLUMN hours_entered_by UUID;
+
+CREATE INDEX idx_event_participants_attended_member ON event_participants(member_id) WHERE status = 'attended';
+
+-- Comments
+COMMENT ON COLUMN event_participants.hours IS 'Credited hours; set on check-out unless entered by an organizer';
+COMMENT ON COLUMN event_participants.hours_entered_by IS 'Organizer who entered the hours; NULL if computed from check-in and check-out';
@@ -0,0 +1,18 @@
+// Package fonts embeds the fonts used for generated PDFs. The PDF core
+// fonts have no Cyrillic, so Mongolian text needs a TrueType font with
+// Ө and Ү; DejaVu Sans covers them.
+//
+// DejaVu fonts are free to use and redistribute, see
+// https://dejavu-fonts.github.io/License.html
+package fonts
+
+import _ "embed"
+
+// Family is the font family name the fonts are registered under
+const Family = "DejaVuSansCondensed"
+
+//go:embed DejaVuSansCondensed.ttf
+var Regular []byte
+
+//go:embed DejaVuSansCondensed-Bold.ttf
+var Bold []byte
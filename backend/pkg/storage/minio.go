@@ -0,0 +1,183 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/rs/zerolog/log"
+)
+
+// MinioConfig holds the connection settings for MinIO
+type MinioConfig struct {
+	Endpoint  string
+	AccessKey string
+	SecretKey string
+	Bucket    string
+	UseSSL    bool
+	// PublicURL is the base URL presigned links are issued for. Optional.
+	PublicURL string
+	// PublicPrefix is a key prefix anyone may read without a presigned URL,
+	// e.g. "public/" for images shown on the site. Optional.
+	PublicPrefix string
+}
+
+// Storage stores objects in a single MinIO bucket
+type Storage struct {
+	client  *minio.Client
+	presign *minio.Client
+	bucket  string
+	// baseURL is where objects are reached from outside, including the bucket
+	baseURL string
+}
+
+func NewMinio(cfg MinioConfig) (*Storage, error) {
+	client, err := minio.New(cfg.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, ""),
+		Secure: cfg.UseSSL,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Presigning is done offline, so the public client never has to reach
+	// MinIO; it only needs the host and scheme the browser will use.
+	presign := client
+	if cfg.PublicURL != "" {
+		u, err := url.Parse(cfg.PublicURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid MinIO public URL: %w", err)
+		}
+		presign, err = minio.New(u.Host, &minio.Options{
+			Creds:  credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, ""),
+			Secure: u.Scheme == "https",
+			Region: "us-east-1",
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Verify connection and make sure the bucket exists
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	exists, err := client.BucketExists(ctx, cfg.Bucket)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		if err := client.MakeBucket(ctx, cfg.Bucket, minio.MakeBucketOptions{}); err != nil {
+			return nil, err
+		}
+		log.Info().Str("bucket", cfg.Bucket).Msg("Created MinIO bucket")
+	}
+
+	if cfg.PublicPrefix != "" {
+		if err := client.SetBucketPolicy(ctx, cfg.Bucket, publicReadPolicy(cfg.Bucket, cfg.PublicPrefix)); err != nil {
+			return nil, err
+		}
+	}
+
+	baseURL := cfg.PublicURL
+	if baseURL == "" {
+		scheme := "http"
+		if cfg.UseSSL {
+			scheme = "https"
+		}
+		baseURL = scheme + "://" + cfg.Endpoint
+	}
+
+	log.Info().Msg("Connected to MinIO")
+	return &Storage{
+		client:  client,
+		presign: presign,
+		bucket:  cfg.Bucket,
+		baseURL: strings.TrimSuffix(baseURL, "/") + "/" + cfg.Bucket + "/",
+	}, nil
+}
+
+// publicReadPolicy is a bucket policy allowing anonymous reads below prefix
+func publicReadPolicy(bucket, prefix string) string {
+	return fmt.Sprintf(`{
+	"Version": "2012-10-17",
+	"Statement": [{
+		"Effect": "Allow",
+		"Principal": {"AWS": ["*"]},
+		"Action": ["s3:GetObject"],
+		"Resource": ["arn:aws:s3:::%s/%s*"]
+	}]
+}`, bucket, prefix)
+}
+
+// Put uploads an object. size may be -1 if unknown.
+func (s *Storage) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	_, err := s.client.PutObject(ctx, s.bucket, key, r, size, minio.PutObjectOptions{
+		ContentType: contentType,
+	})
+	return err
+}
+
+// Get opens an object for reading. The caller must close it.
+func (s *Storage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	return s.client.GetObject(ctx, s.bucket, key, minio.GetObjectOptions{})
+}
+
+// Remove deletes an object. Removing a missing object is not an error.
+func (s *Storage) Remove(ctx context.Context, key string) error {
+	return s.client.RemoveObject(ctx, s.bucket, key, minio.RemoveObjectOptions{})
+}
+
+// Object describes a stored object
+type Object struct {
+	Key          string
+	Size         int64
+	LastModified time.Time
+}
+
+// List returns the objects whose key starts with prefix
+func (s *Storage) List(ctx context.Context, prefix string) ([]Object, error) {
+	var objects []Object
+	for info := range s.client.ListObjects(ctx, s.bucket, minio.ListObjectsOptions{Prefix: prefix, Recursive: true}) {
+		if info.Err != nil {
+			return nil, info.Err
+		}
+		objects = append(objects, Object{Key: info.Key, Size: info.Size, LastModified: info.LastModified})
+	}
+	return objects, nil
+}
+
+// URL returns the permanent URL of an object. It is only readable without
+// signing if the key is under the public prefix.
+func (s *Storage) URL(key string) string {
+	return s.baseURL + key
+}
+
+// KeyFromURL is the inverse of URL. It reports false for URLs that do not
+// point into this bucket.
+func (s *Storage) KeyFromURL(u string) (string, bool) {
+	if !strings.HasPrefix(u, s.baseURL) || len(u) == len(s.baseURL) {
+		return "", false
+	}
+	return strings.TrimPrefix(u, s.baseURL), true
+}
+
+// PresignedGetURL returns a time-limited download URL for an object. If
+// filename is set the browser is told to save the file under that name.
+func (s *Storage) PresignedGetURL(ctx context.Context, key, filename string, expiry time.Duration) (string, error) {
+	reqParams := make(url.Values)
+	if filename != "" {
+		reqParams.Set("response-content-disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	}
+
+	u, err := s.presign.PresignedGetObject(ctx, s.bucket, key, expiry, reqParams)
+	if err != nil {
+		return "", err
+	}
+	return u.String(), nil
+}